@@ -0,0 +1,129 @@
+// Package builder provides a more concise way of constructing simple Networks. It is only sugar
+// over (*badstudent.Network).Add and Finalize; Networks constructed with it are identical to those
+// constructed by hand.
+//
+// For example, a Network for XOR could be constructed by:
+//
+//		net := new(bs.Network)
+//		out := builder.Input(net, 2).Dense(2).Sigmoid().Dense(1).Sigmoid()
+//		err := builder.Finalize(costfuncs.MSE(), out)
+package builder
+
+import (
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/operators"
+)
+
+// Handle is a Node in a Network that is being constructed, which can be used as the input to the
+// next Node. Each method that adds a Node returns a Handle to it.
+//
+// Errors are handled in the same way as with Add: if any Node cannot be added, the Network's
+// error is set, and all further additions will do nothing. The error is then returned by Finalize.
+type Handle struct {
+	net  *bs.Network
+	node *bs.Node
+}
+
+// Input adds an input Node with the given dimensions to the Network, returning a Handle to it. It
+// has the same panic and error conditions as (*badstudent.Network).AddInput.
+func Input(net *bs.Network, dims ...int) Handle {
+	return Handle{net, net.AddInput(dims)}
+}
+
+// Wrap returns a Handle to a Node that has already been added to the Network, so that Nodes can
+// be added with it as their input.
+func Wrap(net *bs.Network, n *bs.Node) Handle {
+	return Handle{net, n}
+}
+
+// Node returns the Node given by the Handle, which can be used for further configuration (e.g.
+// setting Optimizers or HyperParameters). It will be nil if there was an error in adding it.
+func (h Handle) Node() *bs.Node {
+	return h.node
+}
+
+// Then adds a Node with the given Operator to the Network, with this Node as its input, followed
+// by any others that are given. It has the same error conditions as (*badstudent.Network).Add.
+func (h Handle) Then(op bs.Operator, others ...Handle) Handle {
+	inputs := make([]*bs.Node, 1+len(others))
+	inputs[0] = h.node
+	for i := range others {
+		inputs[i+1] = others[i].node
+	}
+
+	return Handle{h.net, h.net.Add(op, inputs...)}
+}
+
+// Name sets the name of the Node, and returns the same Handle.
+func (h Handle) Name(name string) Handle {
+	h.node.SetName(name)
+	return h
+}
+
+// Dense adds a fully-connected layer of the given size (operators.Neurons)
+func (h Handle) Dense(size int) Handle {
+	return h.Then(operators.Neurons(size))
+}
+
+// ReLU adds operators.ReLU
+func (h Handle) ReLU() Handle {
+	return h.Then(operators.ReLU())
+}
+
+// Sigmoid adds operators.Logistic
+func (h Handle) Sigmoid() Handle {
+	return h.Then(operators.Logistic())
+}
+
+// Tanh adds operators.Tanh
+func (h Handle) Tanh() Handle {
+	return h.Then(operators.Tanh())
+}
+
+// Softmax adds operators.Softmax
+func (h Handle) Softmax() Handle {
+	return h.Then(operators.Softmax())
+}
+
+// Dropout adds operators.Dropout with the given rate
+func (h Handle) Dropout(rate float64) Handle {
+	return h.Then(operators.Dropout(rate))
+}
+
+// Flatten adds operators.Flatten
+func (h Handle) Flatten() Handle {
+	return h.Then(operators.Flatten())
+}
+
+// ClassifierOutput adds the usual output for classification into the given number of classes: a
+// fully-connected layer with one value for each class (operators.Neurons), followed by
+// operators.Softmax. Any other Handles given are also used as inputs to the fully-connected layer.
+// The returned Handle should be given to Finalize as an output, typically with
+// costfuncs.CrossEntropy.
+func (h Handle) ClassifierOutput(classes int, others ...Handle) Handle {
+	return h.Then(operators.Neurons(classes), others...).Softmax()
+}
+
+// RegressionOutput adds the usual output for regression: a fully-connected layer of the given size
+// (operators.Neurons), with no activation function, so that the outputs are unbounded. Any other
+// Handles given are also used as inputs. The returned Handle should be given to Finalize as an
+// output, typically with costfuncs.MSE.
+func (h Handle) RegressionOutput(size int, others ...Handle) Handle {
+	return h.Then(operators.Neurons(size), others...)
+}
+
+// Finalize finalizes the Network that the outputs belong to, with the given CostFunction. It is
+// equivalent to (*badstudent.Network).Finalize, and has the same error conditions. If no outputs
+// are given, Finalize returns badstudent.ErrNoOutputs.
+func Finalize(cf bs.CostFunction, outputs ...Handle) error {
+	if len(outputs) == 0 {
+		return bs.ErrNoOutputs
+	}
+
+	nodes := make([]*bs.Node, len(outputs))
+	for i := range outputs {
+		nodes[i] = outputs[i].node
+	}
+
+	return outputs[0].net.Finalize(cf, nodes...)
+}
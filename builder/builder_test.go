@@ -0,0 +1,143 @@
+package builder_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/builder"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	_ "github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	_ "github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestBuilderMatchesAdd(t *testing.T) {
+	manual := new(bs.Network)
+	l := manual.AddInput([]int{2})
+	l = manual.Add(operators.Neurons(4), l)
+	l = manual.Add(operators.ReLU(), l)
+	l = manual.Add(operators.Neurons(1), l)
+	l = manual.Add(operators.Logistic(), l)
+	manual.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := manual.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	built := new(bs.Network)
+	out := builder.Input(built, 2).Dense(4).ReLU().Dense(1).Sigmoid()
+	built.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := builder.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	mNodes, bNodes := manual.Nodes(), built.Nodes()
+	if len(mNodes) != len(bNodes) {
+		t.Fatalf("Builder gave %d Nodes, expected %d", len(bNodes), len(mNodes))
+	}
+
+	for i := range mNodes {
+		m, b := mNodes[i], bNodes[i]
+		if m.OperatorType() != b.OperatorType() || m.Size() != b.Size() || m.IsOutput() != b.IsOutput() {
+			t.Errorf("Node %d from builder is %q with size %d, expected %q with size %d",
+				i, b.OperatorType(), b.Size(), m.OperatorType(), m.Size())
+		}
+
+		if m.IsInput() || b.IsInput() {
+			if m.IsInput() != b.IsInput() {
+				t.Errorf("Node %d from builder is an input: %v, expected %v", i, b.IsInput(), m.IsInput())
+			}
+
+			continue
+		}
+
+		if m.NumInputNodes() != b.NumInputNodes() {
+			t.Errorf("Node %d from builder has %d inputs, expected %d", i, b.NumInputNodes(), m.NumInputNodes())
+			continue
+		}
+
+		for j := 0; j < m.NumInputNodes(); j++ {
+			if m.Input(j).ID() != b.Input(j).ID() {
+				t.Errorf("Input %d of Node %d from builder has id %d, expected %d", j, i, b.Input(j).ID(), m.Input(j).ID())
+			}
+		}
+	}
+
+	// with the same weights, both give the same outputs
+	if err := built.SetWeights(manual.Weights()); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{0.3, -0.8}
+	mOuts, err := manual.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bOuts, err := built.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	} else if mOuts[0] != bOuts[0] {
+		t.Errorf("Builder Network gave outputs %v, expected %v", bOuts, mOuts)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	net := new(bs.Network)
+	out := builder.Input(net, 2).Dense(0).Sigmoid()
+	if out.Node() != nil {
+		t.Errorf("Adding after an error gave a Node, expected nil")
+	}
+
+	if err := builder.Finalize(costfuncs.MSE(), out); err != bs.ErrZeroSize {
+		t.Errorf("Finalize gave error %v, expected ErrZeroSize", err)
+	}
+
+	if err := builder.Finalize(costfuncs.MSE()); err != bs.ErrNoOutputs {
+		t.Errorf("Finalize with no outputs gave error %v, expected ErrNoOutputs", err)
+	}
+}
+
+func TestOutputPresets(t *testing.T) {
+	tests := []struct {
+		name  string
+		add   func(in builder.Handle) builder.Handle
+		types []string
+		size  int
+	}{
+		{"ClassifierOutput", func(in builder.Handle) builder.Handle {
+			return in.ClassifierOutput(4)
+		}, []string{"neurons", "softmax"}, 4},
+		{"RegressionOutput", func(in builder.Handle) builder.Handle {
+			return in.RegressionOutput(2)
+		}, []string{"neurons"}, 2},
+	}
+
+	for _, test := range tests {
+		net := new(bs.Network)
+		out := test.add(builder.Input(net, 3))
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := builder.Finalize(costfuncs.MSE(), out); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		// the first Node is the input
+		nodes := net.Nodes()[1:]
+		if len(nodes) != len(test.types) {
+			t.Errorf("%s added %d Nodes, expected %d", test.name, len(nodes), len(test.types))
+			continue
+		}
+
+		for i, n := range nodes {
+			if n.OperatorType() != test.types[i] {
+				t.Errorf("%s: Node %d has Operator %q, expected %q", test.name, i, n.OperatorType(), test.types[i])
+			}
+		}
+
+		if !out.Node().IsOutput() {
+			t.Errorf("%s: returned Node is not an output", test.name)
+		} else if net.OutputSize() != test.size {
+			t.Errorf("%s: output size is %d, expected %d", test.name, net.OutputSize(), test.size)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package badstudent
 import (
 	"github.com/sharnoff/badstudent/utils"
 	"fmt"
+	"math"
 )
 
 type status int8
@@ -38,6 +39,26 @@ func (net *Network) ClearDelays() {
 	}
 }
 
+// ResetState clears all of the hidden state of the Network: the values waiting in delay (as with
+// ClearDelays), along with the internal state of any Operators that are Stateful. Weights are not
+// changed. For Networks without delay or Stateful Operators, ResetState does nothing of note.
+func (net *Network) ResetState() {
+	net.ClearDelays()
+
+	var reset bool
+	for _, n := range net.nodesByID {
+		if s, ok := n.op.(Stateful); ok {
+			s.ResetState(n)
+			reset = true
+		}
+	}
+
+	// the current values may have depended on the state
+	if reset && net.stat > finalized {
+		net.stat = finalized
+	}
+}
+
 // DoesNotAffectOutputsError results from one (or more) Node not having an output path to the
 // Network outputs.
 type DoesNotAffectOutputsError struct {
@@ -48,6 +69,71 @@ func (err DoesNotAffectOutputsError) Error() string {
 	return fmt.Sprintf("Node %v does not affect outputs", err.N)
 }
 
+// UnreachableError results from Validate, if any Nodes cannot be reached from the inputs of the
+// Network. It lists all of them, in order of ID.
+type UnreachableError struct {
+	Nodes []*Node
+}
+
+func (err UnreachableError) Error() string {
+	list := "[" + err.Nodes[0].String() + "]"
+	for _, n := range err.Nodes[1:] {
+		list += ", [" + n.String() + "]"
+	}
+
+	return "Nodes not reachable from any input: " + list
+}
+
+// Validate checks that every Node in the Network can be reached from at least one input Node --
+// either directly or through other Nodes, with or without delay. Nodes that cannot (e.g.
+// placeholders that were never replaced, or loops with no inputs from elsewhere) would never be
+// given any values that depend on the inputs. If there are any, Validate returns type
+// UnreachableError.
+//
+// Validate can be called at any time; it is not done by Finalize, which instead checks that every
+// Node affects the outputs. If the Network has already encountered an error, Validate returns it.
+func (net *Network) Validate() error {
+	if net.Error() != nil {
+		return net.Error()
+	}
+
+	reached := make([]bool, len(net.nodesByID))
+
+	// an explicit stack is used so that very deep Networks can't overflow the goroutine stack
+	var stack []*Node
+	for _, n := range net.nodesByID {
+		if n.IsInput() {
+			reached[n.id] = true
+			stack = append(stack, n)
+		}
+	}
+
+	for len(stack) != 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, out := range n.outputs.nodes {
+			if !reached[out.id] {
+				reached[out.id] = true
+				stack = append(stack, out)
+			}
+		}
+	}
+
+	var unreached []*Node
+	for _, n := range net.nodesByID {
+		if !reached[n.id] {
+			unreached = append(unreached, n)
+		}
+	}
+
+	if len(unreached) != 0 {
+		return UnreachableError{unreached}
+	}
+
+	return nil
+}
+
 // InstantCycleError is a result of a zero-delay cycle in a network, i.e. at least one Node
 // recieves input from itself with no delay.
 type InstantCycleError struct {
@@ -77,28 +163,29 @@ func (net *Network) checkGraph() error {
 		return nil
 	}
 
+	// Each of the checks below uses Node.completed, and resets it once finished. If one of them
+	// returns early with an error, we still need to leave all Nodes marked as incomplete so that
+	// later traversals (e.g. a second attempt to finalize) don't skip them.
+	defer net.resetCompletion()
+
 	// Check all nodes affect outputs
 	{
-		var mark func(*Node)
-		mark = func(n *Node) {
+		// Mark all Nodes that affect the network outputs. An explicit stack is used instead of
+		// recursion so that very deep Networks can't overflow it.
+		stack := append([]*Node(nil), net.outputs.nodes...)
+		for len(stack) != 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
 			if n.completed {
-				return
+				continue
 			}
 
 			n.completed = true
 
 			if !n.IsInput() {
-				for _, in := range n.inputs.nodes {
-					mark(in)
-				}
+				stack = append(stack, n.inputs.nodes...)
 			}
-			
-			return
-		}
-
-		// Mark all Nodes that affect the network outputs.
-		for _, out := range net.outputs.nodes {
-			mark(out)
 		}
 
 		// If any Nodes don't affect outputs, return error
@@ -159,6 +246,8 @@ func (net *Network) checkGraph() error {
 
 			for _, out := range root.outputs.nodes {
 				if !check(out) {
+					// stack starts with root, but the cycle must be reported from it as well
+					stack = append(stack, root)
 					return InstantCycleError{stack}
 				}
 			}
@@ -172,34 +261,53 @@ func (net *Network) checkGraph() error {
 		// if deltas should not be calculated, it will be indicated by the
 		// deltas of the Node having length 0
 
-		var matter func(*Node, bool)
-		matter = func(n *Node, dm bool) { // 'dm' is short for 'deltas matter'
-			if n.completed && (len(n.deltas) != 0 || !dm) {
-				return
-			}
+		type visit struct {
+			n  *Node
+			dm bool // short for 'deltas matter'
+		}
 
-			if n.IsInput() {
-				dm = false
-			} else {
-				dm = dm || n.adj != nil // if n is adjustable, deltas matter
-			}
+		// Like above, this uses an explicit stack instead of recursion
+		var stack []visit
+		matter := func(n *Node, dm bool) {
+			stack = append(stack, visit{n, dm})
+			for len(stack) != 0 {
+				v := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				n, dm := v.n, v.dm
+				if n.completed && (len(n.deltas) != 0 || !dm) {
+					continue
+				}
 
-			if dm {
-				n.deltas = make([]float64, n.Size())
-			}
-			n.completed = true
+				if n.IsInput() {
+					dm = false
+				} else {
+					dm = dm || n.adj != nil // if n is adjustable, deltas matter
+				}
 
-			for _, out := range n.outputs.nodes {
-				matter(out, dm)
-			}
+				if dm {
+					n.deltas = make([]float64, n.Size())
+				}
+				n.completed = true
 
-			return
+				for _, out := range n.outputs.nodes {
+					stack = append(stack, visit{out, dm})
+				}
+			}
 		}
 
 		for _, in := range net.inputs.nodes {
 			matter(in, false)
 		}
 
+		// Some Nodes may not be reachable from the inputs (e.g. a loop of Nodes with delay that
+		// only take input from each other). They still need their deltas if they're adjustable.
+		// Nodes that have already been visited will simply be skipped, so this is safe to do for
+		// all.
+		for _, n := range net.nodesByID {
+			matter(n, false)
+		}
+
 		for _, n := range net.nodesByID {
 			n.calcInDeltas = false
 
@@ -230,11 +338,8 @@ func (n *Node) setValues(values []float64) {
 	}
 }
 
-// These are completely arbitrary.
-const (
-	threadsPerCPU int = 2
-	opsPerThread  int = 10
-)
+// This is completely arbitrary.
+const opsPerThread int = 10
 
 // Assumes n is NOT an input Node
 func (n *Node) calculateValues() {
@@ -250,7 +355,7 @@ func (n *Node) calculateValues() {
 		n.values.Values[i] = n.elem.Value(inputs[i], i)
 	}
 
-	utils.MultiThread(0, n.Size(), f, opsPerThread, threadsPerCPU)
+	utils.MultiThreadN(0, n.Size(), f, opsPerThread, n.Workers())
 }
 
 // isGettingDeltas is used for determining, during evaluation, whether or not push or pull from
@@ -292,6 +397,7 @@ func (n *Node) evaluate() {
 		if !n.host.isGettingDeltas() {
 			// forward calculation, set from delay
 			n.setValues(<-n.delay)
+			n.storeValues()
 		} else {
 			// backprop, set from stored
 			n.setFromStored()
@@ -304,19 +410,25 @@ func (n *Node) evaluate() {
 	}
 
 	if n.HasDelay() {
-		n.values.Values = make([]float64, n.values.Size())
-	}
-
-	n.calculateValues()
+		// The values of a Node with delay are the ones from the previous time-step, and they must
+		// stay the same for the rest of this one. The new values are only sent into delay. If
+		// we're backpropagating, there's nothing to send; the values were set earlier.
+		if !n.host.isGettingDeltas() {
+			current := append([]float64(nil), n.values.Values...)
+			n.calculateValues()
+			n.delay <- append([]float64(nil), n.values.Values...)
+			copy(n.values.Values, current)
+		}
 
-	if n.HasDelay() && !n.host.isGettingDeltas() {
-		n.delay <- n.values.Values
-		n.storedValues = append(n.storedValues, n.values.Values)
+		return
 	}
 
-	// if we're backpropagating, don't worry about setting values; they were
-	// set earlier
+	// In a loop, this Node may have already been evaluated by way of its inputs
+	if n.completed {
+		return
+	}
 
+	n.calculateValues()
 	n.completed = true
 }
 
@@ -331,8 +443,20 @@ func (net *Network) evaluate() error {
 		return nil
 	}
 
-	for _, out := range net.outputs.nodes {
-		out.evaluate()
+	if net.hasDelay {
+		for _, out := range net.outputs.nodes {
+			out.evaluate()
+		}
+	} else {
+		// Because each Node's inputs have already been evaluated by the time it is reached, this
+		// doesn't recurse, even for very deep Networks.
+		if net.evalOrder == nil {
+			net.evalOrder = net.postOrder(net.outputs.nodes)
+		}
+
+		for _, n := range net.evalOrder {
+			n.evaluate()
+		}
 	}
 
 	net.resetCompletion()
@@ -343,6 +467,14 @@ func (net *Network) evaluate() error {
 
 // calculateInputDeltas is a helper function that does what it says.
 func (n *Node) calculateInputDeltas() {
+	// The deltas of a Node with delay are for the values it gives at the next time-step, so those
+	// are the ones its Operator needs. Its inputs have already been evaluated for this one.
+	if n.HasDelay() {
+		current := append([]float64(nil), n.values.Values...)
+		n.calculateValues()
+		defer copy(n.values.Values, current)
+	}
+
 	if n.lyr != nil {
 		ds := n.lyr.InputDeltas(n)
 		n.inputs.addDeltas(ds)
@@ -371,7 +503,7 @@ func (n *Node) calculateInputDeltas() {
 			ds[x-start] += n.elem.Deriv(n, x) * n.deltas[x]
 		}
 
-		utils.MultiThread(start, end, f, opsPerThread, threadsPerCPU)
+		utils.MultiThreadN(start, end, f, opsPerThread, n.Workers())
 
 		start = end
 	}
@@ -402,15 +534,23 @@ func (n *Node) inputDeltas() {
 		o.inputDeltas()
 	}
 
-	if !n.HasDelay() && n.calcInDeltas {
+	// In a loop, this Node may have already been completed by way of its outputs
+	if n.completed {
+		return
+	}
+
+	if n.calcInDeltas {
 		n.calculateInputDeltas()
 	}
 
 	n.completed = true
 }
 
-// assumes len(targets) == net.OutputSize(), net.stat >= evaluated
-func (net *Network) getDeltas(targets []float64) {
+// assumes d.Fits(net), net.stat >= evaluated
+//
+// The deltas of the outputs are given by 'cf', multiplied by the Weight of the sample and the loss
+// scale, if there is one.
+func (net *Network) getDeltas(d Datum, cf CostFunction) {
 	// reset deltas. For nodes without a need to calculate deltas, this will keep len(deltas) = 0.
 	for _, n := range net.nodesByID {
 		if n.HasDelay() {
@@ -422,20 +562,42 @@ func (net *Network) getDeltas(targets []float64) {
 	}
 
 	// add to output deltas
-	if len(targets) != 0 {
-		// we check if len(targets) is zero because recurrent models can exempt
+	if d.hasTargets() {
+		// we check if there are targets because recurrent models can exempt
 		// certain outputs from having significance by indicating providing no
 		// targets
 
 		// Indicating 'false' for duplicating opens the possibility of cost
 		// functions to corrupt data. This issue is not significant.
-		ds := net.cf.Derivs(net.outputs.getValues(false), targets)
+		ds := d.derivs(cf, net.outputs.getValues(false))
+
+		weight := d.weight()
+		if net.lossScale != 0 {
+			weight *= net.lossScale
+		}
+
+		if weight != 1 {
+			for i := range ds {
+				ds[i] *= weight
+			}
+		}
+
 		net.outputs.addDeltas(ds)
 	}
 
-	// recurse through network
-	for _, in := range net.inputs.nodes {
-		in.inputDeltas()
+	// recurse through network. We start from every Node, not just the inputs, so that those that
+	// aren't reachable from the inputs are still included. Completed Nodes will simply return.
+	if net.hasDelay {
+		for _, n := range net.nodesByID {
+			n.inputDeltas()
+		}
+	} else {
+		// in reverse topological order, the outputs of each Node will already be complete, so
+		// this doesn't recurse
+		sorted := net.sorted()
+		for i := len(sorted) - 1; i >= 0; i-- {
+			sorted[i].inputDeltas()
+		}
 	}
 
 	// put temporary delay deltas back into delay
@@ -452,12 +614,12 @@ func (net *Network) getDeltas(targets []float64) {
 
 // Assumptions:
 //	* net.stat >= finalized
-//	* len(targets[n]) == net.OutputSize(), for all n in range len(targets)
-func (net *Network) adjustRecurrent(targets [][]float64, saveChanges bool) {
-	for i := len(targets) - 1; i >= 0; i-- {
+//	* sequence[n].Fits(net), for all n in range len(sequence)
+func (net *Network) adjustRecurrent(sequence []Datum, saveChanges bool) {
+	for i := len(sequence) - 1; i >= 0; i-- {
 		net.evaluate()
 
-		net.getDeltas(targets[i])
+		net.getDeltas(sequence[i], net.reduced(net.cf))
 
 		// we use saveChanges=true here to prevent issues with
 		net.adjust(true)
@@ -476,13 +638,19 @@ func (net *Network) adjust(saveChanges bool) {
 
 	if saveChanges {
 		net.hasSavedChanges = true
+	} else {
+		net.syncTied()
+
+		// the weights have changed, so the current values are no longer accurate
+		net.stat = finalized
 	}
 
 	return
 }
 
 func (n *Node) adjust(saveChanges bool) {
-	if n.adj == nil {
+	// Nodes with tied weights are adjusted by the Node they're tied to
+	if n.adj == nil || n.frozen || n.tiedTo != nil {
 		return
 	}
 
@@ -494,28 +662,72 @@ func (n *Node) adjust(saveChanges bool) {
 		w = n.delayedWeights
 	}
 
-	var adj Adjustable
+	var adj Adjustable = n.adj
+	if len(n.tied) != 0 {
+		adj = tiedAdj{adj}
+	}
+	if s := n.host.lossScale; s != 0 && s != 1 {
+		adj = scaledAdj{adj, s}
+	}
 	if n.pen != nil {
-		adj = penAdj{n.adj}
-	} else {
-		adj = n.adj
+		adj = penAdj{adj}
 	}
 
 	n.opt.Run(n, adj, w)
 }
 
+// tiedAdj is a wrapper for the Adjustable of a Node that other Nodes are tied to (see TieWeights),
+// so that the gradient of each weight is summed across all of the tied Nodes.
+type tiedAdj struct {
+	Adjustable
+}
+
+func (t tiedAdj) Grad(n *Node, index int) float64 {
+	g := t.Adjustable.Grad(n, index)
+	for _, o := range n.tied {
+		g += o.adj.Grad(o, index)
+	}
+
+	return g
+}
+
+// scaledAdj is a wrapper for the Adjustable of a Node that divides each gradient by the loss scale
+// (see TrainArgs.LossScale), so that the Optimizer and Penalty are given the true gradients.
+type scaledAdj struct {
+	Adjustable
+	scale float64
+}
+
+func (s scaledAdj) Grad(n *Node, index int) float64 {
+	return s.Adjustable.Grad(n, index) / s.scale
+}
+
+// syncTied copies the weights of each Node that others have been tied to into those Nodes
+func (net *Network) syncTied() {
+	for _, n := range net.nodesByID {
+		if n.tiedTo != nil {
+			copy(n.adj.Weights(), n.tiedTo.adj.Weights())
+		}
+	}
+}
+
 // penAdj is a wrapper for the usual Adjustable found in Nodes, to allow for the same types of
-// interaction, but with added penalties for each weight.
+// interaction, but with added penalties for each weight. Biases (as given by Biased) are not
+// penalized.
 type penAdj struct {
 	Adjustable
 }
 
 func (p penAdj) Grad(n *Node, index int) float64 {
-	return n.pen.Penalize(n, n.adj, index)
+	if b, ok := n.adj.(Biased); ok && b.IsBias(n, index) {
+		return p.Adjustable.Grad(n, index)
+	}
+
+	return n.pen.Penalize(n, p.Adjustable, index)
 }
 
 func (p penAdj) Weights() []float64 {
-	return p.Weights()
+	return p.Adjustable.Weights()
 }
 
 func (n *Node) addWeights() {
@@ -534,10 +746,172 @@ func (n *Node) addWeights() {
 		ws[i] += n.delayedWeights[i]
 	}
 
-	utils.MultiThread(0, len(ws), f, opsPerThread, threadsPerCPU)
+	utils.MultiThreadN(0, len(ws), f, opsPerThread, n.Workers())
 	n.delayedWeights = make([]float64, len(ws))
 }
 
+// releaseWeights allows the Node's Operator to discard its temporary copy of the weights, if it
+// has one (see TemporaryWeights)
+func (n *Node) releaseWeights() {
+	if t, ok := n.adj.(TemporaryWeights); ok {
+		t.ReleaseWeights()
+	}
+}
+
+// releaseWeights calls releaseWeights for every Node in the Network
+func (net *Network) releaseWeights() {
+	for _, n := range net.nodesByID {
+		n.releaseWeights()
+	}
+}
+
+// firstNonFinite returns the first Node (by id) with values, weights, or saved changes to its
+// weights that are NaN or ±Inf, along with which of those it was: "values", "weights", or "changes
+// to weights". If there are none, the returned Node will be nil.
+func (net *Network) firstNonFinite() (*Node, string) {
+	finite := func(vs []float64) bool {
+		for _, v := range vs {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, n := range net.nodesByID {
+		// inputs are skipped, because NaN is used to mark missing values (see SetInputs)
+		if !n.IsInput() && !finite(n.values.Values) {
+			return n, "values"
+		} else if n.adj == nil {
+			continue
+		} else if !finite(n.adj.Weights()) {
+			return n, "weights"
+		} else if !finite(n.delayedWeights) {
+			return n, "changes to weights"
+		}
+	}
+
+	return nil, ""
+}
+
+// startUpdateNorms stores the current weights of every trainable Node, so that the size of the
+// changes to them can later be recorded by recordUpdateNorms
+func (net *Network) startUpdateNorms() {
+	if net.normStart == nil {
+		net.normStart = make([][]float64, len(net.nodesByID))
+	}
+
+	for i, n := range net.nodesByID {
+		if n.adj == nil || n.frozen {
+			net.normStart[i] = nil
+			continue
+		}
+
+		net.normStart[i] = append(net.normStart[i][:0], n.adj.Weights()...)
+	}
+}
+
+// recordUpdateNorms adds the L2 norm of the change to each trainable Node's weights since the last
+// call to startUpdateNorms to net.updateNorms, and then starts again.
+func (net *Network) recordUpdateNorms() {
+	for i, n := range net.nodesByID {
+		if net.normStart[i] == nil {
+			continue
+		}
+
+		var sum float64
+		for j, w := range n.adj.Weights() {
+			sum += (w - net.normStart[i][j]) * (w - net.normStart[i][j])
+		}
+
+		name := n.key()
+		net.updateNorms[name] = append(net.updateNorms[name], math.Sqrt(sum))
+	}
+
+	net.startUpdateNorms()
+}
+
+// clipChanges rescales all of the saved changes to the weights of the Network so that their total
+// L2 norm is no greater than 'norm'. If norm ≤ 0, clipChanges does nothing.
+func (net *Network) clipChanges(norm float64) {
+	if norm <= 0 || !net.hasSavedChanges {
+		return
+	}
+
+	var sum float64
+	for _, n := range net.nodesByID {
+		for _, c := range n.delayedWeights {
+			sum += c * c
+		}
+	}
+
+	total := math.Sqrt(sum)
+	if total <= norm {
+		return
+	}
+
+	scale := norm / total
+	for _, n := range net.nodesByID {
+		for i := range n.delayedWeights {
+			n.delayedWeights[i] *= scale
+		}
+	}
+}
+
+// startEMA sets the moving averages of the weights of each Node to their current weights, if they
+// are not already being tracked.
+//
+// Like updateEMA, startEMA assumes that the Network is not currently using the averaged weights.
+func (net *Network) startEMA() {
+	for _, n := range net.nodesByID {
+		if n.adj != nil && len(n.emaWeights) == 0 {
+			n.emaWeights = make([]float64, len(n.adj.Weights()))
+			copy(n.emaWeights, n.adj.Weights())
+		}
+	}
+}
+
+// updateEMA updates the exponential moving average of the weights of each Node, given the decay.
+// If decay ≤ 0, updateEMA does nothing.
+//
+// updateEMA assumes that the moving averages have been started, and that the Network is not
+// currently using the averaged weights.
+func (net *Network) updateEMA(decay float64) {
+	if decay <= 0 {
+		return
+	}
+
+	for _, n := range net.nodesByID {
+		if n.adj == nil {
+			continue
+		}
+
+		ws := n.adj.Weights()
+		for i := range ws {
+			n.emaWeights[i] = decay*n.emaWeights[i] + (1-decay)*ws[i]
+		}
+	}
+}
+
+// swapEMA exchanges the weights of each Node with their moving averages
+func (net *Network) swapEMA() {
+	for _, n := range net.nodesByID {
+		if n.adj == nil {
+			continue
+		}
+
+		ws := n.adj.Weights()
+		for i := range n.emaWeights {
+			ws[i], n.emaWeights[i] = n.emaWeights[i], ws[i]
+		}
+	}
+
+	net.releaseWeights()
+	net.usingEMA = !net.usingEMA
+	net.stat = finalized
+}
+
 // Updates the weights in the network with any previously saved changes.
 // Only runs if there are changes that have not been applied
 func (net *Network) AddWeights() {
@@ -549,7 +923,36 @@ func (net *Network) AddWeights() {
 		n.addWeights()
 	}
 
+	net.syncTied()
+	net.releaseWeights()
 	net.hasSavedChanges = false
 	net.stat = finalized
 	return
 }
+
+// ZeroGradients clears the deltas of every Node, along with any changes to the weights that have
+// been saved from an incomplete batch and would otherwise be applied by AddWeights. This ensures
+// that the next backward pass starts from nothing, which is useful for custom training loops. The
+// deltas held in delay by Nodes with delay are not affected; ResetState should be used for those.
+//
+// ZeroGradients does nothing if the Network has not been finalized.
+func (net *Network) ZeroGradients() {
+	if net.stat < finalized {
+		return
+	}
+
+	for _, n := range net.nodesByID {
+		for i := range n.deltas {
+			n.deltas[i] = 0
+		}
+
+		if len(n.delayedWeights) != 0 {
+			n.delayedWeights = make([]float64, len(n.delayedWeights))
+		}
+	}
+
+	net.hasSavedChanges = false
+	if net.stat > evaluated {
+		net.stat = evaluated
+	}
+}
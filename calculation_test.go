@@ -0,0 +1,357 @@
+package badstudent_test
+
+import (
+	"math"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+	"github.com/sharnoff/tensors"
+)
+
+// branchedNet returns a finalized Network where a single hidden Node feeds two output Nodes, so
+// that the deltas of the hidden Node depend on both of them
+func branchedNet(tb testing.TB) *bs.Network {
+	tb.Helper()
+
+	net := new(bs.Network)
+	in := net.AddInput([]int{2})
+	hidden := net.Add(operators.Logistic(), net.Add(operators.Neurons(4), in).Opt(optimizers.SGD()))
+	a := net.Add(operators.Neurons(1), hidden).Opt(optimizers.SGD())
+	b := net.Add(operators.Logistic(), net.Add(operators.Neurons(1), hidden).Opt(optimizers.SGD()))
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), a, b); err != nil {
+		tb.Fatalf("Failed to finalize Network: %v", err)
+	}
+
+	randomWeights(net, 2)
+	return net
+}
+
+func TestBranchedDeltas(t *testing.T) {
+	// with several workers, the calculations of each Node are split between goroutines, which the
+	// race detector can check
+	net := branchedNet(t).SetWorkers(4)
+
+	inputs, targets := []float64{0.4, -0.7}, []float64{0.5, 0.2}
+
+	cost := func(ins, targets []float64) float64 {
+		outs, err := net.GetOutputs(ins)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sum float64
+		for i := range outs {
+			sum += 0.5 * (outs[i] - targets[i]) * (outs[i] - targets[i])
+		}
+
+		return sum
+	}
+
+	// the hidden Node must calculate its deltas exactly once, from both of its outputs, no matter
+	// how many of them ask for it
+	grads, err := net.InputGradients(inputs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const epsilon = 1e-6
+	for i := range inputs {
+		ins := append([]float64(nil), inputs...)
+		ins[i] = inputs[i] + epsilon
+		plus := cost(ins, targets)
+		ins[i] = inputs[i] - epsilon
+		minus := cost(ins, targets)
+
+		if numeric := (plus - minus) / (2 * epsilon); math.Abs(grads[i]-numeric) > 1e-6 {
+			t.Errorf("Gradient of input %d is %v, estimated %v", i, grads[i], numeric)
+		}
+	}
+
+	// training repeats the same backward pass many times
+	dataset := [][][]float64{{inputs, targets}, {{-0.2, 0.9}, {-0.5, 0.8}}}
+	total := func() float64 { return cost(dataset[0][0], dataset[0][1]) + cost(dataset[1][0], dataset[1][1]) }
+
+	before := total()
+	args := bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(200)}
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := total(); !(after < before) {
+		t.Errorf("Training did not reduce the cost (from %v to %v)", before, after)
+	}
+}
+
+// The Node 'hidden' only takes input from itself, through delay, so it can't be reached from the
+// inputs of the Network. Its weights must still be given gradients.
+func TestUnreachableDeltas(t *testing.T) {
+	net := new(bs.Network)
+	in := net.AddInput([]int{2})
+	loop := net.Placeholder([]int{2})
+	hidden := net.Add(operators.Tanh(), net.Add(operators.Neurons(2), loop).Opt(optimizers.SGD()))
+	loop.Replace(operators.Identity(), hidden).SetDelay(1)
+	out := net.Add(operators.Neurons(1), in, hidden).Opt(optimizers.SGD())
+
+	// with SGD and a learning rate of one, the change to each weight is its gradient
+	net.AddHP("learning-rate", hyperparams.Constant(1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+	randomWeights(net, 1)
+
+	seq := [][][]float64{{{0.5, -1}, {0.3}}, {{1, 0.2}, {-0.4}}, {{-0.7, 0.1}, {0.9}}}
+
+	cost := func(ws []float64) float64 {
+		net.SetWeights(ws)
+		net.ResetState()
+
+		var sum float64
+		for _, d := range seq {
+			outs, err := net.GetOutputs(d[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			sum += 0.5 * (outs[0] - d[1][0]) * (outs[0] - d[1][0])
+		}
+
+		net.ResetState()
+		return sum
+	}
+
+	const epsilon = 1e-6
+
+	weights := net.Weights()
+	numeric := make([]float64, len(weights))
+	for i := range weights {
+		ws := append([]float64(nil), weights...)
+		ws[i] = weights[i] + epsilon
+		plus := cost(ws)
+		ws[i] = weights[i] - epsilon
+		minus := cost(ws)
+
+		numeric[i] = (plus - minus) / (2 * epsilon)
+	}
+
+	net.SetWeights(weights)
+	data, err := bs.SeqData(seq, len(seq), len(seq))
+	if err != nil {
+		t.Fatal(err)
+	} else if err := net.Train(bs.TrainArgs{TrainData: data, RunCondition: bs.TrainUntil(len(seq))}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the weights of 'hidden' come first
+	for i, w := range net.Weights() {
+		if grad := weights[i] - w; math.Abs(grad-numeric[i]) > 1e-6 {
+			t.Errorf("Gradient of weight %d is %v, estimated %v", i, grad, numeric[i])
+		}
+	}
+}
+
+// accumulator is a Stateful Operator that gives the running total of its inputs, across every
+// evaluation since its state was last reset
+type accumulator struct {
+	totals []float64
+}
+
+func (a *accumulator) TypeString() string        { return "accumulator" }
+func (a *accumulator) Finalize(n *bs.Node) error { return nil }
+func (a *accumulator) ResetState(n *bs.Node)     { a.totals = make([]float64, n.Size()) }
+
+func (a *accumulator) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	return inputs[0].Shape(), nil
+}
+
+func (a *accumulator) Evaluate(n *bs.Node, values []float64) {
+	if a.totals == nil {
+		a.totals = make([]float64, n.Size())
+	}
+
+	for i, v := range n.AllInputs() {
+		a.totals[i] += v
+		values[i] = a.totals[i]
+	}
+}
+
+func (a *accumulator) InputDeltas(n *bs.Node) []float64 {
+	ds := make([]float64, n.Size())
+	for i := range ds {
+		ds[i] = n.Delta(i)
+	}
+
+	return ds
+}
+
+func TestResetState(t *testing.T) {
+	acc := new(accumulator)
+
+	net := new(bs.Network)
+	l := net.Add(operators.Neurons(2), net.AddInput([]int{2})).Opt(optimizers.SGD())
+	l = net.Add(acc, l)
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	randomWeights(net, 3)
+	weights := net.Weights()
+
+	first, err := net.GetOutputs([]float64{0.5, -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// with the state of the accumulator, the outputs are the running total
+	inputs := []float64{1, 2}
+	second, err := net.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	net.ResetState()
+	for i, v := range acc.totals {
+		if v != 0 {
+			t.Errorf("State of the Operator at %d is %v after ResetState, expected 0", i, v)
+		}
+	}
+
+	if !sliceEqual(net.Weights(), weights, 0) {
+		t.Errorf("ResetState changed the weights of the Network")
+	}
+
+	// the outputs are no longer affected by the first evaluation, even though the inputs are the
+	// same as before
+	outs, err := net.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []float64{second[0] - first[0], second[1] - first[1]}
+	if !sliceEqual(outs, expected, 1e-12) {
+		t.Errorf("Outputs after ResetState are %v, expected %v", outs, expected)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := xorNet(t, 1).Validate(); err != nil {
+		t.Errorf("Validating the XOR Network gave error %v", err)
+	}
+
+	// a loop with no inputs from outside of it can't be reached
+	net := new(bs.Network)
+	in := net.AddInput([]int{2}).SetName("input")
+	loop := net.Placeholder([]int{2}).SetName("loop")
+	orphan := net.Add(operators.Tanh(), loop).SetName("orphan")
+	loop.Replace(operators.Identity(), orphan)
+	net.Add(operators.Neurons(2), in, orphan).SetName("out")
+
+	err := net.Validate()
+	e, ok := err.(bs.UnreachableError)
+	if !ok {
+		t.Fatalf("Validating a Network with an orphaned loop gave error %v, expected type UnreachableError", err)
+	}
+
+	if len(e.Nodes) != 2 || e.Nodes[0] != loop || e.Nodes[1] != orphan {
+		t.Errorf("Error lists Nodes %v, expected %v", e.Nodes, []*bs.Node{loop, orphan})
+	}
+
+	for _, name := range []string{"loop", "orphan"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Error message %q does not name Node %q", err.Error(), name)
+		}
+	}
+}
+
+func TestDeepChain(t *testing.T) {
+	const depth = 10000
+
+	// recursing through every Node would need more stack than this
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	net := new(bs.Network).HandleErrors()
+	l := net.AddInput([]int{2})
+	for i := 0; i < depth; i++ {
+		l = net.Add(operators.Identity(), l)
+	}
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{0.5, -2}
+	if outs, err := net.GetOutputs(inputs); err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(outs, inputs, 0) {
+		t.Errorf("Outputs are %v, expected the inputs %v", outs, inputs)
+	}
+
+	// backpropagation also goes through the whole chain
+	if ds, err := net.InputGradients(inputs, []float64{0, 0}, nil); err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(ds, inputs, 0) {
+		t.Errorf("Input gradients are %v, expected %v", ds, inputs)
+	}
+}
+
+func TestZeroGradients(t *testing.T) {
+	dataset := [][][]float64{
+		{{1, 0}, {1}},
+		{{0, 1}, {-1}},
+	}
+
+	// train returns the weights of a linear Network after 'epochs' epochs of 'dataset', with all of
+	// the changes accumulated into a single batch. If 'zero' is true, ZeroGradients is called at the
+	// end of the first epoch, discarding the changes from it.
+	train := func(epochs int, zero bool) []float64 {
+		net := new(bs.Network)
+		out := net.Add(operators.Neurons(1), net.AddInput([]int{2})).Opt(optimizers.SGD())
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+			t.Fatal(err)
+		} else if err := net.SetWeights([]float64{0.5, -0.25, 0.1}); err != nil {
+			t.Fatal(err)
+		}
+
+		args := bs.TrainArgs{
+			Data:         bs.SliceSource(dataset),
+			RunCondition: bs.TrainUntil(epochs * len(dataset)),
+			AccumSteps:   epochs * len(dataset),
+			OnEpoch: func(r bs.EpochResult) {
+				if zero && r.Epoch == 1 {
+					net.ZeroGradients()
+					if d := out.Delta(0); d != 0 {
+						t.Errorf("Delta of output after ZeroGradients is %v, expected 0", d)
+					}
+				}
+			},
+		}
+
+		if err := net.Train(args); err != nil {
+			t.Fatal(err)
+		}
+
+		return net.Weights()
+	}
+
+	// without any changes from the first epoch, the second should be the same as training once
+	once, zeroed, full := train(1, false), train(2, true), train(2, false)
+	if !sliceEqual(zeroed, once, 1e-12) {
+		t.Errorf("Weights after zeroing the first epoch are %v, expected %v", zeroed, once)
+	}
+
+	if sliceEqual(full, once, 1e-12) {
+		t.Errorf("Weights without zeroing are the same as from one epoch: %v", full)
+	}
+}
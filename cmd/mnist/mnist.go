@@ -59,7 +59,7 @@ func (d *dataset) Get(index int) (bs.Datum, error) {
 		d.index = 0
 	}
 
-	return bs.Datum{ins, outs}, nil
+	return bs.Datum{Inputs: ins, Outputs: outs}, nil
 }
 
 func (d *dataset) BatchEnded(index int) bool {
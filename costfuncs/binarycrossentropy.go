@@ -0,0 +1,77 @@
+package costfuncs
+
+import (
+	"fmt"
+	"math"
+)
+
+// outputs are clamped to [bceEpsilon, 1 - bceEpsilon] in the calculation of binary cross-entropy,
+// so that the cost and derivatives remain finite
+const bceEpsilon float64 = 1e-12
+
+type binaryCrossEntropy bool
+
+// BinaryCrossEntropy returns the binary cross-entropy cost function, which implements
+// badstudent.CostFunction. It is intended for multi-label classification, where each output is
+// the independent probability of its label (e.g. from Logistic), and any number of targets may be
+// 1. This differs from CrossEntropy, which assumes that exactly one class is correct.
+//
+// The cost is the sum of -[t*log(o) + (1-t)*log(1-o)] over each output o and target t. The
+// derivative for each output is (o - t) / (o*(1 - o)), so that when it is passed back through
+// Logistic, the derivative w.r.t. its input is simply o - t. For stability, outputs are clamped to
+// be within 1e-12 of 0 and 1.
+func BinaryCrossEntropy() *binaryCrossEntropy {
+	b := binaryCrossEntropy(false)
+	return &b
+}
+
+func (b *binaryCrossEntropy) TypeString() string {
+	return "binary-cross-entropy"
+}
+
+func (b *binaryCrossEntropy) PrintOuts() *binaryCrossEntropy {
+	*b = binaryCrossEntropy(true)
+	return b
+}
+
+func (b *binaryCrossEntropy) NoPrint() *binaryCrossEntropy {
+	*b = binaryCrossEntropy(false)
+	return b
+}
+
+// clamp limits the output to be within bceEpsilon of 0 and 1
+func (b *binaryCrossEntropy) clamp(o float64) float64 {
+	return math.Min(math.Max(o, bceEpsilon), 1-bceEpsilon)
+}
+
+func (b *binaryCrossEntropy) Cost(outs, targets []float64) float64 {
+	var sum float64
+	for i := range outs {
+		o := b.clamp(outs[i])
+		sum -= targets[i]*math.Log(o) + (1-targets[i])*math.Log(1-o)
+	}
+
+	if bool(*b) {
+		fmt.Println(targets, outs)
+	}
+
+	return sum
+}
+
+func (b *binaryCrossEntropy) Derivs(outs, targets []float64) []float64 {
+	ds := make([]float64, len(outs))
+	for i := range outs {
+		o := b.clamp(outs[i])
+		ds[i] = (o - targets[i]) / (o * (1 - o))
+	}
+
+	return ds
+}
+
+func (b *binaryCrossEntropy) Get() interface{} {
+	return *b
+}
+
+func (b *binaryCrossEntropy) Blank() interface{} {
+	return b
+}
@@ -0,0 +1,82 @@
+package costfuncs_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+)
+
+func TestBinaryCrossEntropyGradient(t *testing.T) {
+	b := costfuncs.BinaryCrossEntropy()
+
+	outs, targets := []float64{0.8, 0.3, 0.6}, []float64{1, 0, 1}
+
+	const h = 1e-6
+	ds := b.Derivs(outs, targets)
+	for i := range outs {
+		plus := append([]float64{}, outs...)
+		minus := append([]float64{}, outs...)
+		plus[i] += h
+		minus[i] -= h
+
+		numeric := (b.Cost(plus, targets) - b.Cost(minus, targets)) / (2 * h)
+		if math.Abs(numeric-ds[i]) > 1e-6 {
+			t.Errorf("Derivative for output %d is %v, expected %v from finite differences", i, ds[i], numeric)
+		}
+	}
+
+	// outputs of exactly 0 or 1 are clamped
+	for _, o := range []float64{0, 1} {
+		outs, targets := []float64{o}, []float64{1 - o}
+		if c, d := b.Cost(outs, targets), b.Derivs(outs, targets)[0]; math.IsInf(c, 0) || math.IsInf(d, 0) || math.IsNaN(d) {
+			t.Errorf("Output of %v with target %v gave cost %v and derivative %v, expected finite values", o, 1-o, c, d)
+		}
+	}
+}
+
+func TestBinaryCrossEntropyMultiLabel(t *testing.T) {
+	op := operators.Neurons(3)
+
+	net := new(bs.Network).HandleErrors()
+	hidden := net.Add(op, net.AddInput([]int{2}))
+	out := net.Add(operators.Logistic(), hidden)
+
+	net.AddHP("learning-rate", hyperparams.Constant(1))
+	if err := net.Finalize(costfuncs.BinaryCrossEntropy(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs, targets := []float64{0.5, -1}, []float64{1, 0, 1}
+	outs, err := net.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := net.Weights()
+	if err := net.Train(bs.TrainArgs{Data: bs.SliceSource([][][]float64{{inputs, targets}}), RunCondition: bs.TrainUntil(1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// through Logistic, the gradient w.r.t. each bias is simply o - t, so with a learning rate of
+	// 1, each bias changes by t - o
+	var j int
+	for i, w := range net.Weights() {
+		if !op.IsBias(hidden, i) {
+			continue
+		}
+
+		if change, expected := w-before[i], targets[j]-outs[j]; math.Abs(change-expected) > 1e-12 {
+			t.Errorf("Bias for output %d changed by %v, expected %v", j, change, expected)
+		}
+
+		j++
+	}
+
+	if j != len(targets) {
+		t.Errorf("Found %d biases, expected %d", j, len(targets))
+	}
+}
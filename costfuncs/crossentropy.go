@@ -1,12 +1,16 @@
 package costfuncs
 
 import (
-	"math"
 	"fmt"
+	"github.com/pkg/errors"
+	"math"
 )
 
 type crossEntropy bool
 
+// CrossEntropy returns the cross-entropy cost function, which implements both
+// badstudent.CostFunction and badstudent.LabelCostFunction. The cost is the negative sum of
+// target*log(output), averaged over the number of outputs.
 func CrossEntropy() *crossEntropy {
 	c := crossEntropy(false)
 	return &c
@@ -30,10 +34,24 @@ func (c *crossEntropy) NoPrint() *crossEntropy {
 	return c
 }
 
+// CheckSize is the implementation of badstudent.SizedCostFunction. Cross-entropy compares
+// distributions across the outputs, so there must be at least two; BinaryCrossEntropy should be
+// used for a single output.
+func (c *crossEntropy) CheckSize(outputs int) error {
+	if outputs < 2 {
+		return errors.Errorf("Cross-entropy requires at least two outputs; use binary cross-entropy for one")
+	}
+
+	return nil
+}
+
 func (c *crossEntropy) Cost(outs, targets []float64) float64 {
 	var sum float64
 	for i := range outs {
-		sum -= outs[i] * math.Log(targets[i])
+		// terms with zero targets are skipped so that zero outputs don't give NaN
+		if targets[i] != 0 {
+			sum -= targets[i] * math.Log(outs[i])
+		}
 	}
 
 	sum /= float64(len(outs))
@@ -54,6 +72,20 @@ func (c *crossEntropy) Derivs(outs, targets []float64) []float64 {
 	return ds
 }
 
+func (c *crossEntropy) LabelCost(outs []float64, label int) float64 {
+	if bool(*c) {
+		fmt.Println(label, outs)
+	}
+
+	return -math.Log(outs[label]) / float64(len(outs))
+}
+
+func (c *crossEntropy) LabelDerivs(outs []float64, label int) []float64 {
+	ds := make([]float64, len(outs))
+	ds[label] = -1 / outs[label]
+	return ds
+}
+
 func (c *crossEntropy) Get() interface{} {
 	return *c
 }
@@ -0,0 +1,96 @@
+package costfuncs_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+)
+
+func TestCrossEntropyLabels(t *testing.T) {
+	c := costfuncs.CrossEntropy()
+
+	outs := []float64{0.2, 0.5, 0.1, 0.2}
+	for label := range outs {
+		targets := make([]float64, len(outs))
+		targets[label] = 1
+
+		if lc, dc := c.LabelCost(outs, label), c.Cost(outs, targets); lc != dc {
+			t.Errorf("Cost with label %d is %v, expected %v from one-hot targets", label, lc, dc)
+		}
+
+		if lds, dds := c.LabelDerivs(outs, label), c.Derivs(outs, targets); !sliceEqual(lds, dds) {
+			t.Errorf("Derivatives with label %d are %v, expected %v from one-hot targets", label, lds, dds)
+		}
+	}
+}
+
+// nextCounter is a DataSource of identical samples that records how many have been requested
+type nextCounter struct {
+	ins, targets []float64
+	calls        int
+}
+
+func (s *nextCounter) Next() ([]float64, []float64, bool) {
+	s.calls++
+	return s.ins, s.targets, true
+}
+
+func (s *nextCounter) Reset() {}
+
+func TestCheckSize(t *testing.T) {
+	segs, err := costfuncs.Segmented(
+		costfuncs.Segment{Start: 0, End: 1, Cost: costfuncs.MSE()},
+		costfuncs.Segment{Start: 1, End: 3, Cost: costfuncs.CrossEntropy()},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		cf       bs.CostFunction
+		outputs  int
+		mismatch bool
+	}{
+		{"cross-entropy, 1 output", costfuncs.CrossEntropy(), 1, true},
+		{"cross-entropy, 2 outputs", costfuncs.CrossEntropy(), 2, false},
+		{"segmented, 4 outputs", segs, 4, true},
+		{"segmented, 3 outputs", segs, 3, false},
+	}
+
+	for _, test := range tests {
+		net := new(bs.Network)
+		out := net.Add(operators.Neurons(test.outputs), net.AddInput([]int{2}))
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := net.Finalize(test.cf, out); err != nil {
+			t.Fatal(err)
+		}
+
+		src := &nextCounter{ins: []float64{1, 0}, targets: make([]float64, test.outputs)}
+		src.targets[test.outputs-1] = 1
+
+		err := net.Train(bs.TrainArgs{Data: src, RunCondition: bs.TrainUntil(1)})
+		if _, ok := err.(bs.CostMismatchError); ok != test.mismatch {
+			t.Errorf("%s: Train gave error %v, expected CostMismatchError: %v", test.name, err, test.mismatch)
+		} else if test.mismatch && src.calls != 0 {
+			t.Errorf("%s: %d samples were requested before the mismatch was reported", test.name, src.calls)
+		}
+	}
+}
+
+func sliceEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package costfuncs
+
+import (
+	"fmt"
+	"math"
+)
+
+type hinge bool
+
+// Hinge returns the hinge loss cost function, which implements badstudent.CostFunction. It is
+// used for SVM-style maximum-margin classification.
+//
+// Hinge expects each target to be either -1 or 1, and the outputs to be unbounded (e.g. from
+// Identity). For each output, the cost is max(0, 1 - target*output), and the total cost is the sum
+// of these. Outputs that are on the correct side of the margin (target*output ≥ 1) contribute
+// nothing to the cost and have derivatives of zero.
+func Hinge() *hinge {
+	h := hinge(false)
+	return &h
+}
+
+func (h *hinge) TypeString() string {
+	return "hinge"
+}
+
+func (h *hinge) PrintOuts() *hinge {
+	*h = hinge(true)
+	return h
+}
+
+func (h *hinge) NoPrint() *hinge {
+	*h = hinge(false)
+	return h
+}
+
+func (h *hinge) Cost(outs, targets []float64) float64 {
+	var sum float64
+	for i := range outs {
+		sum += math.Max(0, 1-targets[i]*outs[i])
+	}
+
+	if bool(*h) {
+		fmt.Println(targets, outs)
+	}
+
+	return sum
+}
+
+func (h *hinge) Derivs(outs, targets []float64) []float64 {
+	ds := make([]float64, len(outs))
+	for i := range outs {
+		if targets[i]*outs[i] < 1 {
+			ds[i] = -targets[i]
+		}
+	}
+
+	return ds
+}
+
+func (h *hinge) Get() interface{} {
+	return *h
+}
+
+func (h *hinge) Blank() interface{} {
+	return h
+}
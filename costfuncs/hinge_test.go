@@ -0,0 +1,44 @@
+package costfuncs_test
+
+import (
+	"testing"
+
+	"github.com/sharnoff/badstudent/costfuncs"
+)
+
+func TestHinge(t *testing.T) {
+	h := costfuncs.Hinge()
+
+	tests := []struct {
+		out, target float64
+		cost, deriv float64
+	}{
+		// on the correct side of the margin, or exactly on it
+		{2, 1, 0, 0},
+		{1, 1, 0, 0},
+		{-1.5, -1, 0, 0},
+		// inside the margin, or on the wrong side
+		{0.25, 1, 0.75, -1},
+		{-3, 1, 4, -1},
+		{0.5, -1, 1.5, 1},
+		{-0.5, -1, 0.5, 1},
+	}
+
+	for _, test := range tests {
+		outs, targets := []float64{test.out}, []float64{test.target}
+
+		if c := h.Cost(outs, targets); c != test.cost {
+			t.Errorf("Cost of %v with target %v is %v, expected %v", test.out, test.target, c, test.cost)
+		}
+
+		if d := h.Derivs(outs, targets)[0]; d != test.deriv {
+			t.Errorf("Derivative at %v with target %v is %v, expected %v", test.out, test.target, d, test.deriv)
+		}
+	}
+
+	// the total is the sum over the outputs
+	outs, targets := []float64{2, 0.25, 0.5}, []float64{1, 1, -1}
+	if c := h.Cost(outs, targets); c != 2.25 {
+		t.Errorf("Cost of %v with targets %v is %v, expected 2.25", outs, targets, c)
+	}
+}
@@ -0,0 +1,73 @@
+package costfuncs
+
+import (
+	"fmt"
+	"math"
+)
+
+// the smallest value that outputs are allowed to take in the calculation of KL divergence, so
+// that the cost and derivatives remain finite
+const klMinOutput float64 = 1e-12
+
+type klDivergence bool
+
+// KLDivergence returns the Kullback-Leibler divergence cost function, which implements
+// badstudent.CostFunction. It measures how much the outputs differ from the targets, treating both
+// as probability distributions.
+//
+// Both the targets and the outputs should be valid distributions: each value in [0, 1], summing to
+// 1 (e.g. outputs from Softmax). The cost is the sum of target*log(target/output) over all values,
+// where terms with a target of zero contribute nothing. The derivative for each output is
+// -target/output. For stability, outputs are clamped to be no less than 1e-12 in both.
+func KLDivergence() *klDivergence {
+	k := klDivergence(false)
+	return &k
+}
+
+func (k *klDivergence) TypeString() string {
+	return "kl-divergence"
+}
+
+func (k *klDivergence) PrintOuts() *klDivergence {
+	*k = klDivergence(true)
+	return k
+}
+
+func (k *klDivergence) NoPrint() *klDivergence {
+	*k = klDivergence(false)
+	return k
+}
+
+func (k *klDivergence) Cost(outs, targets []float64) float64 {
+	var sum float64
+	for i := range outs {
+		if targets[i] == 0 {
+			continue
+		}
+
+		sum += targets[i] * math.Log(targets[i]/math.Max(outs[i], klMinOutput))
+	}
+
+	if bool(*k) {
+		fmt.Println(targets, outs)
+	}
+
+	return sum
+}
+
+func (k *klDivergence) Derivs(outs, targets []float64) []float64 {
+	ds := make([]float64, len(outs))
+	for i := range outs {
+		ds[i] = -targets[i] / math.Max(outs[i], klMinOutput)
+	}
+
+	return ds
+}
+
+func (k *klDivergence) Get() interface{} {
+	return *k
+}
+
+func (k *klDivergence) Blank() interface{} {
+	return k
+}
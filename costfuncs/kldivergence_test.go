@@ -0,0 +1,78 @@
+package costfuncs_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/sharnoff/badstudent/costfuncs"
+)
+
+func softmax(xs []float64) []float64 {
+	var sum float64
+	outs := make([]float64, len(xs))
+	for i, x := range xs {
+		outs[i] = math.Exp(x)
+		sum += outs[i]
+	}
+
+	for i := range outs {
+		outs[i] /= sum
+	}
+
+	return outs
+}
+
+func TestKLDivergenceGradient(t *testing.T) {
+	kl := costfuncs.KLDivergence()
+
+	logits := []float64{0.5, -1.2, 2.0, 0.1}
+	targets := []float64{0.1, 0.2, 0.6, 0.1}
+
+	// the gradient with respect to the logits, by the chain rule through softmax
+	outs := softmax(logits)
+	ds := kl.Derivs(outs, targets)
+	analytic := make([]float64, len(logits))
+	for i := range logits {
+		for j := range outs {
+			jacobian := outs[j] * (-outs[i])
+			if i == j {
+				jacobian += outs[j]
+			}
+
+			analytic[i] += ds[j] * jacobian
+		}
+	}
+
+	const h = 1e-6
+	for i := range logits {
+		plus := append([]float64{}, logits...)
+		minus := append([]float64{}, logits...)
+		plus[i] += h
+		minus[i] -= h
+
+		numeric := (kl.Cost(softmax(plus), targets) - kl.Cost(softmax(minus), targets)) / (2 * h)
+		if math.Abs(numeric-analytic[i]) > 1e-6 {
+			t.Errorf("Gradient for logit %d is %v, expected %v from finite differences", i, analytic[i], numeric)
+		}
+	}
+
+	// identical distributions have no divergence
+	if c := kl.Cost(targets, targets); math.Abs(c) > 1e-15 {
+		t.Errorf("Cost of identical distributions is %v, expected 0", c)
+	}
+}
+
+func TestKLDivergenceZeroOutput(t *testing.T) {
+	kl := costfuncs.KLDivergence()
+
+	outs, targets := []float64{0, 1}, []float64{0.5, 0.5}
+	if c := kl.Cost(outs, targets); math.IsInf(c, 0) || math.IsNaN(c) {
+		t.Errorf("Cost with an output of zero is %v, expected a finite value", c)
+	}
+
+	for i, d := range kl.Derivs(outs, targets) {
+		if math.IsInf(d, 0) || math.IsNaN(d) {
+			t.Errorf("Derivative %d with an output of zero is %v, expected a finite value", i, d)
+		}
+	}
+}
@@ -8,6 +8,9 @@ func init() {
 		func() bs.CostFunction { return Huber(0) },
 		func() bs.CostFunction { return MSE() },
 		func() bs.CostFunction { return Abs() },
+		func() bs.CostFunction { return Hinge() },
+		func() bs.CostFunction { return KLDivergence() },
+		func() bs.CostFunction { return BinaryCrossEntropy() },
 	}
 
 	if err := bs.RegisterAll(list); err != nil {
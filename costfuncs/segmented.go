@@ -0,0 +1,126 @@
+package costfuncs
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+)
+
+// Segment assigns a cost function to the range of outputs from Start up to (but not including)
+// End.
+type Segment struct {
+	Start, End int
+	Cost       bs.CostFunction
+}
+
+type segmented struct {
+	segs []Segment
+}
+
+// Segmented returns a cost function, which implements badstudent.CostFunction, that applies a
+// different cost function to each range of outputs, as in networks with multiple heads. The total
+// cost is the sum of the costs of each Segment, and the derivatives of each Segment are given
+// only by its own cost function.
+//
+// The Segments must be given in order, starting at zero, each non-empty and beginning where the
+// previous ended, so that no outputs overlap; Segmented will return an error otherwise. The End of
+// the final Segment must be equal to the number of outputs. This is checked before training (see
+// badstudent.SizedCostFunction), and Cost and Derivs will panic if it is not the case.
+//
+// The result also implements badstudent.SeparableCostFunction, so it can be used with any
+// badstudent.Reduction, provided that the cost function of each Segment can be.
+//
+// Because its components cannot be recorded, Segmented is not registered, and Networks using it
+// cannot be loaded after saving.
+func Segmented(segs ...Segment) (*segmented, error) {
+	if len(segs) == 0 {
+		return nil, errors.New("Segmented cost function must have at least one Segment")
+	}
+
+	expected := 0
+	for i, s := range segs {
+		if s.Cost == nil {
+			return nil, errors.Errorf("Segment %d has nil cost function", i)
+		} else if s.Start != expected {
+			return nil, errors.Errorf("Segment %d starts at %d, expected %d", i, s.Start, expected)
+		} else if s.End <= s.Start {
+			return nil, errors.Errorf("Segment %d is empty (start %d, end %d)", i, s.Start, s.End)
+		}
+
+		expected = s.End
+	}
+
+	return &segmented{append([]Segment(nil), segs...)}, nil
+}
+
+func (s *segmented) TypeString() string {
+	return "segmented"
+}
+
+func (s *segmented) CheckSize(outputs int) error {
+	if end := s.segs[len(s.segs)-1].End; end != outputs {
+		return errors.Errorf("Segments cover %d outputs, but there are %d", end, outputs)
+	}
+
+	for i, seg := range s.segs {
+		if sc, ok := seg.Cost.(bs.SizedCostFunction); ok {
+			if err := sc.CheckSize(seg.End - seg.Start); err != nil {
+				return errors.Wrapf(err, "Segment %d", i)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *segmented) check(outs []float64) {
+	if end := s.segs[len(s.segs)-1].End; end != len(outs) {
+		panic(fmt.Sprintf("Segments cover %d outputs, but there are %d", end, len(outs)))
+	}
+}
+
+func (s *segmented) Cost(outs, targets []float64) float64 {
+	s.check(outs)
+
+	var sum float64
+	for _, seg := range s.segs {
+		sum += seg.Cost.Cost(outs[seg.Start:seg.End], targets[seg.Start:seg.End])
+	}
+
+	return sum
+}
+
+func (s *segmented) Derivs(outs, targets []float64) []float64 {
+	s.check(outs)
+
+	ds := make([]float64, 0, len(outs))
+	for _, seg := range s.segs {
+		ds = append(ds, seg.Cost.Derivs(outs[seg.Start:seg.End], targets[seg.Start:seg.End])...)
+	}
+
+	return ds
+}
+
+// OutputCosts is the implementation of badstudent.SeparableCostFunction. The cost of each output is
+// given by the cost function of its Segment -- through OutputCosts, if it is also separable, or
+// otherwise by its Cost with only that output.
+func (s *segmented) OutputCosts(outs, targets []float64) []float64 {
+	s.check(outs)
+
+	cs := make([]float64, 0, len(outs))
+	for _, seg := range s.segs {
+		o, t := outs[seg.Start:seg.End], targets[seg.Start:seg.End]
+
+		if sc, ok := seg.Cost.(bs.SeparableCostFunction); ok {
+			cs = append(cs, sc.OutputCosts(o, t)...)
+			continue
+		}
+
+		for i := range o {
+			cs = append(cs, seg.Cost.Cost(o[i:i+1], t[i:i+1]))
+		}
+	}
+
+	return cs
+}
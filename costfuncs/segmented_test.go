@@ -0,0 +1,196 @@
+package costfuncs_test
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	_ "github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	_ "github.com/sharnoff/badstudent/optimizers"
+)
+
+// twoHeads returns a Network with a regression head (one linear output) followed by a
+// classification head (two softmax outputs), both from the same two inputs, along with the
+// cost function for them
+func twoHeads(t *testing.T) (*bs.Network, bs.CostFunction) {
+	t.Helper()
+
+	cf, err := costfuncs.Segmented(
+		costfuncs.Segment{Start: 0, End: 1, Cost: costfuncs.MSE()},
+		costfuncs.Segment{Start: 1, End: 3, Cost: costfuncs.CrossEntropy()},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	net := new(bs.Network).HandleErrors()
+	in := net.AddInput([]int{2})
+	reg := net.Add(operators.Neurons(1), in)
+	class := net.Add(operators.Softmax(), net.Add(operators.Neurons(2), in))
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(cf, reg, class); err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	ws := net.Weights()
+	for i := range ws {
+		ws[i] = rng.Float64()*2 - 1
+	}
+	net.SetWeights(ws)
+
+	return net, cf
+}
+
+func TestSegmentedTwoHeads(t *testing.T) {
+	net, cf := twoHeads(t)
+
+	outs := []float64{0.5, 0.25, 0.75}
+	targets := []float64{2, 0, 1}
+
+	// the total cost and derivatives are those of each head, with its own cost function
+	mse, ce := costfuncs.MSE(), costfuncs.CrossEntropy()
+
+	expected := mse.Cost(outs[:1], targets[:1]) + ce.Cost(outs[1:], targets[1:])
+	if c := cf.Cost(outs, targets); math.Abs(c-expected) > 1e-12 {
+		t.Errorf("Cost is %v, expected %v", c, expected)
+	}
+
+	expectedDs := append(mse.Derivs(outs[:1], targets[:1]), ce.Derivs(outs[1:], targets[1:])...)
+	for i, d := range cf.Derivs(outs, targets) {
+		if math.Abs(d-expectedDs[i]) > 1e-12 {
+			t.Errorf("Derivative %d is %v, expected %v", i, d, expectedDs[i])
+		}
+	}
+
+	// both heads must contribute to the gradients of the inputs. With ReduceSum, the derivatives
+	// are exactly those of the sum of the costs of each output.
+	net.SetReduction(bs.ReduceSum)
+	sumCost := func(outs []float64) float64 {
+		var sum float64
+		for _, c := range cf.(bs.SeparableCostFunction).OutputCosts(outs, targets) {
+			sum += c
+		}
+
+		return sum
+	}
+
+	inputs := []float64{0.3, -0.6}
+	grads, err := net.InputGradients(inputs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const epsilon = 1e-6
+	for i := range inputs {
+		ins := append([]float64(nil), inputs...)
+
+		ins[i] = inputs[i] + epsilon
+		outs, _ := net.GetOutputs(ins)
+		plus := sumCost(outs)
+
+		ins[i] = inputs[i] - epsilon
+		outs, _ = net.GetOutputs(ins)
+		minus := sumCost(outs)
+
+		if numeric := (plus - minus) / (2 * epsilon); math.Abs(grads[i]-numeric) > 1e-6 {
+			t.Errorf("Gradient of input %d is %v, estimated %v", i, grads[i], numeric)
+		}
+	}
+}
+
+func TestSegmentedReduction(t *testing.T) {
+	dataset := [][][]float64{
+		{{0.3, -0.6}, {2, 0, 1}},
+		{{-0.1, 0.8}, {-1, 1, 0}},
+	}
+
+	for _, r := range []bs.Reduction{bs.ReduceSum, bs.ReduceMean} {
+		net, _ := twoHeads(t)
+		net.SetReduction(r)
+
+		// the cost of each output is given by its own head
+		outs, err := net.GetOutputs(dataset[0][0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		targets := dataset[0][1]
+		expected := 0.5*(outs[0]-targets[0])*(outs[0]-targets[0]) - math.Log(outs[2])
+		if r == bs.ReduceMean {
+			expected /= 3
+		}
+
+		data, err := bs.Data(dataset[:1], 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cost, _, err := net.Test(data, bs.CorrectHighest)
+		if err != nil {
+			t.Fatal(err)
+		} else if math.Abs(cost-expected) > 1e-12 {
+			t.Errorf("Reduction %d: cost is %v, expected %v", r, cost, expected)
+		}
+
+		args := bs.TrainArgs{
+			Data:         bs.SliceSource(dataset),
+			RunCondition: bs.TrainUntil(10),
+		}
+
+		if err := net.Train(args); err != nil {
+			t.Errorf("Reduction %d: training failed: %v", r, err)
+		}
+	}
+}
+
+func TestSegmentedErrors(t *testing.T) {
+	mse := costfuncs.MSE()
+
+	invalid := map[string][]costfuncs.Segment{
+		"no segments": nil,
+		"nil cost":    {{Start: 0, End: 1}},
+		"not at zero": {{Start: 1, End: 2, Cost: mse}},
+		"empty":       {{Start: 0, End: 1, Cost: mse}, {Start: 1, End: 1, Cost: mse}},
+		"gap":         {{Start: 0, End: 1, Cost: mse}, {Start: 2, End: 3, Cost: mse}},
+		"overlap":     {{Start: 0, End: 2, Cost: mse}, {Start: 1, End: 3, Cost: mse}},
+	}
+
+	for name, segs := range invalid {
+		if _, err := costfuncs.Segmented(segs...); err == nil {
+			t.Errorf("Segmented gave no error for %s", name)
+		}
+	}
+
+	cf, err := costfuncs.Segmented(
+		costfuncs.Segment{Start: 0, End: 1, Cost: mse},
+		costfuncs.Segment{Start: 1, End: 3, Cost: costfuncs.CrossEntropy()},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cf.CheckSize(3); err != nil {
+		t.Errorf("CheckSize(3) gave error: %v", err)
+	}
+
+	err = cf.CheckSize(4)
+	if err == nil || !strings.Contains(err.Error(), "3 outputs, but there are 4") {
+		t.Errorf("CheckSize(4) gave error %v, expected one giving both sizes", err)
+	}
+
+	// the second segment only has one output, which cross-entropy can't use
+	cf, _ = costfuncs.Segmented(
+		costfuncs.Segment{Start: 0, End: 2, Cost: mse},
+		costfuncs.Segment{Start: 2, End: 3, Cost: costfuncs.CrossEntropy()},
+	)
+	if err := cf.CheckSize(3); err == nil {
+		t.Errorf("CheckSize gave no error for a Segment with an invalid size")
+	}
+}
@@ -0,0 +1,576 @@
+package badstudent
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DataSource is a simpler alternative to DataSupplier for datasets that are consumed in full
+// passes. Where DataSupplier is indexed by the current iteration, a DataSource simply produces
+// samples in order until it runs out, at which point it can be Reset. This allows data to be
+// streamed from memory, files, or generators without needing to know its size in advance.
+//
+// DataSources can be used for training or testing either through TrainArgs.Data or by converting
+// them to a DataSupplier with FromSource.
+type DataSource interface {
+	// Next returns the inputs and target outputs of the next sample. Once the source has been
+	// exhausted, ok will be false and the other values should be ignored.
+	Next() (inputs, outputs []float64, ok bool)
+
+	// Reset returns the DataSource to its starting position, so that the next call to Next will
+	// return the first sample again.
+	Reset()
+}
+
+// CheckedSource is an optional interface for DataSources that may encounter errors while
+// producing samples, such as those reading from files. Once Next returns ok = false, Err should be
+// checked to determine whether the source was exhausted or failed.
+type CheckedSource interface {
+	DataSource
+
+	// Err returns the error that caused the most recent call to Next to fail, or nil if the source
+	// simply ran out of samples.
+	Err() error
+}
+
+// LabeledSource is an optional interface for DataSources used for classification, which can give
+// the index of the correct output for each sample instead of a full vector of target outputs. When
+// it is available, NextLabel will be used in place of Next, so that the one-hot vectors of targets
+// need not be constructed (see Datum.Label).
+type LabeledSource interface {
+	DataSource
+
+	// NextLabel is the same as Next, but gives the index of the correct output instead of the
+	// target outputs.
+	NextLabel() (inputs []float64, label int, ok bool)
+}
+
+type sliceSource struct {
+	data  [][][]float64
+	index int
+	err   error
+}
+
+// SliceSource returns a DataSource that provides each sample in the 3D dataset, in order. The
+// indexing of 'dataset' is the same as for Data: [data index][inputs, outputs][values]
+//
+// SliceSource does not check the validity of the dataset; that will be done while it is being
+// used. The returned DataSource is also a CheckedSource: if a sample is missing its inputs or
+// outputs, Next will return ok = false and Err will give type DataMissingError, in the same way
+// as Data.
+func SliceSource(dataset [][][]float64) DataSource {
+	return &sliceSource{data: dataset}
+}
+
+func (s *sliceSource) Next() ([]float64, []float64, bool) {
+	if s.err != nil || s.index >= len(s.data) {
+		return nil, nil, false
+	}
+
+	d := s.data[s.index]
+	if len(d) < 2 {
+		s.err = DataMissingError{s.index, d}
+		return nil, nil, false
+	}
+
+	s.index++
+	return d[0], d[1], true
+}
+
+func (s *sliceSource) Reset() {
+	s.index = 0
+	s.err = nil
+}
+
+func (s *sliceSource) Err() error {
+	return s.err
+}
+
+type labelSource struct {
+	inputs  [][]float64
+	labels  []int
+	classes int
+	index   int
+}
+
+// LabelSource returns a LabeledSource that provides each set of inputs with the corresponding
+// label, in order. 'classes' is the number of outputs, used to construct the one-hot vectors of
+// targets if Next is called. If the lengths of 'inputs' and 'labels' differ, the extra values of
+// the longer one are ignored.
+//
+// Like SliceSource, LabelSource does not check the validity of the dataset.
+func LabelSource(inputs [][]float64, labels []int, classes int) LabeledSource {
+	return &labelSource{inputs: inputs, labels: labels, classes: classes}
+}
+
+func (s *labelSource) NextLabel() ([]float64, int, bool) {
+	if s.index >= len(s.inputs) || s.index >= len(s.labels) {
+		return nil, 0, false
+	}
+
+	s.index++
+	return s.inputs[s.index-1], s.labels[s.index-1], true
+}
+
+func (s *labelSource) Next() ([]float64, []float64, bool) {
+	ins, label, ok := s.NextLabel()
+	if !ok {
+		return nil, nil, false
+	}
+
+	return ins, Datum{Label: label, HasLabel: true}.targets(s.classes), true
+}
+
+func (s *labelSource) Reset() {
+	s.index = 0
+}
+
+type generatorSource struct {
+	fn    func() ([]float64, []float64)
+	count int
+	index int
+}
+
+// GeneratorSource returns a DataSource that calls 'fn' to produce each sample, so that datasets
+// can be generated procedurally instead of being stored. Each pass gives 'count' samples; Reset
+// restarts the count, but does not affect 'fn'. If count is negative, there is no limit, and the
+// source will never run out -- so epochs will never end.
+//
+// 'fn' is given no information about which sample it is producing; any state (e.g. a schedule for
+// curriculum learning) must be kept by 'fn' itself.
+func GeneratorSource(fn func() (inputs, outputs []float64), count int) DataSource {
+	return &generatorSource{fn: fn, count: count}
+}
+
+func (s *generatorSource) Next() ([]float64, []float64, bool) {
+	if s.count >= 0 && s.index >= s.count {
+		return nil, nil, false
+	}
+
+	s.index++
+
+	ins, outs := s.fn()
+	return ins, outs, true
+}
+
+func (s *generatorSource) Reset() {
+	s.index = 0
+}
+
+// BatchedSource is a DataSource that groups its samples into batches, as returned by Batch. The
+// samples can still be retrieved one at a time with Next, so a BatchedSource can be used anywhere
+// that a DataSource can.
+type BatchedSource interface {
+	DataSource
+
+	// NextBatch returns the inputs and target outputs of each sample in the next batch. If some of
+	// the samples in the current batch have already been given by Next, only the remaining ones
+	// are returned. Once the source has been exhausted, ok will be false.
+	NextBatch() (inputs, outputs [][]float64, ok bool)
+}
+
+type batchSource struct {
+	src      DataSource
+	size     int
+	dropLast bool
+
+	// the current batch
+	ins, outs [][]float64
+
+	// the index in the current batch of the next sample to give
+	pos int
+}
+
+// Batch returns a BatchedSource that groups the samples of 'src' into batches of the given size,
+// so that Operators that need whole batches (e.g. for batch normalization) can be given them. The
+// final batch of each pass may be smaller, unless 'dropLast' is true, in which case it is
+// discarded -- even when the samples are retrieved individually with Next. If size < 1, batches
+// of one sample are used.
+//
+// When the returned source is used with FromSource, the same batch size should be given so that
+// the batches line up. If src is a CheckedSource, any errors it gives are available through the
+// Err method of the returned source, which always implements CheckedSource.
+func Batch(src DataSource, size int, dropLast bool) BatchedSource {
+	if size < 1 {
+		size = 1
+	}
+
+	return &batchSource{src: src, size: size, dropLast: dropLast}
+}
+
+// fill retrieves the next batch from the source, returning false if there isn't one
+func (s *batchSource) fill() bool {
+	s.ins, s.outs, s.pos = nil, nil, 0
+	for len(s.ins) < s.size {
+		ins, outs, ok := s.src.Next()
+		if !ok {
+			break
+		}
+
+		s.ins = append(s.ins, ins)
+		s.outs = append(s.outs, outs)
+	}
+
+	if len(s.ins) == 0 || (s.dropLast && len(s.ins) < s.size) {
+		s.ins, s.outs = nil, nil
+		return false
+	}
+
+	return true
+}
+
+func (s *batchSource) NextBatch() ([][]float64, [][]float64, bool) {
+	if s.pos >= len(s.ins) && !s.fill() {
+		return nil, nil, false
+	}
+
+	ins, outs := s.ins[s.pos:], s.outs[s.pos:]
+	s.pos = len(s.ins)
+	return ins, outs, true
+}
+
+func (s *batchSource) Next() ([]float64, []float64, bool) {
+	if s.pos >= len(s.ins) && !s.fill() {
+		return nil, nil, false
+	}
+
+	s.pos++
+	return s.ins[s.pos-1], s.outs[s.pos-1], true
+}
+
+func (s *batchSource) Reset() {
+	s.src.Reset()
+	s.ins, s.outs, s.pos = nil, nil, 0
+}
+
+func (s *batchSource) Err() error {
+	if c, ok := s.src.(CheckedSource); ok {
+		return c.Err()
+	}
+
+	return nil
+}
+
+// sourceSupplier is the DataSupplier returned by FromSource
+type sourceSupplier struct {
+	src       DataSource
+	batchSize int
+
+	// the number of samples that have been retrieved since the end of the last batch
+	inBatch int
+
+	// the next sample to be returned by Get. It is retrieved in advance so that the end of each
+	// pass through the source is known as soon as its last sample is given.
+	next Datum
+
+	// whether or not the most recent call to Get returned the last sample of a pass through the
+	// source
+	ended bool
+}
+
+// FromSource converts a DataSource into a DataSupplier, so that it can be used anywhere a
+// DataSupplier can. Once the source has been exhausted, it will be Reset and continue from the
+// beginning. Batches end every 'batchSize' samples, in addition to at the end of every pass
+// through the source. Testing is done after a single pass.
+//
+// The returned DataSupplier is Epochal, with each epoch being a single pass through the source.
+//
+// FromSource calls src.Reset() before retrieving the first sample.
+//
+// If src is a CheckedSource, any errors it gives will be returned by Get (or FromSource, if
+// the error is with the first sample). If src is a LabeledSource, the samples it gives will have
+// Labels instead of Outputs.
+//
+// FromSource has a few error conditions:
+//	(0) If src is nil, type NilArgError;
+//	(1) If batchSize < 1, ErrSmallBatchSize;
+//	(2) If src fails to give the first sample, the error from src.Err();
+//	(3) If the source gives no samples, ErrNoData;
+func FromSource(src DataSource, batchSize int) (DataSupplier, error) {
+	if src == nil {
+		return nil, NilArgError{"DataSource"}
+	} else if batchSize < 1 {
+		return nil, ErrSmallBatchSize
+	}
+
+	s := &sourceSupplier{src: src, batchSize: batchSize}
+
+	src.Reset()
+	if ok, err := s.fetch(); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrNoData
+	}
+
+	return s, nil
+}
+
+// fetch retrieves the next sample from the source, returning whether or not it was successful, and
+// any error given by the source if it is a CheckedSource.
+func (s *sourceSupplier) fetch() (bool, error) {
+	var ok bool
+	if l, isLabeled := s.src.(LabeledSource); isLabeled {
+		var ins []float64
+		var label int
+		ins, label, ok = l.NextLabel()
+		s.next = Datum{Inputs: ins, Label: label, HasLabel: true}
+	} else {
+		var ins, outs []float64
+		ins, outs, ok = s.src.Next()
+		s.next = Datum{Inputs: ins, Outputs: outs}
+	}
+
+	if c, isChecked := s.src.(CheckedSource); !ok && isChecked {
+		return false, c.Err()
+	}
+
+	return ok, nil
+}
+
+func (s *sourceSupplier) Get(iter int) (Datum, error) {
+	d := s.next
+	s.ended = false
+	s.inBatch++
+
+	if ok, err := s.fetch(); err != nil {
+		return d, err
+	} else if !ok {
+		s.ended = true
+
+		s.src.Reset()
+		if ok, err := s.fetch(); err != nil {
+			return d, err
+		} else if !ok {
+			return d, ErrNoData
+		}
+	}
+
+	return d, nil
+}
+
+func (s *sourceSupplier) BatchEnded(iter int) bool {
+	if s.ended || s.inBatch >= s.batchSize {
+		s.inBatch = 0
+		return true
+	}
+
+	return false
+}
+
+func (s *sourceSupplier) EpochEnded(iter int) bool {
+	return s.ended
+}
+
+// DoneTesting clears the marker of the end of the pass so that the same supplier can be used for
+// testing again.
+func (s *sourceSupplier) DoneTesting(iter int) bool {
+	if s.ended {
+		s.ended = false
+		return true
+	}
+
+	return false
+}
+
+// CSVError documents errors in the contents of a file being loaded by LoadCSV.
+type CSVError struct {
+	Path string
+
+	// Line is the line number in the file where the error occured, starting from 1.
+	Line int
+
+	Err string
+}
+
+func (err CSVError) Error() string {
+	return fmt.Sprintf("%s at %s, line %d", err.Err, err.Path, err.Line)
+}
+
+// LoadCSV reads a dataset from the CSV file at 'path', returning it as a DataSource. The values
+// at the indexes in inputCols are used as the inputs of each sample, and the values at the indexes
+// in outputCols as the outputs. Both are given in the order provided. If skipHeader is true, the
+// first line of the file will be ignored.
+//
+// The entire file is read before LoadCSV returns, so the resulting DataSource can be reused
+// freely.
+//
+// LoadCSV has several error conditions:
+//	(0) If len(inputCols) == 0, type NilArgError;
+//	(1) If the file cannot be opened, type FileError;
+//	(2) If the file has no rows (excluding the header), ErrNoData;
+//	(3) If a row has a different number of values than the first, type CSVError;
+//	(4) If any column index is out of range for the rows, type CSVError;
+//	(5) If any value that is used cannot be parsed as a float64, type CSVError;
+func LoadCSV(path string, inputCols, outputCols []int, skipHeader bool) (DataSource, error) {
+	if len(inputCols) == 0 {
+		return nil, NilArgError{"inputCols"}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, FileError{path, "Failed to open file"}
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	// we check the number of fields ourselves, for better error messages
+	r.FieldsPerRecord = -1
+
+	var dataset [][][]float64
+	var width int
+
+	cols := [][]int{inputCols, outputCols}
+
+	for line := 1; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, CSVError{path, line, "Failed to read row: " + err.Error()}
+		}
+
+		if line == 1 && skipHeader {
+			continue
+		}
+
+		if len(dataset) == 0 {
+			width = len(row)
+
+			for _, cs := range cols {
+				for _, c := range cs {
+					if c < 0 || c >= width {
+						return nil, CSVError{path, line, fmt.Sprintf("Column index %d out of range for rows with %d values", c, width)}
+					}
+				}
+			}
+		} else if len(row) != width {
+			return nil, CSVError{path, line, fmt.Sprintf("Row has %d values, expected %d", len(row), width)}
+		}
+
+		d := make([][]float64, 2)
+		for i, cs := range cols {
+			d[i] = make([]float64, len(cs))
+
+			for j, c := range cs {
+				v, err := strconv.ParseFloat(strings.TrimSpace(row[c]), 64)
+				if err != nil {
+					return nil, CSVError{path, line, fmt.Sprintf("Value %q in column %d is not a number", row[c], c)}
+				}
+
+				d[i][j] = v
+			}
+		}
+
+		dataset = append(dataset, d)
+	}
+
+	if len(dataset) == 0 {
+		return nil, ErrNoData
+	}
+
+	return SliceSource(dataset), nil
+}
+
+// RecordError documents errors in the contents of the data read by a ReaderSource.
+type RecordError struct {
+	// Line is the line number where the error occured, starting from 1.
+	Line int
+
+	Err string
+}
+
+func (err RecordError) Error() string {
+	return fmt.Sprintf("%s at line %d", err.Err, err.Line)
+}
+
+type readerSource struct {
+	r       io.ReadSeeker
+	scanner *bufio.Scanner
+
+	inputs, outputs int
+
+	// the number of lines that have been read since the last Reset
+	line int
+	err  error
+}
+
+// ReaderSource returns a DataSource that reads samples from 'r' as they are requested, so that
+// datasets that are too large to fit in memory can still be used. Reset seeks back to the start of
+// 'r'.
+//
+// Each line of 'r' is a single sample, given by its values separated by commas or whitespace: the
+// first 'inputs' values are the inputs, followed by 'outputs' values for the outputs. Empty lines
+// are ignored.
+//
+// The returned DataSource is also a CheckedSource. If any line has the wrong number of values
+// (including a truncated final line), contains a value that cannot be parsed as a float64, or if
+// reading or seeking fails, Next will return ok = false and Err will give the error -- type
+// RecordError for problems with the contents, and the original error otherwise.
+func ReaderSource(r io.ReadSeeker, inputs, outputs int) DataSource {
+	return &readerSource{
+		r:       r,
+		scanner: bufio.NewScanner(r),
+		inputs:  inputs,
+		outputs: outputs,
+	}
+}
+
+func (s *readerSource) Next() ([]float64, []float64, bool) {
+	if s.err != nil {
+		return nil, nil, false
+	}
+
+	for s.scanner.Scan() {
+		s.line++
+
+		fields := strings.FieldsFunc(s.scanner.Text(), func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) != s.inputs+s.outputs {
+			s.err = RecordError{s.line, fmt.Sprintf("Record has %d values, expected %d", len(fields), s.inputs+s.outputs)}
+			return nil, nil, false
+		}
+
+		values := make([]float64, len(fields))
+		for i := range fields {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				s.err = RecordError{s.line, fmt.Sprintf("Value %q is not a number", fields[i])}
+				return nil, nil, false
+			}
+
+			values[i] = v
+		}
+
+		return values[:s.inputs:s.inputs], values[s.inputs:], true
+	}
+
+	// Scan returned false, either because we reached the end or because of an error. Err returns
+	// nil for io.EOF.
+	s.err = s.scanner.Err()
+	return nil, nil, false
+}
+
+func (s *readerSource) Reset() {
+	s.line = 0
+	s.err = nil
+
+	if _, err := s.r.Seek(0, io.SeekStart); err != nil {
+		s.err = err
+	}
+
+	s.scanner = bufio.NewScanner(s.r)
+}
+
+func (s *readerSource) Err() error {
+	return s.err
+}
@@ -0,0 +1,371 @@
+package badstudent_test
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+)
+
+// countingSource is a DataSource that records the inputs it has given in each pass
+type countingSource struct {
+	bs.DataSource
+	passes [][]float64
+}
+
+func (s *countingSource) Next() ([]float64, []float64, bool) {
+	ins, outs, ok := s.DataSource.Next()
+	if ok {
+		s.passes[len(s.passes)-1] = append(s.passes[len(s.passes)-1], ins[0])
+	}
+
+	return ins, outs, ok
+}
+
+func (s *countingSource) Reset() {
+	s.DataSource.Reset()
+	s.passes = append(s.passes, nil)
+}
+
+func TestSliceSourceReset(t *testing.T) {
+	src := bs.SliceSource(xorData)
+
+	for pass := 0; pass < 3; pass++ {
+		for i := range xorData {
+			ins, outs, ok := src.Next()
+			if !ok {
+				t.Fatalf("Pass %d: source ended after %d samples, expected %d", pass, i, len(xorData))
+			} else if !sliceEqual(ins, xorData[i][0], 0) || !sliceEqual(outs, xorData[i][1], 0) {
+				t.Errorf("Pass %d: sample %d is %v, %v; expected %v, %v", pass, i, ins, outs, xorData[i][0], xorData[i][1])
+			}
+		}
+
+		if _, _, ok := src.Next(); ok {
+			t.Errorf("Pass %d: source did not end after %d samples", pass, len(xorData))
+		}
+
+		src.Reset()
+	}
+}
+
+func TestSliceSourceMissingData(t *testing.T) {
+	dataset := [][][]float64{xorData[0], {xorData[1][0]}, xorData[2]}
+
+	src := bs.SliceSource(dataset)
+	if _, _, ok := src.Next(); !ok {
+		t.Fatal("First sample could not be read")
+	} else if _, _, ok := src.Next(); ok {
+		t.Fatal("Sample without outputs was read without error")
+	}
+
+	err := src.(bs.CheckedSource).Err()
+	if e, ok := err.(bs.DataMissingError); !ok {
+		t.Errorf("Gave error %v, expected type DataMissingError", err)
+	} else if e.Index != 1 {
+		t.Errorf("Error was at index %d, expected 1", e.Index)
+	}
+
+	// the rest of the dataset must not be silently dropped while training
+	net := xorNet(t, 1)
+	err = net.Train(bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(6)})
+	if e, ok := err.(bs.GetDataError); !ok {
+		t.Errorf("Train gave error %v, expected type GetDataError", err)
+	} else if _, ok := e.Err.(bs.DataMissingError); !ok {
+		t.Errorf("Train gave error %v, expected it to wrap DataMissingError", err)
+	}
+}
+
+func TestTrainDataSourceEpochs(t *testing.T) {
+	const epochs = 3
+
+	dataset := [][][]float64{
+		{{-1, -1}, {0}},
+		{{-0.5, 1}, {1}},
+		{{0.5, -1}, {1}},
+	}
+
+	src := &countingSource{DataSource: bs.SliceSource(dataset)}
+
+	var completed int
+	net := xorNet(t, 1)
+	err := net.Train(bs.TrainArgs{
+		Data:         src,
+		RunCondition: bs.TrainUntil(epochs * len(dataset)),
+		OnEpoch:      func(r bs.EpochResult) { completed = r.Epoch },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if completed != epochs {
+		t.Errorf("Completed %d epochs, expected %d", completed, epochs)
+	}
+
+	// every pass through the source gives every sample, in order. The last sample of each pass is
+	// followed by a Reset, so that the end of the epoch is known.
+	if len(src.passes) < epochs {
+		t.Fatalf("Source was Reset %d times, expected at least %d", len(src.passes), epochs)
+	}
+
+	for p := 0; p < epochs; p++ {
+		if len(src.passes[p]) != len(dataset) {
+			t.Errorf("Pass %d gave %d samples, expected %d", p, len(src.passes[p]), len(dataset))
+			continue
+		}
+
+		for i := range dataset {
+			if src.passes[p][i] != dataset[i][0][0] {
+				t.Errorf("Pass %d, sample %d had input %v, expected %v", p, i, src.passes[p][i], dataset[i][0][0])
+			}
+		}
+	}
+}
+
+// writeFile writes 'contents' to a file in a temporary directory, returning its path
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+const irisCSV = `sepal length,sepal width,petal length,class
+5.1,3.5,1.4,0
+7.0, 3.2 ,4.7,1
+6.3,3.3,6.0,2
+`
+
+func TestLoadCSV(t *testing.T) {
+	path := writeFile(t, "iris.csv", irisCSV)
+
+	src, err := bs.LoadCSV(path, []int{2, 0}, []int{3}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][][]float64{
+		{{1.4, 5.1}, {0}},
+		{{4.7, 7.0}, {1}},
+		{{6.0, 6.3}, {2}},
+	}
+
+	// the source can be used for more than one pass
+	for pass := 0; pass < 2; pass++ {
+		for i := range expected {
+			ins, outs, ok := src.Next()
+			if !ok {
+				t.Fatalf("Pass %d: source ended after %d samples", pass, i)
+			} else if !sliceEqual(ins, expected[i][0], 0) || !sliceEqual(outs, expected[i][1], 0) {
+				t.Errorf("Pass %d, row %d: got %v, %v; expected %v, %v", pass, i, ins, outs, expected[i][0], expected[i][1])
+			}
+		}
+
+		if _, _, ok := src.Next(); ok {
+			t.Errorf("Pass %d: source did not end", pass)
+		}
+
+		src.Reset()
+	}
+}
+
+func TestLoadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name, contents string
+		inputs         []int
+		skipHeader     bool
+		line           int
+	}{
+		{"header not skipped", irisCSV, []int{0}, false, 1},
+		{"ragged row", "1,2,3\n4,5\n", []int{0}, false, 2},
+		{"non-numeric cell", "1,2,3\n4,x,6\n", []int{0, 1}, false, 2},
+		{"column out of range", "1,2,3\n", []int{3}, false, 1},
+	}
+
+	for _, test := range tests {
+		path := writeFile(t, "data.csv", test.contents)
+
+		_, err := bs.LoadCSV(path, test.inputs, []int{2}, test.skipHeader)
+		if e, ok := err.(bs.CSVError); !ok {
+			t.Errorf("%s: gave error %v, expected type CSVError", test.name, err)
+		} else if e.Line != test.line || e.Path != path {
+			t.Errorf("%s: error was at %s, line %d; expected %s, line %d", test.name, e.Path, e.Line, path, test.line)
+		}
+	}
+
+	if _, err := bs.LoadCSV(writeFile(t, "empty.csv", "a,b\n"), []int{0}, []int{1}, true); err != bs.ErrNoData {
+		t.Errorf("File with only a header gave error %v, expected ErrNoData", err)
+	}
+
+	if _, err := bs.LoadCSV(filepath.Join(t.TempDir(), "missing.csv"), []int{0}, []int{1}, false); err == nil {
+		t.Errorf("Missing file gave no error")
+	}
+}
+
+func TestReaderSource(t *testing.T) {
+	const contents = "-1 -1 0\n-1, 1, 1\n\n1 -1 1\n1,1,0\n"
+
+	src := bs.ReaderSource(strings.NewReader(contents), 2, 1)
+
+	for pass := 0; pass < 2; pass++ {
+		for i := range xorData {
+			ins, outs, ok := src.Next()
+			if !ok {
+				t.Fatalf("Pass %d: source ended after %d samples (error: %v)", pass, i, src.(bs.CheckedSource).Err())
+			} else if !sliceEqual(ins, xorData[i][0], 0) || !sliceEqual(outs, xorData[i][1], 0) {
+				t.Errorf("Pass %d, sample %d: got %v, %v; expected %v, %v", pass, i, ins, outs, xorData[i][0], xorData[i][1])
+			}
+		}
+
+		if _, _, ok := src.Next(); ok {
+			t.Errorf("Pass %d: source did not end", pass)
+		} else if err := src.(bs.CheckedSource).Err(); err != nil {
+			t.Errorf("Pass %d: source ended with error %v", pass, err)
+		}
+
+		src.Reset()
+	}
+
+	// the source can be trained on for more than one epoch
+	var epochs int
+	err := xorNet(t, 1).Train(bs.TrainArgs{
+		Data:         src,
+		RunCondition: bs.TrainUntil(2 * len(xorData)),
+		OnEpoch:      func(bs.EpochResult) { epochs++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if epochs != 2 {
+		t.Errorf("Trained for %d epochs, expected 2", epochs)
+	}
+}
+
+func TestReaderSourceErrors(t *testing.T) {
+	tests := []struct {
+		name, contents string
+		line           int
+	}{
+		{"truncated final record", "-1 -1 0\n-1 1", 2},
+		{"non-numeric value", "-1 -1 0\n\n-1 x 1\n", 3},
+	}
+
+	for _, test := range tests {
+		src := bs.ReaderSource(strings.NewReader(test.contents), 2, 1)
+
+		if _, _, ok := src.Next(); !ok {
+			t.Errorf("%s: first record could not be read", test.name)
+			continue
+		} else if _, _, ok := src.Next(); ok {
+			t.Errorf("%s: second record was read without error", test.name)
+			continue
+		}
+
+		err := src.(bs.CheckedSource).Err()
+		if e, ok := err.(bs.RecordError); !ok {
+			t.Errorf("%s: gave error %v, expected type RecordError", test.name, err)
+		} else if e.Line != test.line {
+			t.Errorf("%s: error was at line %d, expected %d", test.name, e.Line, test.line)
+		}
+	}
+}
+
+func TestGeneratorSource(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	xor := func() ([]float64, []float64) {
+		d := xorData[rng.Intn(len(xorData))]
+		return d[0], d[1]
+	}
+
+	// the count restarts with each pass
+	src := bs.GeneratorSource(xor, 3)
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i < 3; i++ {
+			if _, _, ok := src.Next(); !ok {
+				t.Fatalf("Pass %d: source ended after %d samples, expected 3", pass, i)
+			}
+		}
+
+		if _, _, ok := src.Next(); ok {
+			t.Errorf("Pass %d: source did not end after 3 samples", pass)
+		}
+
+		src.Reset()
+	}
+
+	net := xorNet(t, 1)
+	args := bs.TrainArgs{Data: bs.GeneratorSource(xor, 100), RunCondition: bs.TrainUntil(8000)}
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range xorData {
+		if outs, err := net.GetOutputs(d[0]); err != nil {
+			t.Fatal(err)
+		} else if !bs.CorrectRound(outs, d[1]) {
+			t.Errorf("Output for %v is %v after training, expected %v", d[0], outs, d[1])
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	data := make([][][]float64, 10)
+	for i := range data {
+		data[i] = [][]float64{{float64(i)}, {float64(-i)}}
+	}
+
+	for _, dropLast := range []bool{true, false} {
+		src := bs.Batch(bs.SliceSource(data), 3, dropLast)
+
+		sizes := []int{3, 3, 3, 1}
+		if dropLast {
+			sizes = sizes[:3]
+		}
+
+		// the batches are the same in each pass
+		for pass := 0; pass < 2; pass++ {
+			var next int
+			for b, size := range sizes {
+				ins, outs, ok := src.NextBatch()
+				if !ok {
+					t.Fatalf("dropLast = %v, pass %d: source ended after %d batches, expected %d", dropLast, pass, b, len(sizes))
+				} else if len(ins) != size || len(outs) != size {
+					t.Errorf("dropLast = %v, pass %d: batch %d has %d samples, expected %d", dropLast, pass, b, len(ins), size)
+				}
+
+				for i := range ins {
+					if ins[i][0] != float64(next) || outs[i][0] != float64(-next) {
+						t.Errorf("dropLast = %v, pass %d: sample %d of batch %d is %v, %v; expected sample %d", dropLast, pass, i, b, ins[i], outs[i], next)
+					}
+
+					next++
+				}
+			}
+
+			if _, _, ok := src.NextBatch(); ok {
+				t.Errorf("dropLast = %v, pass %d: source did not end after %d batches", dropLast, pass, len(sizes))
+			}
+
+			src.Reset()
+		}
+
+		// the same samples are given one at a time by Next
+		var count, expected int
+		for _, _, ok := src.Next(); ok; _, _, ok = src.Next() {
+			count++
+		}
+
+		for _, size := range sizes {
+			expected += size
+		}
+
+		if count != expected {
+			t.Errorf("dropLast = %v: Next gave %d samples, expected %d", dropLast, count, expected)
+		}
+	}
+}
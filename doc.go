@@ -62,7 +62,11 @@
 // Training is mildly cumbersome, with the type TrainArgs used as a proxy for the type of optional
 // arguments that are available in other languages (such as Python). Training and Testing are all
 // done with the custom type Datum, which contains two slices of float64 for inputs and correct
-// outputs for the Network.
+// outputs for the Network. Datasets are usually given as a DataSupplier, but may also be given as
+// a DataSource -- which simply produces samples until it runs out -- through TrainArgs.Data or by
+// conversion with FromSource. DataSources can be created from slices (SliceSource), CSV files
+// (LoadCSV), functions that generate samples (GeneratorSource), or streamed from any io.ReadSeeker
+// (ReaderSource), and their samples can be grouped into batches with Batch.
 //
 // All training is done with the function Train:
 //
@@ -76,6 +80,9 @@
 // While this seems overly complicated, it is just a subsection of fields found in TrainArgs. More
 // information can be found there.
 //
+// For DataSources, Evaluate gives the same results for a single pass through the data, and can use
+// a different CostFunction than the Network's own.
+//
 // Saving and Loading
 //
 // Writing Networks to files is quite simple. The function signature is:
@@ -25,9 +25,17 @@ var (
 
 	ErrNetFinalized       = Error{"Network has already been finalized"}
 	ErrNetNotFinalized    = Error{"Network has not been finalized"}
+	ErrNetNotEvaluated    = Error{"Network outputs have not been evaluated"}
+	ErrNetHasDelay        = Error{"Operation is not supported for Networks with delay"}
 	ErrNilNet             = Error{"Method called on nil Network"}
+	ErrNegativeWorkers    = Error{"Number of workers is less than 0"}
+	ErrInvalidLRScale     = Error{"Learning rate scale must be > 0"}
+	ErrInvalidLossScale   = Error{"Loss scale must be ≥ 0"}
 	ErrNilInputNode       = Error{"One or more input Node(s) is nil"}
+	ErrZeroSizeInput      = Error{"One or more input Node(s) has a size of zero"}
+	ErrZeroSize           = Error{"Node would have a size of zero"}
 	ErrInvalidOperator    = Error{"Operator is invalid (Does not implement Layer or Elementwise)"}
+	ErrNoOperator         = Error{"Node is an input or placeholder, and has no Operator to replace"}
 	ErrNoInputs           = Error{"Network has no inputs"}
 	ErrNoOutputs          = Error{"No outputs have been given"}
 	ErrIsInput            = Error{"Output Node is an input"}
@@ -43,8 +51,15 @@ var (
 	ErrDelayInput       = Error{"Cannot set delay of an input Node"}
 
 	ErrNoHP          = Error{"No HyperParameter by given name"}
+	ErrNoWeights     = Error{"There are no weights"}
+	ErrNoEMAWeights  = Error{"Moving average of weights has not been tracked"}
 	ErrNoInputValues = Error{"Node is an input; does not have input values."}
 
+	ErrTieDifferentNetwork = Error{"Nodes to tie belong to different Networks"}
+	ErrTieNotAdjustable    = Error{"Node to tie does not have an Adjustable Operator"}
+	ErrTieMismatch         = Error{"Nodes to tie have different Operator types or numbers of weights"}
+	ErrAlreadyTied         = Error{"Node is already tied to another Node"}
+
 	ErrNegativeIter = Error{"Given iteration is less than zero."}
 
 	ErrFailedCommand = Error{"Graphviz dot command failed."}
@@ -52,9 +67,15 @@ var (
 	ErrTrainNotSequential = Error{"Network has delay but training data is not sequential"}
 	ErrTestNotSequential  = Error{"Network has delay but testing data is not sequential"}
 	ErrShouldTestButNil   = Error{"TestData is nil but ShouldTest is not"}
+	ErrNotEpochal         = Error{"Training data is not Epochal"}
+	ErrNotShuffleable     = Error{"Training data is not Shuffleable"}
+	ErrNotReorderable     = Error{"Training data is not Reorderable, so its order cannot be restored"}
+	ErrInvalidOrder       = Error{"Order is not a permutation of the dataset"}
 	ErrNoData             = Error{"Given dataset has no data (len=0)"}
 	ErrSmallBatchSize     = Error{"Given batch size is less than 1"}
 	ErrSmallSetSize       = Error{"Given set size is less than 1"}
+
+	ErrLabelOutOfRange = Error{"Label is out of range for the number of classes"}
 )
 
 // NilArgError documents errors resulting from certain arguments provided to a function being nil.
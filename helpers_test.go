@@ -0,0 +1,94 @@
+package badstudent_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	_ "github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+// xorData is the dataset used by the XOR example in cmd/xor
+var xorData = [][][]float64{
+	{{-1, -1}, {0}},
+	{{-1, 1}, {1}},
+	{{1, -1}, {1}},
+	{{1, 1}, {0}},
+}
+
+// xorNet returns a finalized Network with the same structure as the XOR example in cmd/xor, with
+// random weights from a generator seeded by 'seed'
+func xorNet(tb testing.TB, seed int64) *bs.Network {
+	tb.Helper()
+
+	net := new(bs.Network)
+	l := net.AddInput([]int{2}).SetName("input")
+	l = net.Add(operators.Neurons(3), l).Opt(optimizers.SGD()).SetName("hidden neurons")
+	l = net.Add(operators.Logistic(), l).SetName("hidden logistic")
+	l = net.Add(operators.Neurons(1), l).Opt(optimizers.SGD()).SetName("output neurons")
+	l = net.Add(operators.Logistic(), l).SetName("output logistic")
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.5))
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		tb.Fatalf("Failed to finalize Network: %v", err)
+	}
+
+	randomWeights(net, seed)
+	return net
+}
+
+// randomWeights sets the weights of the Network to values in [-1, 1) from a generator seeded by
+// 'seed'
+func randomWeights(net *bs.Network, seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+
+	ws := net.Weights()
+	for i := range ws {
+		ws[i] = rng.Float64()*2 - 1
+	}
+
+	net.SetWeights(ws)
+}
+
+// xorArgs returns TrainArgs for training on xorData until the given iteration
+func xorArgs(tb testing.TB, until int) bs.TrainArgs {
+	tb.Helper()
+
+	data, err := bs.Data(xorData, 1)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return bs.TrainArgs{
+		TrainData:    data,
+		RunCondition: bs.TrainUntil(until),
+		IsCorrect:    bs.CorrectRound,
+	}
+}
+
+// approxEqual returns whether or not the two values are equal within 'tolerance', relative to the
+// larger of the two (or absolute, if both are small)
+func approxEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// sliceEqual returns whether or not each value in 'a' is approximately equal to the value in 'b' at
+// the same index, as per approxEqual
+func sliceEqual(a, b []float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !approxEqual(a[i], b[i], tolerance) {
+			return false
+		}
+	}
+
+	return true
+}
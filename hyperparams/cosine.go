@@ -0,0 +1,50 @@
+package hyperparams
+
+import (
+	"math"
+)
+
+type cosine struct {
+	Initial, Min float64
+	Period       int
+}
+
+// CosineAnnealing returns a HyperParameter that decays from 'initial' to 'min' along a half cosine
+// over each period of 'periodEpochs' epochs, restarting at 'initial' at the start of every period.
+// The value at any multiple of 'periodEpochs' is exactly 'initial'.
+//
+// The value is given by epoch (see badstudent.EpochHyperParameter), so it changes once each epoch
+// is finished. The training data must be Epochal for epochs to be counted; otherwise, the value
+// will stay at 'initial'.
+//
+// CosineAnnealing will panic if periodEpochs < 1.
+func CosineAnnealing(initial, min float64, periodEpochs int) *cosine {
+	if periodEpochs < 1 {
+		panic("cosine annealing period must be ≥ 1")
+	}
+
+	return &cosine{initial, min, periodEpochs}
+}
+
+func (c *cosine) TypeString() string {
+	return "cosine-annealing"
+}
+
+// Value is given so that the cosine schedule is a HyperParameter. It is the same as EpochValue,
+// treating 'iter' as a number of epochs.
+func (c *cosine) Value(iter int) float64 {
+	return c.EpochValue(iter)
+}
+
+func (c *cosine) EpochValue(epoch int) float64 {
+	progress := float64(epoch%c.Period) / float64(c.Period)
+	return c.Min + 0.5*(c.Initial-c.Min)*(1+math.Cos(math.Pi*progress))
+}
+
+func (c *cosine) Get() interface{} {
+	return *c
+}
+
+func (c *cosine) Blank() interface{} {
+	return c
+}
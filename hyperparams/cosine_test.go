@@ -0,0 +1,107 @@
+package hyperparams_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	_ "github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestCosineAnnealing(t *testing.T) {
+	const initial, min, period = 1, 0.1, 10
+
+	c := hyperparams.CosineAnnealing(initial, min, period)
+
+	for p := 0; p < 3; p++ {
+		start := p * period
+
+		// the value restarts at exactly 'initial' at the boundary of each period
+		if v := c.EpochValue(start); v != initial {
+			t.Errorf("Value at the start of period %d (epoch %d) is %v, expected %v", p, start, v, initial)
+		}
+
+		// halfway through, it is halfway between the two
+		if v := c.EpochValue(start + period/2); math.Abs(v-(initial+min)/2) > 1e-12 {
+			t.Errorf("Value halfway through period %d is %v, expected %v", p, v, (initial+min)/2)
+		}
+
+		for i := start; i < start+period; i++ {
+			expected := min + 0.5*(initial-min)*(1+math.Cos(math.Pi*float64(i-start)/period))
+			if v := c.EpochValue(i); math.Abs(v-expected) > 1e-12 {
+				t.Errorf("Value at epoch %d is %v, expected %v", i, v, expected)
+			}
+
+			if i > start && c.EpochValue(i) >= c.EpochValue(i-1) {
+				t.Errorf("Value at epoch %d (%v) is not less than the one before it (%v)", i, c.EpochValue(i), c.EpochValue(i-1))
+			}
+		}
+	}
+}
+
+func TestCosineAnnealingPeriod(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("CosineAnnealing with a period of 0 did not panic")
+		}
+	}()
+
+	hyperparams.CosineAnnealing(1, 0, 0)
+}
+
+func TestCosineAnnealingTrain(t *testing.T) {
+	const initial, min, period, epochs = 1, 0, 3, 7
+
+	net := new(bs.Network)
+	out := net.Add(operators.Neurons(1), net.AddInput([]int{2})).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.CosineAnnealing(initial, min, period))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	dataset := [][][]float64{
+		{{0, 1}, {1}},
+		{{1, 0}, {0}},
+	}
+
+	// the learning rate is recorded after every iteration, before the next
+	var rates []float64
+	args := bs.TrainArgs{
+		Data:         bs.SliceSource(dataset),
+		RunCondition: bs.TrainUntil(epochs * len(dataset)),
+		SendStatus:   func(int) bool { return true },
+		Update:       func(bs.Result) { rates = append(rates, out.HP("learning-rate")) },
+	}
+
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rates) != epochs*len(dataset) {
+		t.Fatalf("Recorded %d learning rates, expected %d", len(rates), epochs*len(dataset))
+	}
+
+	for i, r := range rates {
+		iter := i + 1
+		epoch := iter / len(dataset)
+
+		expected := min + 0.5*(initial-min)*(1+math.Cos(math.Pi*float64(epoch%period)/period))
+		if math.Abs(r-expected) > 1e-12 {
+			t.Errorf("Learning rate at iteration %d (epoch %d) is %v, expected %v", iter, epoch, r, expected)
+		}
+
+		// the rate decays within each period, and restarts at the boundary
+		if iter%len(dataset) == 0 && i > 0 {
+			prev := rates[i-1]
+			if epoch%period == 0 && r != initial {
+				t.Errorf("Learning rate did not restart at epoch %d: %v", epoch, r)
+			} else if epoch%period != 0 && r >= prev {
+				t.Errorf("Learning rate did not decay at epoch %d: %v, from %v", epoch, r, prev)
+			}
+		}
+	}
+}
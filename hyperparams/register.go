@@ -6,6 +6,7 @@ func init() {
 	list := []interface{}{
 		func() bs.HyperParameter { return Constant(0) },
 		func() bs.HyperParameter { return Step(0) },
+		func() bs.HyperParameter { return CosineAnnealing(0, 0, 1) },
 	}
 
 	if err := bs.RegisterAll(list); err != nil {
@@ -1,5 +1,9 @@
 package initializers
 
+import (
+	bs "github.com/sharnoff/badstudent"
+)
+
 type leCun struct {
 	*varianceScaling
 }
@@ -27,3 +31,17 @@ func Xavier() xavier {
 func Glorot() xavier {
 	return Xavier()
 }
+
+type constant float64
+
+// Constant returns an Initializer that sets every weight to the given value. It is mostly useful
+// for biases (see (*badstudent.Node).BiasInit).
+func Constant(value float64) constant {
+	return constant(value)
+}
+
+func (c constant) Set(n *bs.Node, ws []float64) {
+	for i := range ws {
+		ws[i] = float64(c)
+	}
+}
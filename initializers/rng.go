@@ -1,8 +1,11 @@
 package initializers
 
-import "math/rand"
+import (
+	bs "github.com/sharnoff/badstudent"
+)
 
-// RNG needs no explanation
+// RNG needs no explanation. All of the RNGs provided here use badstudent.Rand(), so they can be
+// made reproducible with badstudent.Seed.
 type RNG interface {
 	Gen() float64
 }
@@ -26,7 +29,7 @@ func (u *uniform) Bounds(lower, upper float64) *uniform {
 
 // Gen is the implementation of RNG for Uniform. It returns a random number.
 func (u *uniform) Gen() float64 {
-	return rand.Float64()*(u.upper-u.lower) + u.lower
+	return bs.Rand().Float64()*(u.upper-u.lower) + u.lower
 }
 
 type normal struct {
@@ -56,7 +59,7 @@ func (n *normal) Mean(mean float64) *normal {
 
 // Gen is the implementation of RNG for Normal. It returns a random number.
 func (n *normal) Gen() float64 {
-	return rand.NormFloat64()*n.σ + n.µ
+	return bs.Rand().NormFloat64()*n.σ + n.µ
 }
 
 type truncNormal struct {
@@ -91,7 +94,7 @@ func (t *truncNormal) Trunc(sds float64) *truncNormal {
 // Gen is the implementation of RNG for TruncNormal. It returns a random number.
 func (t *truncNormal) Gen() float64 {
 	for {
-		v := rand.NormFloat64()
+		v := bs.Rand().NormFloat64()
 		if v < -t.trunc || v > t.trunc {
 			continue
 		}
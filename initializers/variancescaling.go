@@ -56,7 +56,9 @@ func (v *varianceScaling) Set(n *bs.Node, ws []float64) {
 		scale = float64(n.NumInputs()+n.Size()) / 2
 	}
 
-	gen := TruncNormal().SD(math.Sqrt(v.factor / scale))
+	// SD returns the embedded *normal, so it can't be chained without losing the truncation
+	gen := TruncNormal()
+	gen.SD(math.Sqrt(v.factor / scale))
 
 	for i := 0; i < len(ws); i++ {
 		ws[i] = gen.Gen()
@@ -3,8 +3,10 @@ package badstudent
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 )
 
@@ -21,7 +23,9 @@ func (err RegisterNamePresentError) Error() string {
 
 // Register updates internals so that Load() will recognize the given type. This
 // function does not pertain to the average use case; it is only necessary for creating
-// custom types. Register is given a function of the form func() <T>, where <T> can be any
+// custom types. The types provided by the subpackages of badstudent register themselves when
+// their packages are initialized, so those packages must be imported by any program that loads
+// Networks using them. Register is given a function of the form func() <T>, where <T> can be any
 // of:
 //	(a) Operator
 //	(b) Optimizer
@@ -140,10 +144,32 @@ type proxyNetwork struct {
 	NumNodes  int
 	Iter      int
 	CFString  string
+	Reduction Reduction
 	HPStrings map[string]string
 	PenString string
 }
 
+// proxyTrainingState is the additional information stored by SaveTrainingState
+type proxyTrainingState struct {
+	// the iterations before the current call to Train, and within it
+	Iter, RunIter int
+	Epoch         int
+
+	// the state of the shared generator
+	Source uint64
+
+	// the state of the generator used for shuffling, and the order of the training data. Both are
+	// only used if Shuffled is true.
+	Shuffled      bool
+	ShuffleSource uint64
+	Order         []int
+
+	HasSavedChanges bool
+
+	// the saved changes to the weights of each Node, by id
+	DelayedWeights [][]float64
+}
+
 type proxyNode struct {
 	Dims      []int
 	Name      string
@@ -153,6 +179,8 @@ type proxyNode struct {
 	PenString string
 	InputsID  []int
 	Delay     int
+	Frozen    bool
+	LRScale   float64
 }
 
 func nodesToIDs(nodes []*Node) []int {
@@ -172,13 +200,14 @@ func idsToNodes(nodesByID []*Node, ids []int) []*Node {
 }
 
 const (
-	main_file string = "main.net"
-	node_ext  string = ".node"
-	cf_ext    string = "cf"
-	op_ext    string = "op"
-	opt_ext   string = "opt"
-	hp_pref   string = "hp_"
-	pen_ext   string = "pen"
+	main_file  string = "main.net"
+	node_ext   string = ".node"
+	cf_ext     string = "cf"
+	op_ext     string = "op"
+	opt_ext    string = "opt"
+	hp_pref    string = "hp_"
+	pen_ext    string = "pen"
+	state_file string = "training.state"
 )
 
 // FileError stores errors from attempting to access files, either to write to them or to read
@@ -290,6 +319,7 @@ func (net *Network) writeFile(dirPath string) error {
 		NumNodes:  len(net.nodesByID),
 		Iter:      net.iter,
 		CFString:  net.cf.TypeString(),
+		Reduction: net.reduction,
 	}
 
 	if net.pen != nil {
@@ -343,15 +373,19 @@ func (n *Node) writeFile(dirPath string) error {
 			PenString string
 			InputsID  []int
 			Delay     int
+			Frozen    bool
+			LRScale   float64
 		}
 	*/
 
 	var p proxyNode
 	{
 		p = proxyNode{
-			Dims:  n.values.Dims,
-			Name:  n.name,
-			Delay: n.Delay(),
+			Dims:    n.values.Dims,
+			Name:    n.name,
+			Delay:   n.Delay(),
+			Frozen:  n.frozen,
+			LRScale: n.lrScale,
 		}
 
 		if !n.IsInput() {
@@ -441,14 +475,15 @@ func (net *Network) Save(path string, overwrite bool) (bool, error) {
 	return true, nil
 }
 
-// NotRegisteredError documents errors from types that have not been registered.
+// NotRegisteredError documents errors from types that have not been registered. Typ is the kind of
+// type (e.g. "Operator"), and Str is the TypeString that was not recognized.
 type NotRegisteredError struct {
 	Typ string
 	Str string
 }
 
 func (err NotRegisteredError) Error() string {
-	return err.Typ + " type \"" + err.Str + "\" has not been registered"
+	return err.Typ + " type \"" + err.Str + "\" has not been registered (custom types must be registered with Register before loading)"
 }
 
 // ConstructionError documents errors occuring from the re-construction of the Network from a saved
@@ -482,6 +517,7 @@ func Load(path string) (*Network, error) {
 	net := new(Network)
 	pNodes := make([]proxyNode, pNet.NumNodes)
 	net.iter = pNet.Iter
+	net.reduction = pNet.Reduction
 
 	// Load the Nodes
 	for id := 0; id < pNet.NumNodes; id++ {
@@ -525,6 +561,13 @@ func Load(path string) (*Network, error) {
 		if pn.Name != "" {
 			n.SetName(pn.Name)
 		}
+
+		n.SetTrainable(!pn.Frozen)
+
+		// files saved before the scale was stored will not have it
+		if pn.LRScale != 0 {
+			n.SetLearningRateScale(pn.LRScale)
+		}
 	}
 
 	// replace placeholders
@@ -701,6 +744,164 @@ func Load(path string) (*Network, error) {
 	return net, nil
 }
 
+// Clone returns an independent copy of the Network, with the same structure, weights, Optimizers,
+// and HyperParameters. Nothing is shared between the two, so either can be trained or modified
+// without affecting the other.
+//
+// Clone works by saving the Network to a temporary directory and loading it again, so it is subject
+// to the same requirements as Save and Load -- all types used in the Network must be registered.
+// Like Save, Clone does not copy any changes that have been saved from an incomplete batch, nor any
+// values held in delay.
+//
+// Clone will return ErrNetNotFinalized if the Network has not been finalized, type FileError if the
+// temporary directory could not be created, and any errors from Save or Load.
+func (net *Network) Clone() (*Network, error) {
+	if net.stat < finalized {
+		return nil, ErrNetNotFinalized
+	}
+
+	dir, err := ioutil.TempDir("", "badstudent-clone")
+	if err != nil {
+		return nil, FileError{os.TempDir(), "Failed to create temporary directory"}
+	}
+	defer os.RemoveAll(dir)
+
+	path := dir + "/net"
+	if _, err := net.Save(path, false); err != nil {
+		return nil, err
+	}
+
+	clone, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	clone.longIter = net.longIter
+	clone.panicErrors = net.panicErrors
+	clone.workers = net.workers
+
+	return clone, nil
+}
+
+// SaveTrainingState saves the Network in the same way as Save, along with the rest of the state
+// needed to resume training exactly: the number of iterations and epochs (which determine the
+// values of HyperParameters and the position in the training data), any changes that have been
+// saved from an incomplete batch, the state of the random number generator shared by badstudent
+// (see Rand), and -- if the training data was shuffled -- the state of the generator used for
+// shuffling and the current order of the data. Any state kept by Optimizers is already saved with
+// each Node, provided that they are Storable or JSONAble.
+//
+// Recording the state does not change it, so SaveTrainingState can be called at any point between
+// iterations, including from TrainArgs.OnEpoch or Update. Once the state is restored with
+// LoadTrainingState, training with TrainArgs.Resume set will continue identically to training this
+// Network further, provided that both are given the same arguments. The order of the data can
+// only be recorded if it is Reorderable, as Data is.
+//
+// The Network is first saved to a temporary directory alongside 'path', which then replaces 'path'
+// once it is complete, so any previous save at 'path' is kept if saving fails. If 'path' already
+// exists and 'overwrite' is false, nothing is saved and SaveTrainingState returns false.
+//
+// SaveTrainingState returns ErrNetNotFinalized if the Network has not been finalized, type
+// FileError if the temporary directory can't be created or moved to 'path', and any errors from
+// Save.
+func (net *Network) SaveTrainingState(path string, overwrite bool) (bool, error) {
+	if net.stat < finalized {
+		return false, ErrNetNotFinalized
+	}
+
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return false, nil
+	}
+
+	tmp, err := ioutil.TempDir(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return false, FileError{path, "Failed to create temporary directory"}
+	}
+	defer os.RemoveAll(tmp)
+
+	saved := filepath.Join(tmp, "net")
+	if _, err := net.Save(saved, false); err != nil {
+		return false, err
+	}
+
+	state := proxyTrainingState{
+		Iter:            net.longIter,
+		RunIter:         net.iter,
+		Epoch:           net.epoch,
+		Source:          source.getState(),
+		HasSavedChanges: net.hasSavedChanges,
+		DelayedWeights:  make([][]float64, len(net.nodesByID)),
+	}
+
+	if r, ok := net.shuffled.(Reorderable); ok && net.shuffleSrc != nil {
+		state.Shuffled = true
+		state.ShuffleSource = net.shuffleSrc.state
+		state.Order = r.Order()
+	}
+
+	for id, n := range net.nodesByID {
+		state.DelayedWeights[id] = n.delayedWeights
+	}
+
+	if err := saveJSON(state, saved+"/"+state_file, false); err != nil {
+		return false, FieldIOError{"Network", "training state", "save", err}
+	}
+
+	// the previous save is moved out of the way (into the temporary directory, so that it will be
+	// removed), and only restored if the new one can't replace it
+	if _, err := os.Stat(path); err == nil {
+		old := filepath.Join(tmp, "old")
+		if err := os.Rename(path, old); err != nil {
+			return false, FileError{path, "Failed to replace previous save"}
+		}
+
+		if err := os.Rename(saved, path); err != nil {
+			os.Rename(old, path)
+			return false, FileError{path, "Failed to move training state from temporary directory"}
+		}
+	} else if err := os.Rename(saved, path); err != nil {
+		return false, FileError{path, "Failed to move training state from temporary directory"}
+	}
+
+	return true, nil
+}
+
+// LoadTrainingState loads a Network that was saved by SaveTrainingState, restoring the training
+// state that was saved with it, so that training can be continued with TrainArgs.Resume. This sets
+// the state of the random number generator shared by badstudent to what it was when the Network
+// was saved.
+//
+// LoadTrainingState returns any errors from Load, along with type FieldIOError if the training
+// state could not be loaded.
+func LoadTrainingState(path string) (*Network, error) {
+	net, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state proxyTrainingState
+	if err := loadJSON(&state, path+"/"+state_file, false); err != nil {
+		return nil, FieldIOError{"Network", "training state", "load", err}
+	}
+
+	net.longIter, net.iter = state.Iter, state.RunIter
+	net.epoch = state.Epoch
+	net.hasSavedChanges = state.HasSavedChanges
+	for id, ws := range state.DelayedWeights {
+		if id < len(net.nodesByID) && len(ws) != 0 {
+			net.nodesByID[id].delayedWeights = ws
+		}
+	}
+
+	if state.Shuffled {
+		net.shuffleSrc = &splitMix{state.ShuffleSource}
+		net.resumeOrder = state.Order
+	}
+
+	source.setState(state.Source)
+	return net, nil
+}
+
 // Graph generates a graph of the Network, through the DOT Language and graphviz's
 // dot command. N.B: if dot is not installed, this method will fail. Graph creates a
 // pdf file with the name and location given by path.
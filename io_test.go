@@ -0,0 +1,250 @@
+package badstudent_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+// dropoutNet returns a Network that uses the shared generator while training, so that restoring it
+// is necessary for training to continue identically
+func dropoutNet(t *testing.T) *bs.Network {
+	t.Helper()
+
+	net := new(bs.Network)
+	l := net.AddInput([]int{2})
+	l = net.Add(operators.Neurons(4), l).Opt(optimizers.SGD())
+	l = net.Add(operators.Logistic(), l)
+	l = net.Add(operators.Dropout(0.25), l)
+	l = net.Add(operators.Neurons(1), l).Opt(optimizers.SGD())
+	l = net.Add(operators.Logistic(), l)
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.5))
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	randomWeights(net, 1)
+	return net
+}
+
+func TestTrainingStateResume(t *testing.T) {
+	const interrupt, total = 18, 40
+
+	args := func(until int, resume bool) bs.TrainArgs {
+		a := xorArgs(t, until)
+		a.Shuffle, a.Seed, a.Resume = true, 7, resume
+		return a
+	}
+
+	// uninterrupted
+	bs.Seed(3)
+	straight := dropoutNet(t)
+	if err := straight.Train(args(total, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	// interrupted partway through an epoch, then restored
+	bs.Seed(3)
+	net := dropoutNet(t)
+	if err := net.Train(args(interrupt, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "state")
+	if ok, err := net.SaveTrainingState(path, false); !ok || err != nil {
+		t.Fatalf("Failed to save training state: %v, %v", ok, err)
+	}
+
+	// anything else using the shared generator in between must not matter
+	bs.Seed(99)
+	bs.Rand().Int63()
+
+	resumed, err := bs.LoadTrainingState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a new supplier, in its original order, which must be restored
+	if err := resumed.Train(args(total, true)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, got := straight.Weights(), resumed.Weights()
+	for i := range expected {
+		if expected[i] != got[i] {
+			t.Fatalf("Weight %d is %v after resuming, expected %v", i, got[i], expected[i])
+		}
+	}
+}
+
+func TestSaveTrainingStateKeepsGenerator(t *testing.T) {
+	net := xorNet(t, 1)
+
+	bs.Seed(5)
+	expected := []int64{bs.Rand().Int63(), bs.Rand().Int63()}
+
+	bs.Seed(5)
+	first := bs.Rand().Int63()
+	if _, err := net.SaveTrainingState(filepath.Join(t.TempDir(), "state"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := []int64{first, bs.Rand().Int63()}; got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("Random values after saving were %v, expected %v", got, expected)
+	}
+}
+
+// failingOp is an Identity Operator that can't be saved
+type failingOp struct {
+	bs.Elementwise
+}
+
+var errFailingSave = errors.New("failingOp can't be saved")
+
+func (failingOp) Save(dirPath string) error { return errFailingSave }
+func (failingOp) Load(dirPath string) error { return nil }
+
+func TestSaveTrainingStateFailureKeepsPrevious(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+
+	net := xorNet(t, 1)
+	if ok, err := net.SaveTrainingState(path, false); !ok || err != nil {
+		t.Fatalf("Failed to save training state: %v, %v", ok, err)
+	}
+
+	if ok, err := net.SaveTrainingState(path, false); ok || err != nil {
+		t.Errorf("Saving without overwriting gave %v, %v; expected false, nil", ok, err)
+	}
+
+	bad := new(bs.Network)
+	l := bad.Add(failingOp{operators.Identity()}, bad.AddInput([]int{1}))
+	if err := bad.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := bad.SaveTrainingState(path, true); ok || err == nil {
+		t.Fatalf("Saving a Network that can't be saved gave %v, %v", ok, err)
+	}
+
+	loaded, err := bs.LoadTrainingState(path)
+	if err != nil {
+		t.Fatalf("Previous save was not kept: %v", err)
+	}
+
+	if !sliceEqual(loaded.Weights(), net.Weights(), 0) {
+		t.Errorf("Loaded weights %v, expected %v", loaded.Weights(), net.Weights())
+	}
+}
+
+func TestClone(t *testing.T) {
+	net := xorNet(t, 1)
+
+	clone, err := net.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !sliceEqual(clone.Weights(), net.Weights(), 0) {
+		t.Fatalf("Clone has different weights")
+	}
+
+	original := make([][]float64, len(xorData))
+	for i, d := range xorData {
+		if original[i], err = net.GetOutputs(d[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := clone.Train(xorArgs(t, 100*len(xorData))); err != nil {
+		t.Fatal(err)
+	} else if sliceEqual(clone.Weights(), net.Weights(), 0) {
+		t.Fatalf("Training the clone did not change its weights")
+	}
+
+	for i, d := range xorData {
+		outs, err := net.GetOutputs(d[0])
+		if err != nil {
+			t.Fatal(err)
+		} else if !sliceEqual(outs, original[i], 0) {
+			t.Errorf("Training the clone changed the outputs of the original for %v: %v -> %v", d[0], original[i], outs)
+		}
+	}
+}
+
+func TestOperatorTypeSaved(t *testing.T) {
+	net := xorNet(t, 1)
+
+	path := filepath.Join(t.TempDir(), "xor")
+	if _, err := net.Save(path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := bs.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, loadedNodes := net.Nodes(), loaded.Nodes()
+	if len(nodes) != len(loadedNodes) {
+		t.Fatalf("Loaded Network has %d Nodes, expected %d", len(loadedNodes), len(nodes))
+	}
+
+	// the input Node has no Operator
+	expected := []string{"", "neurons", "logistic", "neurons", "logistic"}
+	for i, n := range loadedNodes {
+		if typ := n.OperatorType(); typ != expected[i] || typ != nodes[i].OperatorType() {
+			t.Errorf("Node %v has type %q after loading, expected %q", n, typ, expected[i])
+		}
+	}
+}
+
+// double is a custom Operator, which must be registered before it can be loaded
+type double int8
+
+func (double) TypeString() string              { return "test-double" }
+func (double) Finalize(n *bs.Node) error       { return nil }
+func (double) Value(in float64, i int) float64 { return 2 * in }
+func (double) Deriv(n *bs.Node, i int) float64 { return 2 }
+
+func TestRegisterCustomOperator(t *testing.T) {
+	net := new(bs.Network)
+	out := net.Add(double(0), net.AddInput([]int{2}))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "double")
+	if _, err := net.Save(path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := bs.Load(path)
+	if e, ok := err.(bs.NotRegisteredError); !ok {
+		t.Fatalf("Loading an unregistered Operator gave %v, expected type NotRegisteredError", err)
+	} else if e.Str != "test-double" {
+		t.Errorf("Error was for type %q, expected %q", e.Str, "test-double")
+	}
+
+	err = bs.Register(func() bs.Operator { return double(0) })
+	if _, ok := err.(bs.RegisterNamePresentError); err != nil && !ok {
+		t.Fatal(err)
+	}
+
+	loaded, err := bs.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if outs, err := loaded.GetOutputs([]float64{1.5, -2}); err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(outs, []float64{3, -4}, 0) {
+		t.Errorf("Loaded Network gave outputs %v, expected [3 -4]", outs)
+	}
+}
@@ -3,6 +3,7 @@ package badstudent
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"sort"
 )
 
@@ -55,10 +56,30 @@ func PrintResult() (func(Result), func()) {
 // A value is correct if it is on the same side of 0.5 as the target -- values less than 0.5 round
 // to 0, and values greater than or equal to 0.5 round to 1 This requires 'targets' to consist of
 // either '0' or '1'
+//
+// CorrectRound is equivalent to CorrectThreshold(0.5).
 func CorrectRound(outs, targets []float64) bool {
+	return correctThreshold(0.5, outs, targets)
+}
+
+// CorrectThreshold returns an 'IsCorrect' function to provide to TrainArgs, for binary outputs
+// with an operating point other than 0.5. Values less than 'threshold' are counted as 0, and values
+// greater than or equal to it are counted as 1 -- values exactly at the threshold are positive.
+// Like CorrectRound, this requires 'targets' to consist of either '0' or '1'
+func CorrectThreshold(threshold float64) func([]float64, []float64) bool {
+	return func(outs, targets []float64) bool {
+		return correctThreshold(threshold, outs, targets)
+	}
+}
+
+func correctThreshold(threshold float64, outs, targets []float64) bool {
 	for i := range outs {
-		// rounds to 0 if a number is < 0.5, 1 if ≥ 0.5. Tanh reduces the value to (0, 1)
-		if math.Round(0.5*(1+math.Tanh(outs[i]-0.5))) != targets[i] {
+		var rounded float64
+		if outs[i] >= threshold {
+			rounded = 1
+		}
+
+		if rounded != targets[i] {
 			return false
 		}
 	}
@@ -71,6 +92,20 @@ func CorrectHighest(outs, targets []float64) bool {
 	return HighestIndex(outs) == HighestIndex(targets)
 }
 
+// CorrectWithin returns an 'IsCorrect' function to provide to TrainArgs, for Networks with
+// continuous outputs. The outputs are correct if every value is within 'epsilon' of its target.
+func CorrectWithin(epsilon float64) func([]float64, []float64) bool {
+	return func(outs, targets []float64) bool {
+		for i := range outs {
+			if math.Abs(outs[i]-targets[i]) > epsilon {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
 // for use in HighestIndexes
 type sortable struct {
 	values  []float64
@@ -89,20 +124,26 @@ func (s sortable) Swap(i, j int) {
 	return
 }
 
-// returns the indexes of the highest values in the given slice, from greatest to least
+// returns the indexes of the highest values in the given slice, from greatest to least. The given
+// slice is not modified.
 func HighestIndexes(sl []float64) []int {
 	indexes := make([]int, len(sl))
 	for i := range indexes {
 		indexes[i] = i
 	}
 
-	s := sortable{sl, indexes}
-	sort.Sort(s)
+	values := make([]float64, len(sl))
+	copy(values, sl)
+
+	s := sortable{values, indexes}
+	sort.Stable(s)
 
 	return s.indexes
 }
 
-// returns the index of the highest value in an unsorted slice
+// returns the index of the highest value in an unsorted slice (i.e. the argmax). If there are
+// multiple equal highest values, the lowest index is returned. If the slice is empty, HighestIndex
+// returns -1.
 func HighestIndex(sl []float64) int {
 	highVal := math.Inf(-1)
 	index := -1
@@ -116,6 +157,43 @@ func HighestIndex(sl []float64) int {
 	return index
 }
 
+// OneHot returns the one-hot encoding of a classification label: a slice of length 'classes' with
+// every value 0, except for the value at index 'label', which is 1. This is the inverse of
+// HighestIndex, and is suitable as the target outputs for CorrectHighest.
+//
+// If label is not within [0, classes), OneHot returns ErrLabelOutOfRange.
+func OneHot(label, classes int) ([]float64, error) {
+	if label < 0 || label >= classes {
+		return nil, ErrLabelOutOfRange
+	}
+
+	vs := make([]float64, classes)
+	vs[label] = 1
+	return vs, nil
+}
+
+// Split randomly divides a 3D dataset (with the same indexing as for Data) into two: 'train', with
+// the given fraction of the samples, and 'val', with the rest. Each sample is in exactly one of the
+// two. The order is shuffled with a random number generator seeded by 'seed', so the same split
+// can be made again. The dataset itself is not modified, though the samples are not copied.
+//
+// The number of samples in 'train' is rounded to the nearest integer; a fraction of 0 gives an
+// empty 'train', and 1 gives an empty 'val'. Fractions outside [0, 1] are treated as 0 or 1.
+func Split(data [][][]float64, fraction float64, seed int64) (train, val [][][]float64) {
+	fraction = math.Max(0, math.Min(1, fraction))
+
+	shuffled := make([][][]float64, len(data))
+	copy(shuffled, data)
+
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	size := int(math.Round(fraction * float64(len(data))))
+	return shuffled[:size:size], shuffled[size:]
+}
+
 // Acts as a TrainArgs.RunCondition
 // Tells the network to run for a specified number of individual data corrected
 func TrainUntil(maxIterations int) func(int) bool {
@@ -0,0 +1,189 @@
+package badstudent_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+)
+
+func TestCorrectPredicates(t *testing.T) {
+	tests := []struct {
+		name          string
+		correct       func([]float64, []float64) bool
+		outs, targets []float64
+		expected      bool
+	}{
+		{"highest", bs.CorrectHighest, []float64{0.1, 0.7, 0.2}, []float64{0, 1, 0}, true},
+		{"highest", bs.CorrectHighest, []float64{0.4, 0.3, 0.3}, []float64{0, 1, 0}, false},
+		{"within 0.1", bs.CorrectWithin(0.1), []float64{1.05, -2.02}, []float64{1, -2}, true},
+		{"within 0.1", bs.CorrectWithin(0.1), []float64{1.05, -2.2}, []float64{1, -2}, false},
+		{"threshold 0.8", bs.CorrectThreshold(0.8), []float64{0.8, 0.7}, []float64{1, 0}, true},
+		{"round", bs.CorrectRound, []float64{0.8, 0.7}, []float64{1, 0}, false},
+	}
+
+	for _, test := range tests {
+		if c := test.correct(test.outs, test.targets); c != test.expected {
+			t.Errorf("%s: %v with targets %v gave %v, expected %v", test.name, test.outs, test.targets, c, test.expected)
+		}
+	}
+}
+
+func TestEvaluateCorrect(t *testing.T) {
+	net := xorNet(t, 1)
+
+	// only the targets are checked, so that the result doesn't depend on the outputs
+	positive := func(outs, targets []float64) bool { return targets[0] == 1 }
+
+	if _, correct, err := net.Evaluate(bs.SliceSource(xorData), nil, positive); err != nil {
+		t.Fatal(err)
+	} else if correct != 0.5 {
+		t.Errorf("Custom predicate gave accuracy %v, expected 0.5", correct)
+	}
+
+	if _, correct, err := net.Evaluate(bs.SliceSource(xorData), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if correct != 0 {
+		t.Errorf("Without a predicate, accuracy was %v, expected 0", correct)
+	}
+}
+
+func TestHighestIndex(t *testing.T) {
+	tests := []struct {
+		values []float64
+		index  int
+	}{
+		{[]float64{0.1, 0.7, 0.2}, 1},
+		// ties go to the lowest index
+		{[]float64{0.3, 0.7, 0.7, 0.1}, 1},
+		{[]float64{-2, -2}, 0},
+		{[]float64{-5}, 0},
+		{nil, -1},
+	}
+
+	for _, test := range tests {
+		if i := bs.HighestIndex(test.values); i != test.index {
+			t.Errorf("HighestIndex(%v) = %d, expected %d", test.values, i, test.index)
+		}
+	}
+
+	// the order of equal values is kept
+	if is, expected := bs.HighestIndexes([]float64{1, 3, 1, 3}), []int{1, 3, 0, 2}; len(is) != len(expected) {
+		t.Errorf("HighestIndexes gave %v, expected %v", is, expected)
+	} else {
+		for i := range is {
+			if is[i] != expected[i] {
+				t.Errorf("HighestIndexes gave %v, expected %v", is, expected)
+				break
+			}
+		}
+	}
+}
+
+func TestOneHot(t *testing.T) {
+	vs, err := bs.OneHot(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	} else if expected := []float64{0, 0, 1, 0}; !sliceEqual(vs, expected, 0) {
+		t.Errorf("OneHot(2, 4) = %v, expected %v", vs, expected)
+	} else if i := bs.HighestIndex(vs); i != 2 {
+		t.Errorf("HighestIndex of OneHot(2, 4) is %d, expected 2", i)
+	}
+
+	if vs, err := bs.OneHot(0, 1); err != nil || !sliceEqual(vs, []float64{1}, 0) {
+		t.Errorf("OneHot(0, 1) = %v, %v; expected [1]", vs, err)
+	}
+
+	for _, label := range []int{-1, 4, 10} {
+		if _, err := bs.OneHot(label, 4); err != bs.ErrLabelOutOfRange {
+			t.Errorf("OneHot(%d, 4) gave error %v, expected ErrLabelOutOfRange", label, err)
+		}
+	}
+}
+
+func TestCorrectThreshold(t *testing.T) {
+	outs := []float64{0.1, 0.3, 0.5, 0.7, 0.9}
+	targets := []float64{0, 0, 1, 1, 1}
+
+	tests := []struct {
+		threshold float64
+		correct   int
+	}{
+		{0, 3},
+		{0.2, 4},
+		// values exactly at the threshold are positive
+		{0.3, 4},
+		{0.5, 5},
+		{0.6, 4},
+		{0.8, 3},
+		{1, 2},
+	}
+
+	for _, test := range tests {
+		correct := bs.CorrectThreshold(test.threshold)
+
+		var n int
+		for i := range outs {
+			if correct(outs[i:i+1], targets[i:i+1]) {
+				n++
+			}
+		}
+
+		if n != test.correct {
+			t.Errorf("Threshold %v counted %d outputs as correct, expected %d", test.threshold, n, test.correct)
+		}
+	}
+
+	// CorrectRound uses a threshold of 0.5
+	for i := range outs {
+		if !bs.CorrectRound(outs[i:i+1], targets[i:i+1]) {
+			t.Errorf("CorrectRound counted %v as incorrect for target %v", outs[i], targets[i])
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	// the input of each sample is its index, so that they can be identified
+	data := make([][][]float64, 10)
+	for i := range data {
+		data[i] = [][]float64{{float64(i)}, {0}}
+	}
+
+	tests := []struct {
+		fraction           float64
+		trainSize, valSize int
+	}{
+		{0, 0, 10},
+		{0.25, 3, 7},
+		{0.7, 7, 3},
+		{1, 10, 0},
+	}
+
+	for _, test := range tests {
+		train, val := bs.Split(data, test.fraction, 3)
+		if len(train) != test.trainSize || len(val) != test.valSize {
+			t.Errorf("Fraction %v gave %d and %d samples, expected %d and %d", test.fraction, len(train), len(val), test.trainSize, test.valSize)
+		}
+
+		// every sample is in exactly one of the two
+		seen := make([]int, len(data))
+		for _, d := range append(append([][][]float64{}, train...), val...) {
+			seen[int(d[0][0])]++
+		}
+
+		for i, n := range seen {
+			if n != 1 {
+				t.Errorf("Fraction %v: sample %d appears %d times, expected once", test.fraction, i, n)
+			}
+		}
+	}
+
+	// the same seed gives the same split
+	a, _ := bs.Split(data, 0.5, 3)
+	b, _ := bs.Split(data, 0.5, 3)
+	for i := range a {
+		if a[i][0][0] != b[i][0][0] {
+			t.Errorf("Splits with the same seed differ: %v and %v", a, b)
+			break
+		}
+	}
+}
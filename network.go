@@ -1,5 +1,13 @@
 package badstudent
 
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+)
+
 // setError sets the Network's stored error to the error provided. If net.panicErrors is true,
 // setError will additionally panic the error it is given.
 func (net *Network) setError(e error) {
@@ -18,6 +26,84 @@ func (net *Network) Nodes() []*Node {
 	return ns
 }
 
+// SortedNodes returns the list of all Nodes in the Network in topological order, so that every
+// Node comes after all of the Nodes it takes input from. Ties are broken by ID, so input Nodes
+// will typically come first. Inputs from Nodes with delay are not considered, because they give
+// values from previous time-steps. SortedNodes returns nil if the Network has not been finalized.
+//
+// Like Nodes, the returned slice is a copy and can be modified freely. The order is calculated
+// once and then stored.
+func (net *Network) SortedNodes() []*Node {
+	if net.stat < finalized {
+		return nil
+	}
+
+	sorted := net.sorted()
+	ns := make([]*Node, len(sorted))
+	copy(ns, sorted)
+	return ns
+}
+
+// sorted returns the stored topological order of the Nodes, calculating it if it hasn't been
+// already. Assumes net.stat >= finalized.
+func (net *Network) sorted() []*Node {
+	if net.sortedNodes == nil {
+		net.sortedNodes = net.postOrder(net.nodesByID)
+	}
+
+	return net.sortedNodes
+}
+
+// postOrder returns every Node reachable from 'roots' by following inputs without delay, ordered
+// so that each Node comes after all of the Nodes it takes input from. Ties are broken by the order
+// of 'roots' and of each Node's inputs. An explicit stack is used instead of recursion, so that
+// very deep Networks can't overflow it.
+func (net *Network) postOrder(roots []*Node) []*Node {
+	type frame struct {
+		n    *Node
+		next int // the index of the next input to visit
+	}
+
+	visited := make([]bool, len(net.nodesByID))
+	var order []*Node
+	var stack []frame
+
+	for _, r := range roots {
+		if visited[r.id] {
+			continue
+		}
+
+		visited[r.id] = true
+		stack = append(stack, frame{r, 0})
+
+		for len(stack) != 0 {
+			f := &stack[len(stack)-1]
+
+			var ins []*Node
+			if !f.n.IsInput() {
+				ins = f.n.inputs.nodes
+			}
+
+			if f.next < len(ins) {
+				in := ins[f.next]
+				f.next++
+
+				if !in.HasDelay() && !visited[in.id] {
+					visited[in.id] = true
+					stack = append(stack, frame{in, 0})
+				}
+
+				continue
+			}
+
+			order = append(order, f.n)
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return order
+}
+
 // ResetIter resets the Network's tracked number of iterations to the provided value. This could be
 // done to bring HyperParameters that are dependent upon iterations back to an earlier state. The
 // given value will usually be zero. ResetIter will return ErrNegativeIter if the iteration given
@@ -31,8 +117,9 @@ func (net *Network) ResetIter(iter int) error {
 	return nil
 }
 
-// InputSize returns the total number of expected input values to the Network. If the Network has
-// not been finalized yet, InputSize will return -1.
+// InputSize returns the total number of expected input values to the Network -- the sum of the
+// sizes of each input Node. This is the length required for the inputs to SetInputs, GetOutputs,
+// and Datum.Inputs. If the Network has not been finalized yet, InputSize will return -1.
 func (net *Network) InputSize() int {
 	if net.stat < finalized {
 		return -1
@@ -41,8 +128,9 @@ func (net *Network) InputSize() int {
 	return net.inputs.size()
 }
 
-// OutputSize returns the total number of expected output values to the Network. If the Network has
-// not been finalized yet, OutputSize will return -1.
+// OutputSize returns the total number of expected output values to the Network -- the sum of the
+// sizes of each output Node. This is the length of the values returned by GetOutputs, and required
+// for Datum.Outputs. If the Network has not been finalized yet, OutputSize will return -1.
 func (net *Network) OutputSize() int {
 	if net.stat < finalized {
 		return -1
@@ -65,6 +153,13 @@ func (net *Network) CurrentInputs() []float64 {
 // finalized, ErrNetNotFinalized will be returned (or panicked if PanicErrors() has been called).
 // Else, if the number of inputs does not equal the total size of the inputs (given by
 // InputSize()), type SizeMismatchError will be returned.
+//
+// If the Network is not recurrent and the given inputs are identical to the current ones, the
+// values that have already been calculated will be kept. This is not done while training, because
+// each sample must be evaluated separately -- e.g. so that Dropout chooses a new mask for it.
+//
+// Inputs may be NaN to indicate that they are missing. SetInputs does not treat them differently,
+// so they should be replaced before they are used, e.g. by operators.Impute.
 func (net *Network) SetInputs(inputs []float64) error {
 	if net.stat < finalized {
 		if net.panicErrors {
@@ -74,6 +169,12 @@ func (net *Network) SetInputs(inputs []float64) error {
 		return ErrNetNotFinalized
 	}
 
+	// For recurrent Networks, each new set of inputs is a separate time-step, so they must always
+	// be evaluated again. While training, the same is true of each sample.
+	if !net.hasDelay && !net.training && net.stat >= evaluated && net.inputs.isContinuous() && equal(net.inputs.values, inputs) {
+		return nil
+	}
+
 	err := net.inputs.setValues(inputs)
 	if err != nil {
 		err = SizeMismatchError{net.inputs.size(), len(inputs), "inputs"}
@@ -90,8 +191,9 @@ func (net *Network) SetInputs(inputs []float64) error {
 }
 
 // GetOutputs returns a copy of the Network's output values for the given inputs. SetInputs() will
-// be called regardless of whether or not the given inputs are actually the current inputs. There
-// are several error conditions:
+// be called regardless of whether or not the given inputs are actually the current inputs, though
+// the outputs will not be recalculated if they are unchanged (see SetInputs). There are several
+// error conditions:
 //	(0) If the Network has not been finalized: ErrNetNotFinalized,
 //	(1) If the number of inputs doesn't match the total size: type SizeMismatchError,
 // If PanicErrors() has been called, error conditions will be panicked, not returned.
@@ -108,6 +210,684 @@ func (net *Network) GetOutputs(inputs []float64) ([]float64, error) {
 	return net.outputs.getValues(true), nil
 }
 
+// Infer returns the Network's outputs for the given inputs, like GetOutputs, but without changing
+// the values stored in the Network. Each call evaluates every Node into its own scratch values, so
+// Infer can be called from many goroutines at once on the same Network, and calls are evaluated in
+// parallel. Because nothing is stored, results are never cached between calls.
+//
+// While evaluating for Infer, Nodes report IsInferring() = true, so that Operators don't keep any
+// state for backpropagation. Operators that change their own state while evaluating regardless
+// (e.g. custom Stateful Operators) are not safe to use with Infer.
+//
+// Infer must not be called at the same time as any method that changes the Network or its
+// weights, such as Train or SetWeights. It has the same error conditions as GetOutputs, and will
+// additionally give ErrNetHasDelay if the Network has delay, because concurrent calls would be
+// treated as successive time-steps. If PanicErrors() has been called, errors will be panicked, not
+// returned.
+func (net *Network) Infer(inputs []float64) ([]float64, error) {
+	var err error
+	if net.stat < finalized {
+		err = ErrNetNotFinalized
+	} else if net.hasDelay {
+		err = ErrNetHasDelay
+	} else if len(inputs) != net.InputSize() {
+		err = SizeMismatchError{net.InputSize(), len(inputs), "inputs"}
+	}
+
+	if err != nil {
+		if net.panicErrors {
+			panic(err)
+		}
+
+		return nil, err
+	}
+
+	// the order is shared with evaluate, and may not have been calculated yet
+	net.syncMux.Lock()
+	if net.evalOrder == nil {
+		net.evalOrder = net.postOrder(net.outputs.nodes)
+	}
+	order := net.evalOrder
+	net.syncMux.Unlock()
+
+	// each Node is replaced by a copy with its own values, so that the Network isn't changed. The
+	// copies take input from each other, indexed by id.
+	scratch := make([]*Node, len(net.nodesByID))
+	copyNode := func(n *Node) *Node {
+		s := *n
+		s.inferring = true
+		s.group = nil
+		s.values.Values = make([]float64, n.Size())
+
+		scratch[n.id] = &s
+		return &s
+	}
+
+	for i, in := range net.inputs.nodes {
+		copy(copyNode(in).values.Values, inputs[net.inputs.sumVals[i]-in.Size():])
+	}
+
+	for _, n := range order {
+		if n.IsInput() {
+			continue
+		}
+
+		s := copyNode(n)
+		s.inputs = &nodeGroup{nodes: make([]*Node, num(n.inputs)), sumVals: n.inputs.sumVals}
+		for i, in := range n.inputs.nodes {
+			s.inputs.nodes[i] = scratch[in.id]
+		}
+
+		s.calculateValues()
+	}
+
+	outs := make([]float64, 0, net.OutputSize())
+	for _, out := range net.outputs.nodes {
+		outs = append(outs, scratch[out.id].values.Values...)
+	}
+
+	return outs, nil
+}
+
+// GetOutputsContext is the same as GetOutputs, but stops evaluating once the Context is cancelled
+// or its deadline is exceeded, so that inference can be bounded in time. The Context is checked
+// before each Node is evaluated; if it is done, GetOutputsContext returns ctx.Err() and the
+// partially-calculated values are discarded. For recurrent Networks, the Context is only checked
+// before evaluation begins, because the time-step cannot be stopped partway through.
+//
+// GetOutputsContext has the same error conditions as GetOutputs. Errors from the Context are never
+// panicked, even if PanicErrors() has been called.
+func (net *Network) GetOutputsContext(ctx context.Context, inputs []float64) ([]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := net.SetInputs(inputs); err != nil {
+		return nil, err
+	}
+
+	if !net.hasDelay && net.stat < evaluated {
+		// evaluating in order means that each Node only needs the values of those before it
+		for _, n := range net.SortedNodes() {
+			if err := ctx.Err(); err != nil {
+				net.resetCompletion()
+				return nil, err
+			}
+
+			n.evaluate()
+		}
+
+		net.resetCompletion()
+		net.stat = evaluated
+	}
+
+	// evaluate should only return ErrNetNotFinalized, but we check anyways for future-proofing.
+	if err := net.evaluate(); err != nil {
+		return nil, err
+	}
+
+	return net.outputs.getValues(true), nil
+}
+
+// GetOutputsBatch returns the Network's output values for each set of inputs, as GetOutputs would.
+// All of the inputs are checked before any are evaluated, and the outputs share a single
+// allocation. Most of the time is spent evaluating the Network, however, so GetOutputsBatch is
+// not much faster than calling GetOutputs repeatedly. Because the values of each Node are stored
+// within the Network, the inputs cannot be evaluated in parallel.
+//
+// For recurrent Networks, each set of inputs is treated as a successive time-step; StepSequence
+// should usually be used instead.
+//
+// GetOutputsBatch has the same error conditions as GetOutputs, where the Description of any
+// SizeMismatchError indicates the index of the offending inputs.
+func (net *Network) GetOutputsBatch(inputs [][]float64) ([][]float64, error) {
+	var err error
+	if net.stat < finalized {
+		err = ErrNetNotFinalized
+	} else {
+		for i := range inputs {
+			if len(inputs[i]) != net.InputSize() {
+				err = SizeMismatchError{net.InputSize(), len(inputs[i]), fmt.Sprintf("inputs at index %d", i)}
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		if net.panicErrors {
+			panic(err)
+		}
+
+		return nil, err
+	}
+
+	size := net.OutputSize()
+	values := make([]float64, len(inputs)*size)
+	outs := make([][]float64, len(inputs))
+
+	for i := range inputs {
+		// we've already checked for all of the errors that SetInputs and evaluate could return
+		net.SetInputs(inputs[i])
+		net.evaluate()
+
+		outs[i] = values[i*size : (i+1)*size : (i+1)*size]
+		copy(outs[i], net.outputs.getValues(false))
+	}
+
+	return outs, nil
+}
+
+// InputGradients returns the derivatives of the cost w.r.t. each of the Network's inputs, given
+// the inputs and their target outputs. This can be used to determine which inputs are most
+// significant, or to construct adversarial examples. If 'cost' is nil, the Network's CostFunction
+// is used. Either way, the Network's Reduction is applied to it.
+//
+// Because deltas are usually only calculated where they're needed to train, every Node is made to
+// calculate them temporarily. The weights of the Network are not changed.
+//
+// InputGradients has a few error conditions:
+//	(0) If the Network has not been finalized, ErrNetNotFinalized;
+//	(1) If the Network has delay, ErrNetHasDelay;
+//	(2) If len(inputs) != net.InputSize(), type SizeMismatchError;
+//	(3) If len(targets) != net.OutputSize(), type SizeMismatchError;
+// If PanicErrors() has been called, these will be panicked, not returned.
+func (net *Network) InputGradients(inputs, targets []float64, cost CostFunction) ([]float64, error) {
+	grads := make([]float64, 0, net.InputSize())
+	err := net.withAllDeltas(inputs, targets, net.defaultCost(cost), func() {
+		for _, in := range net.inputs.nodes {
+			grads = append(grads, in.deltas...)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return grads, nil
+}
+
+// WeightGradients returns the derivatives of the cost w.r.t. each of the weights in the Network,
+// in the same order as given by Weights, for the given inputs and their target outputs. The
+// gradients are those given by the Operators themselves; penalties are not included. If 'cost' is
+// nil, the Network's CostFunction is used. Either way, the Network's Reduction is applied to it.
+//
+// WeightGradients is mainly useful for checking the gradients of Operators against numerical
+// estimates. The weights of the Network are not changed, and it has the same error conditions as
+// InputGradients.
+func (net *Network) WeightGradients(inputs, targets []float64, cost CostFunction) ([]float64, error) {
+	var grads []float64
+	err := net.withAllDeltas(inputs, targets, net.defaultCost(cost), func() {
+		for _, n := range net.nodesByID {
+			if n.adj == nil {
+				continue
+			}
+
+			for i := range n.adj.Weights() {
+				grads = append(grads, n.adj.Grad(n, i))
+			}
+			n.releaseWeights()
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return grads, nil
+}
+
+// DeltaNorms returns the L2 norm of the deltas (derivatives of the cost w.r.t. the values) of every
+// Node, given the inputs and their target outputs. This can be used to diagnose vanishing or
+// exploding gradients in deep Networks. The map is keyed in the same way as UpdateNorms. If 'cost'
+// is nil, the Network's CostFunction is used. Either way, the Network's Reduction is applied to it.
+//
+// Like InputGradients, DeltaNorms makes every Node calculate its deltas temporarily, and does not
+// change the weights of the Network. It has the same error conditions as InputGradients.
+func (net *Network) DeltaNorms(inputs, targets []float64, cost CostFunction) (map[string]float64, error) {
+	norms := make(map[string]float64, len(net.nodesByID))
+	err := net.withAllDeltas(inputs, targets, net.defaultCost(cost), func() {
+		for _, n := range net.nodesByID {
+			var sum float64
+			for _, d := range n.deltas {
+				sum += d * d
+			}
+
+			norms[n.key()] = math.Sqrt(sum)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return norms, nil
+}
+
+// withAllDeltas evaluates the Network and calculates the deltas of every Node for the given inputs
+// and targets with the CostFunction given, calling f before they are discarded. It gives the errors
+// for InputGradients.
+func (net *Network) withAllDeltas(inputs, targets []float64, cost CostFunction, f func()) error {
+	var err error
+	if net.stat < finalized {
+		err = ErrNetNotFinalized
+	} else if net.hasDelay {
+		err = ErrNetHasDelay
+	} else if len(inputs) != net.InputSize() {
+		err = SizeMismatchError{net.InputSize(), len(inputs), "inputs"}
+	} else if len(targets) != net.OutputSize() {
+		err = SizeMismatchError{net.OutputSize(), len(targets), "targets"}
+	}
+
+	if err != nil {
+		if net.panicErrors {
+			panic(err)
+		}
+
+		return err
+	}
+
+	// we've already checked for all of the errors that SetInputs and evaluate could return
+	net.SetInputs(inputs)
+	net.evaluate()
+
+	// make every Node calculate its deltas, and restore them once we're done
+	deltas := make([][]float64, len(net.nodesByID))
+	calcInDeltas := make([]bool, len(net.nodesByID))
+	for i, n := range net.nodesByID {
+		deltas[i], calcInDeltas[i] = n.deltas, n.calcInDeltas
+
+		n.deltas = make([]float64, n.Size())
+		n.calcInDeltas = !n.IsInput()
+	}
+
+	net.getDeltas(Datum{Inputs: inputs, Outputs: targets}, cost)
+
+	f()
+
+	for i, n := range net.nodesByID {
+		n.deltas, n.calcInDeltas = deltas[i], calcInDeltas[i]
+	}
+
+	// the deltas that were calculated have been discarded
+	net.stat = evaluated
+	return nil
+}
+
+// defaultCost returns the given CostFunction with the Network's Reduction applied to it, using the
+// Network's own CostFunction if the one given is nil
+func (net *Network) defaultCost(cost CostFunction) CostFunction {
+	if cost == nil {
+		cost = net.cf
+	}
+
+	return net.reduced(cost)
+}
+
+// Jacobian returns the derivatives of each of the Network's outputs w.r.t. each of its inputs, for
+// the given inputs, such that Jacobian(inputs)[i][j] is the derivative of output i w.r.t. input j.
+// This is calculated by backpropagating once for each output, so it may be slow for Networks with
+// many outputs. The CostFunction and Reduction of the Network are not used.
+//
+// Like InputGradients, Jacobian does not change the weights of the Network. It has the same error
+// conditions as InputGradients, except that there are no targets to check.
+func (net *Network) Jacobian(inputs []float64) ([][]float64, error) {
+	if net.stat < finalized {
+		if net.panicErrors {
+			panic(ErrNetNotFinalized)
+		}
+
+		return nil, ErrNetNotFinalized
+	}
+
+	targets := make([]float64, net.OutputSize())
+	jac := make([][]float64, net.OutputSize())
+	for i := range jac {
+		err := net.withAllDeltas(inputs, targets, unitCost(i), func() {
+			jac[i] = make([]float64, 0, net.InputSize())
+			for _, in := range net.inputs.nodes {
+				jac[i] = append(jac[i], in.deltas...)
+			}
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return jac, nil
+}
+
+// unitCost is a CostFunction whose cost is simply the output at its index, so that the deltas it
+// gives are the derivatives of that output. It is used by Jacobian.
+type unitCost int
+
+func (c unitCost) TypeString() string {
+	return "unit"
+}
+
+func (c unitCost) Cost(outs, targets []float64) float64 {
+	return outs[c]
+}
+
+func (c unitCost) Derivs(outs, targets []float64) []float64 {
+	ds := make([]float64, len(outs))
+	ds[c] = 1
+	return ds
+}
+
+// CurrentOutputs returns a copy of the Network's output values from the last time they were
+// evaluated, without setting the inputs. If the Network has not been finalized, CurrentOutputs
+// will return ErrNetNotFinalized. If the outputs have not been evaluated since the inputs or
+// weights last changed, it will return ErrNetNotEvaluated. If PanicErrors() has been called, both
+// errors will be panicked instead.
+func (net *Network) CurrentOutputs() ([]float64, error) {
+	var err error
+	if net.stat < finalized {
+		err = ErrNetNotFinalized
+	} else if net.stat < evaluated {
+		err = ErrNetNotEvaluated
+	}
+
+	if err != nil {
+		if net.panicErrors {
+			panic(err)
+		}
+
+		return nil, err
+	}
+
+	return net.outputs.getValues(true), nil
+}
+
+// Reset discards all of the values and deltas that have been calculated, so that the next call to
+// GetOutputs will recalculate everything. This is necessary if the weights of the Network have been
+// changed directly (e.g. by modifying the slice given by an Operator's Weights method), because
+// the Network has no way of knowing that they have changed. SetWeights and training already handle
+// this.
+//
+// Reset does not affect the current inputs, any values held in delay, or changes that have been
+// saved from an incomplete batch. If the Network has not been finalized, Reset does nothing.
+func (net *Network) Reset() {
+	if net.stat < finalized {
+		return
+	}
+
+	for _, n := range net.nodesByID {
+		if !n.IsInput() {
+			for i := range n.values.Values {
+				n.values.Values[i] = 0
+			}
+		}
+
+		for i := range n.deltas {
+			n.deltas[i] = 0
+		}
+	}
+
+	net.stat = finalized
+}
+
+// StepSequence runs the Network through each set of inputs in order, as successive time-steps,
+// returning the outputs from each step. This is mainly intended for recurrent Networks -- for
+// those without delay, it is equivalent to calling GetOutputs for each set of inputs.
+//
+// The hidden state of the Network (i.e. the values waiting in delay, and any Stateful Operators)
+// is not reset before or after the sequence; that can be done with ResetState().
+//
+// StepSequence has the same error conditions as GetOutputs. If an error is encountered, the
+// outputs from the time-steps before it are returned alongside it.
+func (net *Network) StepSequence(inputs [][]float64) ([][]float64, error) {
+	outs := make([][]float64, 0, len(inputs))
+	for i := range inputs {
+		o, err := net.GetOutputs(inputs[i])
+		if err != nil {
+			return outs, err
+		}
+
+		outs = append(outs, o)
+	}
+
+	// Values are stored during evaluation for backpropagation through time. We aren't training,
+	// so they are no longer needed.
+	if net.hasDelay {
+		for _, n := range net.nodesByID {
+			n.storedValues = nil
+		}
+	}
+
+	return outs, nil
+}
+
+// Weights returns a copy of all of the weights in the Network, concatenated in order of Node ID.
+// Only Nodes with Adjustable Operators have weights. Weights returns nil if the Network has not
+// been finalized.
+//
+// The order of the weights is deterministic, so the result can be given to SetWeights for another
+// Network with the same structure.
+func (net *Network) Weights() []float64 {
+	if net.stat < finalized {
+		return nil
+	}
+
+	var ws []float64
+	for _, n := range net.nodesByID {
+		if n.adj != nil {
+			ws = append(ws, n.adj.Weights()...)
+			n.releaseWeights()
+		}
+	}
+
+	return ws
+}
+
+// NumWeights returns the total number of weights in the Network, or -1 if the Network has not
+// been finalized.
+func (net *Network) NumWeights() int {
+	if net.stat < finalized {
+		return -1
+	}
+
+	var num int
+	for _, n := range net.nodesByID {
+		num += n.NumWeights()
+	}
+
+	return num
+}
+
+// WeightStats gives the same statistics as (*Node).WeightStats, but for all of the weights in the
+// Network together. If the Network has not been finalized, WeightStats returns ErrNetNotFinalized.
+// If it has no weights, it returns ErrNoWeights.
+func (net *Network) WeightStats() (min, max, mean, sd float64, err error) {
+	if net.stat < finalized {
+		return 0, 0, 0, 0, ErrNetNotFinalized
+	}
+
+	return weightStats(net.Weights())
+}
+
+// SetWeights sets all of the weights in the Network to the values given, in the same order as
+// provided by Weights. Any changes that have been saved from an incomplete batch are not affected.
+// There are several error conditions:
+//	(0) If the Network has not been finalized: ErrNetNotFinalized,
+//	(1) If the number of weights doesn't match the total number (NumWeights): type
+//	SizeMismatchError,
+// If PanicErrors() has been called, error conditions will be panicked, not returned.
+func (net *Network) SetWeights(weights []float64) error {
+	var err error
+	if net.stat < finalized {
+		err = ErrNetNotFinalized
+	} else if len(weights) != net.NumWeights() {
+		err = SizeMismatchError{net.NumWeights(), len(weights), "weights"}
+	}
+
+	if err != nil {
+		if net.panicErrors {
+			panic(err)
+		}
+
+		return err
+	}
+
+	for _, n := range net.nodesByID {
+		if n.adj != nil {
+			ws := n.adj.Weights()
+			copy(ws, weights)
+			weights = weights[len(ws):]
+			n.releaseWeights()
+		}
+	}
+
+	// the current values no longer reflect the weights
+	net.stat = finalized
+	return nil
+}
+
+// TieWeights makes Node b share the weights of Node a, as in siamese networks, so that both always
+// have the same weights. The current weights of a are copied to b. During training, the gradients
+// from every tied Node are summed and a single update is made with the Optimizer, Penalty, and
+// trainability of a (or of the Node that a is tied to, if a has already been tied to another).
+// Any number of Nodes can be tied together in this way.
+//
+// Ties are not saved with the Network, and weights set directly (e.g. by SetWeights) are not kept
+// in sync until the next update. There are a few error conditions:
+//	(0) If the Network has not been finalized: ErrNetNotFinalized,
+//	(1) If either Node is nil: type NilArgError,
+//	(2) If either Node belongs to a different Network: ErrTieDifferentNetwork,
+//	(3) If either Node does not have an Adjustable Operator: ErrTieNotAdjustable,
+//	(4) If the Operators have different types or numbers of weights: ErrTieMismatch,
+//	(5) If b is already tied to another Node, has others tied to it, or is the same as a:
+//	ErrAlreadyTied
+// If PanicErrors() has been called, error conditions will be panicked, not returned.
+func (net *Network) TieWeights(a, b *Node) error {
+	var err error
+	if net.stat < finalized {
+		err = ErrNetNotFinalized
+	} else if a == nil {
+		err = NilArgError{"a"}
+	} else if b == nil {
+		err = NilArgError{"b"}
+	} else if a.host != net || b.host != net {
+		err = ErrTieDifferentNetwork
+	} else if a.adj == nil || b.adj == nil {
+		err = ErrTieNotAdjustable
+	} else if a.op.TypeString() != b.op.TypeString() || len(a.adj.Weights()) != len(b.adj.Weights()) {
+		err = ErrTieMismatch
+	}
+
+	root := a
+	if err == nil {
+		if a.tiedTo != nil {
+			root = a.tiedTo
+		}
+
+		if b == root || b.tiedTo != nil || len(b.tied) != 0 {
+			err = ErrAlreadyTied
+		}
+	}
+
+	if err != nil {
+		if net.panicErrors {
+			panic(err)
+		}
+
+		return err
+	}
+
+	copy(b.adj.Weights(), root.adj.Weights())
+	b.releaseWeights()
+	root.releaseWeights()
+	b.tiedTo = root
+	root.tied = append(root.tied, b)
+
+	// the current values no longer reflect the weights
+	net.stat = finalized
+	return nil
+}
+
+// Summary returns a human-readable description of the structure of the Network, with one line for
+// each Node, followed by the totals. Each line gives the Node, its dimensions, the type of its
+// Operator, its inputs, and its number of weights. Nodes with delay and output Nodes are marked as
+// such. The Nodes are listed in the order given by SortedNodes, so that each Node comes after its
+// inputs.
+//
+// Summary can be called at any point while the Network is being constructed.
+func (net *Network) Summary() string {
+	var b strings.Builder
+	var totalValues, totalWeights int
+
+	// before finalizing, the order can't be stored because more Nodes may still be added
+	nodes := net.SortedNodes()
+	if nodes == nil {
+		nodes = net.postOrder(net.nodesByID)
+	}
+
+	for _, n := range nodes {
+		typ := n.OperatorType()
+		if n.isPlaceholder() {
+			typ = "placeholder"
+		} else if n.IsInput() {
+			typ = "input"
+		}
+
+		var ins []string
+		if !n.IsInput() {
+			for _, in := range n.inputs.nodes {
+				ins = append(ins, in.String())
+			}
+		}
+
+		fmt.Fprintf(&b, "%v: dims %v, operator %s, inputs [%s], weights %d", n, n.Dims(), typ, strings.Join(ins, ", "), n.NumWeights())
+		if n.HasDelay() {
+			fmt.Fprintf(&b, ", delay %d", n.Delay())
+		}
+		if n.IsOutput() {
+			b.WriteString(", output")
+		}
+		b.WriteString("\n")
+
+		totalValues += n.Size()
+		totalWeights += n.NumWeights()
+	}
+
+	fmt.Fprintf(&b, "Total: %d Nodes, %d values, %d weights\n", len(net.nodesByID), totalValues, totalWeights)
+	return b.String()
+}
+
+// UseEMAWeights switches the Network to use the exponential moving averages of its weights, which
+// are tracked during training if TrainArgs.EMADecay is set. This usually gives more stable outputs
+// than the raw weights. The raw weights are kept, and can be returned to with UseRawWeights. Train
+// will always switch back to the raw weights before it starts.
+//
+// While the averaged weights are in use, they are what will be given by Weights and saved by Save.
+//
+// UseEMAWeights returns ErrNetNotFinalized if the Network has not been finalized, and
+// ErrNoEMAWeights if the moving averages have not been tracked.
+func (net *Network) UseEMAWeights() error {
+	if net.stat < finalized {
+		return ErrNetNotFinalized
+	} else if net.usingEMA {
+		return nil
+	}
+
+	for _, n := range net.nodesByID {
+		if n.adj != nil && len(n.emaWeights) == 0 {
+			return ErrNoEMAWeights
+		}
+	}
+
+	net.swapEMA()
+	return nil
+}
+
+// UseRawWeights switches the Network back to using its raw weights, if UseEMAWeights had been
+// called. Otherwise, it does nothing.
+func (net *Network) UseRawWeights() {
+	if net.usingEMA {
+		net.swapEMA()
+	}
+}
+
 // ChangeCost changes the CostFunction of the Network, after it has been finalized. This allows
 // different CostFunctions for training and final model evaluation. If cf is nil, ChangeCost will
 // panic with type NilArgError.
@@ -120,9 +900,285 @@ func (net *Network) ChangeCost(cf CostFunction) *Network {
 	return net
 }
 
+// setTraining sets whether or not the Network is being trained. Because Operators may behave
+// differently during training, any values that have been calculated are marked as outdated if this
+// changes.
+func (net *Network) setTraining(training bool) {
+	if net.training != training && net.stat > finalized {
+		net.stat = finalized
+	}
+
+	net.training = training
+}
+
+// UpdateNorms returns the L2 norm of the total change to the weights of each trainable Node in each
+// epoch of the last call to Train, if TrainArgs.RecordUpdateNorms was true. The map is keyed by the
+// Node's name, or the result of its String method if it has none; Nodes should therefore have
+// unique names. Nodes that are not trainable, or that have no weights, are not included.
+//
+// If norms were not recorded, UpdateNorms returns nil.
+func (net *Network) UpdateNorms() map[string][]float64 {
+	if net.updateNorms == nil {
+		return nil
+	}
+
+	norms := make(map[string][]float64, len(net.updateNorms))
+	for name, ns := range net.updateNorms {
+		norms[name] = append([]float64(nil), ns...)
+	}
+
+	return norms
+}
+
+// WeightHistogram returns a histogram of the weights of each Node that has any, with the given
+// number of buckets evenly spanning the range from that Node's minimum weight to its maximum. The
+// map is keyed in the same way as UpdateNorms. If all of a Node's weights are equal, they are all
+// counted in the first bucket. The weights are not modified.
+//
+// WeightHistogram returns nil if the Network has not been finalized or if buckets < 1.
+func (net *Network) WeightHistogram(buckets int) map[string][]int {
+	if net.stat < finalized || buckets < 1 {
+		return nil
+	}
+
+	hists := make(map[string][]int)
+	for _, n := range net.nodesByID {
+		if n.NumWeights() == 0 {
+			continue
+		}
+
+		hists[n.key()] = histogram(n.adj.Weights(), buckets)
+		n.releaseWeights()
+	}
+
+	return hists
+}
+
+// histogram counts the values in each of the given number of buckets between their minimum and
+// maximum. Assumes len(vs) != 0.
+func histogram(vs []float64, buckets int) []int {
+	min, max, _, _, _ := weightStats(vs)
+
+	counts := make([]int, buckets)
+	for _, v := range vs {
+		var b int
+		if max > min {
+			b = int(float64(buckets) * (v - min) / (max - min))
+		}
+
+		// the maximum would otherwise be just past the last bucket
+		if b >= buckets {
+			b = buckets - 1
+		}
+
+		counts[b]++
+	}
+
+	return counts
+}
+
+// SaturationReport returns the fraction of the values of each Node with a Saturating Operator that
+// were saturated in the last evaluation -- e.g. inactive ReLU units, or Logistic units within 0.01
+// of 0 or 1. The map is keyed in the same way as UpdateNorms. Nodes with Operators that are not
+// Saturating are not included.
+//
+// SaturationReport returns nil if the Network has not been evaluated since it was last changed.
+func (net *Network) SaturationReport() map[string]float64 {
+	if net.stat < evaluated {
+		return nil
+	}
+
+	report := make(map[string]float64)
+	for _, n := range net.nodesByID {
+		sat, ok := n.op.(Saturating)
+		if !ok || n.Size() == 0 {
+			continue
+		}
+
+		var count int
+		for i := 0; i < n.Size(); i++ {
+			if sat.Saturated(n, i) {
+				count++
+			}
+		}
+
+		report[n.key()] = float64(count) / float64(n.Size())
+	}
+
+	return report
+}
+
+// SetWorkers sets the maximum number of goroutines that may be used at once for calculations
+// within the Network -- both by the Network itself and by the Operators and Optimizers of its Nodes.
+// If n = 1, all calculations are done sequentially, in the calling goroutine. If n = 0, the number
+// of CPUs is used, as given by runtime.NumCPU(). This is the default.
+//
+// SetWorkers can be called at any time, and will panic with ErrNegativeWorkers if n < 0.
+func (net *Network) SetWorkers(n int) *Network {
+	if n < 0 {
+		panic(ErrNegativeWorkers)
+	}
+
+	net.workers = n
+	return net
+}
+
+// Workers returns the maximum number of goroutines that may be used for calculations, as set by
+// SetWorkers.
+func (net *Network) Workers() int {
+	if net.workers == 0 {
+		return runtime.NumCPU()
+	}
+
+	return net.workers
+}
+
+// Reduction determines how the costs of each output are combined into the total cost of a sample,
+// as set by SetReduction. This affects both the reported costs and the derivatives used for
+// training -- and therefore the effective learning rate.
+type Reduction int8
+
+const (
+	// ReduceDefault uses the cost and derivatives exactly as they are given by the CostFunction.
+	// For most of those in costfuncs, the cost is the mean over the outputs, but the derivatives
+	// are those of the sum -- so the size of the changes to the weights grows with the number of
+	// outputs. This is the Reduction used by new Networks.
+	ReduceDefault Reduction = iota
+
+	// ReduceSum gives the sum of the costs of each output, along with its derivatives.
+	ReduceSum
+
+	// ReduceMean gives the mean of the costs of each output, along with its derivatives. These
+	// are 1/n of those given by ReduceSum, where n is the number of outputs. Because the deltas of
+	// the outputs are divided by their number, the size of each update does not depend on it, so
+	// the same learning rate can be used regardless of the number of outputs.
+	ReduceMean
+)
+
+// SetReduction sets how the costs of each output are combined, for both training and testing.
+// ReduceSum and ReduceMean require that the CostFunction is separable: the cost of all of the
+// outputs must be the sum of the costs of each output on its own, with Derivs giving the
+// derivatives of that sum. All of the CostFunctions in costfuncs satisfy this. The cost of each
+// output is given by OutputCosts if the CostFunction is a SeparableCostFunction, and otherwise by
+// calling Cost with only that output.
+//
+// SetReduction can be called at any time. The Reduction is saved with the Network.
+func (net *Network) SetReduction(r Reduction) *Network {
+	net.reduction = r
+	return net
+}
+
+// reducedCost is a wrapper for CostFunctions that applies a Reduction other than ReduceDefault
+type reducedCost struct {
+	CostFunction
+	r Reduction
+}
+
+// reducedLabelCost is the same as reducedCost, but for LabelCostFunctions, so that the labeled
+// variants are still used with a Reduction
+type reducedLabelCost struct {
+	reducedCost
+}
+
+// reduced returns the CostFunction with the Network's Reduction applied to it
+func (net *Network) reduced(cf CostFunction) CostFunction {
+	if net.reduction == ReduceDefault {
+		return cf
+	}
+
+	c := reducedCost{cf, net.reduction}
+	if _, ok := cf.(LabelCostFunction); ok {
+		return reducedLabelCost{c}
+	}
+
+	return c
+}
+
+func (c reducedCost) Cost(outs, targets []float64) float64 {
+	var sum float64
+	if sc, ok := c.CostFunction.(SeparableCostFunction); ok {
+		for _, cost := range sc.OutputCosts(outs, targets) {
+			sum += cost
+		}
+	} else {
+		for i := range outs {
+			sum += c.CostFunction.Cost(outs[i:i+1], targets[i:i+1])
+		}
+	}
+
+	if c.r == ReduceMean {
+		sum /= float64(len(outs))
+	}
+
+	return sum
+}
+
+func (c reducedCost) Derivs(outs, targets []float64) []float64 {
+	ds := c.CostFunction.Derivs(outs, targets)
+
+	if c.r == ReduceMean {
+		for i := range ds {
+			ds[i] /= float64(len(outs))
+		}
+	}
+
+	return ds
+}
+
+// LabelCost gives the same result as Cost would with a one-hot vector of targets, without
+// constructing it: the cost of the labeled output is given by LabelCost on its own, and every other
+// output has a target of zero.
+func (c reducedLabelCost) LabelCost(outs []float64, label int) float64 {
+	lcf := c.CostFunction.(LabelCostFunction)
+
+	zero := []float64{0}
+
+	var sum float64
+	for i := range outs {
+		if i == label {
+			sum += lcf.LabelCost(outs[i:i+1], 0)
+		} else {
+			sum += lcf.Cost(outs[i:i+1], zero)
+		}
+	}
+
+	if c.r == ReduceMean {
+		sum /= float64(len(outs))
+	}
+
+	return sum
+}
+
+func (c reducedLabelCost) LabelDerivs(outs []float64, label int) []float64 {
+	ds := c.CostFunction.(LabelCostFunction).LabelDerivs(outs, label)
+
+	if c.r == ReduceMean {
+		for i := range ds {
+			ds[i] /= float64(len(outs))
+		}
+	}
+
+	return ds
+}
+
 // Error returns any errors encountered while constructing the Network, particularly while creating
 // the architecture. This method will always return nil after the Network has been SUCESSFULLY
 // finalized.
 func (net *Network) Error() error {
 	return net.err
 }
+
+// equal returns whether or not the two slices contain the same values
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
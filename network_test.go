@@ -0,0 +1,919 @@
+package badstudent_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+// counter is an elementwise identity Operator that counts how many values it has calculated
+type counter struct {
+	calls int
+}
+
+func (c *counter) TypeString() string              { return "counter" }
+func (c *counter) Finalize(n *bs.Node) error       { return nil }
+func (c *counter) Deriv(n *bs.Node, i int) float64 { return 1 }
+
+func (c *counter) Value(in float64, i int) float64 {
+	c.calls++
+	return in
+}
+
+func TestCurrentOutputs(t *testing.T) {
+	c := new(counter)
+
+	net := new(bs.Network)
+	out := net.Add(c, net.AddInput([]int{3}))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := net.CurrentOutputs(); err != bs.ErrNetNotEvaluated {
+		t.Errorf("CurrentOutputs before evaluation gave error %v, expected ErrNetNotEvaluated", err)
+	}
+
+	inputs := []float64{1, 2, 3}
+	if _, err := net.GetOutputs(inputs); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := c.calls
+	if calls != len(inputs) {
+		t.Fatalf("Evaluation calculated %d values, expected %d", calls, len(inputs))
+	}
+
+	outs, err := net.CurrentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(outs, inputs, 0) {
+		t.Errorf("CurrentOutputs gave %v, expected %v", outs, inputs)
+	}
+
+	// the same inputs again (in a different slice) don't cause the values to be recalculated
+	if outs, err := net.GetOutputs(append([]float64(nil), inputs...)); err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(outs, inputs, 0) {
+		t.Errorf("Repeated GetOutputs gave %v, expected %v", outs, inputs)
+	}
+
+	if c.calls != calls {
+		t.Errorf("Repeated inputs calculated %d more values, expected the cache to be reused", c.calls-calls)
+	}
+
+	if _, err := net.GetOutputs([]float64{1, 2, 4}); err != nil {
+		t.Fatal(err)
+	} else if c.calls == calls {
+		t.Errorf("New inputs did not cause the values to be recalculated")
+	}
+
+	if err := net.SetInputs([]float64{0, 0, 0}); err != nil {
+		t.Fatal(err)
+	} else if _, err := net.CurrentOutputs(); err != bs.ErrNetNotEvaluated {
+		t.Errorf("CurrentOutputs after changing the inputs gave error %v, expected ErrNetNotEvaluated", err)
+	}
+}
+
+func TestWeightsRoundTrip(t *testing.T) {
+	from, to := xorNet(t, 1), xorNet(t, 2)
+
+	ws := from.Weights()
+	if err := to.SetWeights(ws); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range xorData {
+		a, err := from.GetOutputs(d[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := to.GetOutputs(d[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !sliceEqual(a, b, 0) {
+			t.Errorf("Outputs for %v differ after transferring weights: %v != %v", d[0], a, b)
+		}
+	}
+
+	// the exported weights are a copy
+	ws[0]++
+	if from.Weights()[0] == ws[0] {
+		t.Errorf("Changing the exported weights changed the Network")
+	}
+
+	err := to.SetWeights(ws[1:])
+	if e, ok := err.(bs.SizeMismatchError); !ok {
+		t.Errorf("Wrong number of weights gave %T (%v), expected SizeMismatchError", err, err)
+	} else if e.Expected != len(ws) || e.Given != len(ws)-1 {
+		t.Errorf("Error was for %d weights instead of %d, expected %d instead of %d", e.Given, e.Expected, len(ws)-1, len(ws))
+	}
+}
+
+func TestSummary(t *testing.T) {
+	summary := xorNet(t, 1).Summary()
+
+	expected := []string{
+		`"hidden neurons": dims [3], operator neurons, inputs ["input"], weights 9`,
+		`"output neurons": dims [1], operator neurons, inputs ["hidden logistic"], weights 4`,
+		`"output logistic": dims [1], operator logistic, inputs ["output neurons"], weights 0, output`,
+		"Total: 5 Nodes, 10 values, 13 weights",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(summary, line+"\n") {
+			t.Errorf("Summary doesn't contain %q:\n%s", line, summary)
+		}
+	}
+
+	// a Node that feeds several others is still only listed once
+	net := branchedNet(t)
+	lines := strings.Split(strings.TrimSuffix(net.Summary(), "\n"), "\n")
+	if len(lines) != len(net.SortedNodes())+1 {
+		t.Errorf("Summary of %d Nodes has %d lines, expected one per Node and the total:\n%s",
+			len(net.SortedNodes()), len(lines), strings.Join(lines, "\n"))
+	}
+}
+
+func TestReset(t *testing.T) {
+	op := operators.Neurons(1)
+
+	net := new(bs.Network)
+	out := net.Add(op, net.AddInput([]int{2})).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	// two input weights, then the bias
+	if err := net.SetWeights([]float64{1, 1, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{2, 5}
+	if outs, err := net.GetOutputs(inputs); err != nil {
+		t.Fatal(err)
+	} else if outs[0] != 7 {
+		t.Fatalf("Output is %v, expected 7", outs[0])
+	}
+
+	// changing the weights directly isn't noticed by the Network until it is Reset
+	op.Weights()[0] = 3
+	if outs, err := net.GetOutputs(inputs); err != nil {
+		t.Fatal(err)
+	} else if outs[0] != 7 {
+		t.Errorf("Output changed to %v without Reset, expected the cached value 7", outs[0])
+	}
+
+	net.Reset()
+	if outs, err := net.GetOutputs(inputs); err != nil {
+		t.Fatal(err)
+	} else if outs[0] != 11 {
+		t.Errorf("Output after Reset is %v, expected 11", outs[0])
+	}
+}
+
+func TestGetOutputsBatch(t *testing.T) {
+	net := xorNet(t, 1)
+
+	inputs := make([][]float64, len(xorData))
+	for i, d := range xorData {
+		inputs[i] = d[0]
+	}
+
+	batch, err := net.GetOutputsBatch(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range inputs {
+		outs, err := net.GetOutputs(inputs[i])
+		if err != nil {
+			t.Fatal(err)
+		} else if !sliceEqual(batch[i], outs, 0) {
+			t.Errorf("Batched outputs for %v are %v, expected %v", inputs[i], batch[i], outs)
+		}
+	}
+
+	_, err = net.GetOutputsBatch([][]float64{{0, 1}, {0, 1, 2}})
+	if e, ok := err.(bs.SizeMismatchError); !ok {
+		t.Errorf("Wrong input size gave %T (%v), expected SizeMismatchError", err, err)
+	} else if !strings.Contains(e.Description, "index 1") {
+		t.Errorf("Error doesn't give the index of the offending inputs: %v", err)
+	}
+}
+
+// batchInputs returns random inputs for the XOR Network
+func batchInputs(count int) [][]float64 {
+	rng := rand.New(rand.NewSource(1))
+
+	inputs := make([][]float64, count)
+	for i := range inputs {
+		inputs[i] = []float64{rng.Float64()*2 - 1, rng.Float64()*2 - 1}
+	}
+
+	return inputs
+}
+
+func BenchmarkGetOutputsBatch(b *testing.B) {
+	net := xorNet(b, 1)
+	inputs := batchInputs(1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := net.GetOutputsBatch(inputs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetOutputsLoop(b *testing.B) {
+	net := xorNet(b, 1)
+	inputs := batchInputs(1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, in := range inputs {
+			if _, err := net.GetOutputs(in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// checkTopological reports an error if any Node in 'sorted' comes before one of its inputs, or if
+// 'sorted' doesn't contain each Node in the Network exactly once
+func checkTopological(t *testing.T, net *bs.Network, sorted []*bs.Node) {
+	t.Helper()
+
+	position := make(map[*bs.Node]int)
+	for i, n := range sorted {
+		if _, ok := position[n]; ok {
+			t.Errorf("Node %v is listed more than once", n)
+		}
+		position[n] = i
+	}
+
+	if len(position) != len(net.Nodes()) {
+		t.Errorf("Sorted %d Nodes, expected %d", len(position), len(net.Nodes()))
+	}
+
+	for i, n := range sorted {
+		if n.IsInput() {
+			continue
+		}
+
+		for in := 0; in < n.NumInputNodes(); in++ {
+			if position[n.Input(in)] > i {
+				t.Errorf("Node %v comes before its input %v", n, n.Input(in))
+			}
+		}
+	}
+}
+
+func TestSortedNodes(t *testing.T) {
+	net := xorNet(t, 1)
+	checkTopological(t, net, net.SortedNodes())
+
+	// a Node that feeds several others
+	net = branchedNet(t)
+	checkTopological(t, net, net.SortedNodes())
+
+	// a placeholder is created before its input, so it has a lower ID
+	net = new(bs.Network)
+	p := net.Placeholder([]int{2})
+	h := net.Add(operators.Logistic(), net.AddInput([]int{2}))
+	p.Replace(operators.Identity(), h)
+	out := net.Add(operators.Neurons(1), p).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	sorted := net.SortedNodes()
+	checkTopological(t, net, sorted)
+
+	// the order is stored, so it's the same each time
+	again := net.SortedNodes()
+	for i := range sorted {
+		if sorted[i] != again[i] {
+			t.Fatalf("SortedNodes gave a different order the second time")
+		}
+	}
+}
+
+func TestEMAWeights(t *testing.T) {
+	net := xorNet(t, 1)
+	if err := net.UseEMAWeights(); err != bs.ErrNoEMAWeights {
+		t.Errorf("UseEMAWeights before training gave error %v, expected ErrNoEMAWeights", err)
+	}
+
+	args := xorArgs(t, 200)
+	args.EMADecay = 0.9
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	// maxDiff returns the largest difference between the raw and averaged weights
+	maxDiff := func() float64 {
+		raw := net.Weights()
+		if err := net.UseEMAWeights(); err != nil {
+			t.Fatal(err)
+		}
+		ema := net.Weights()
+		net.UseRawWeights()
+
+		if !sliceEqual(net.Weights(), raw, 0) {
+			t.Fatalf("UseRawWeights didn't restore the raw weights")
+		}
+
+		var max float64
+		for i := range raw {
+			max = math.Max(max, math.Abs(raw[i]-ema[i]))
+		}
+		return max
+	}
+
+	// while the weights are changing, the average lags behind them
+	if d := maxDiff(); d < 1e-3 {
+		t.Errorf("Averaged weights differ from the raw weights by only %v during training", d)
+	}
+
+	// once the weights stop changing, the average converges to them
+	for _, n := range net.Nodes() {
+		if n.NumWeights() != 0 {
+			if err := n.ReplaceHP("learning-rate", hyperparams.Constant(0)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	args.RunCondition = bs.TrainUntil(200 + 500)
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := maxDiff(); d > 1e-9 {
+		t.Errorf("Averaged weights differ from the raw weights by %v after they stopped changing", d)
+	}
+}
+
+// goroutineID returns the ID of the current goroutine, from its stack trace
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	return strings.Fields(string(buf))[1]
+}
+
+// goroutines is an elementwise identity Operator that records the goroutines it is called from
+type goroutines struct {
+	mux sync.Mutex
+	ids map[string]bool
+}
+
+func (g *goroutines) TypeString() string              { return "goroutines" }
+func (g *goroutines) Finalize(n *bs.Node) error       { return nil }
+func (g *goroutines) Deriv(n *bs.Node, i int) float64 { return 1 }
+
+func (g *goroutines) Value(in float64, i int) float64 {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	g.ids[goroutineID()] = true
+	return in
+}
+
+func TestSetWorkersSequential(t *testing.T) {
+	const size = 1000
+
+	g := &goroutines{ids: make(map[string]bool)}
+
+	net := new(bs.Network).SetWorkers(1)
+	out := net.Add(g, net.Add(operators.Neurons(size), net.AddInput([]int{2})).Opt(optimizers.SGD()))
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	} else if net.Workers() != 1 {
+		t.Fatalf("Network has %d workers, expected 1", net.Workers())
+	}
+	randomWeights(net, 1)
+
+	targets := make([]float64, size)
+	dataset := [][][]float64{{{0.5, -1}, targets}, {{-0.3, 0.8}, targets}}
+
+	if err := net.Train(bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(10)}); err != nil {
+		t.Fatal(err)
+	} else if len(g.ids) != 1 || !g.ids[goroutineID()] {
+		t.Errorf("With one worker, values were calculated in goroutines %v, expected only this one (%s)", g.ids, goroutineID())
+	}
+
+	// the same calculations with more workers give the same results
+	ws := net.Weights()
+	net.SetWorkers(4)
+	randomWeights(net, 1)
+	if err := net.Train(bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(10)}); err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(net.Weights(), ws, 1e-12) {
+		t.Errorf("Training with 4 workers gave different weights from training with 1")
+	}
+
+	if net.SetWorkers(0).Workers() != runtime.NumCPU() {
+		t.Errorf("With SetWorkers(0), Network has %d workers, expected %d", net.Workers(), runtime.NumCPU())
+	}
+}
+
+func TestSetReduction(t *testing.T) {
+	dataset := [][][]float64{{{1, -2}, {0.5, 1, -1}}}
+	weights := []float64{0.1, 0.2, 0.3, -0.4, 0.5, 0.6, 0.7, 0.8, -0.9}
+
+	// train returns the change in each weight from a single step, along with the cost before it
+	train := func(r bs.Reduction) ([]float64, float64) {
+		net := new(bs.Network)
+		out := net.Add(operators.Neurons(3), net.AddInput([]int{2})).Opt(optimizers.SGD())
+		net.AddHP("learning-rate", hyperparams.Constant(1))
+		if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+			t.Fatal(err)
+		}
+
+		net.SetReduction(r)
+		if err := net.SetWeights(weights); err != nil {
+			t.Fatal(err)
+		}
+
+		cost, _, err := net.Evaluate(bs.SliceSource(dataset), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := net.Train(bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(1)}); err != nil {
+			t.Fatal(err)
+		}
+
+		changes := net.Weights()
+		for i := range changes {
+			changes[i] -= weights[i]
+		}
+
+		return changes, cost
+	}
+
+	sumChanges, sumCost := train(bs.ReduceSum)
+	meanChanges, meanCost := train(bs.ReduceMean)
+
+	if !approxEqual(meanCost, sumCost/3, 1e-12) {
+		t.Errorf("Mean cost is %v, expected 1/3 of the summed cost %v", meanCost, sumCost)
+	}
+
+	for i := range sumChanges {
+		if sumChanges[i] == 0 || !approxEqual(meanChanges[i], sumChanges[i]/3, 1e-12) {
+			t.Errorf("Weight %d changed by %v with ReduceMean, expected 1/3 of %v from ReduceSum", i, meanChanges[i], sumChanges[i])
+		}
+	}
+}
+
+func TestInputGradients(t *testing.T) {
+	net := new(bs.Network)
+	l := net.Add(operators.Neurons(3), net.AddInput([]int{2}), net.AddInput([]int{1})).Opt(optimizers.SGD())
+	l = net.Add(operators.Tanh(), l)
+	l = net.Add(operators.Neurons(2), l).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	// with ReduceSum, the derivatives given by MSE are exactly those of its cost
+	net.SetReduction(bs.ReduceSum)
+	randomWeights(net, 6)
+	weights := net.Weights()
+
+	inputs, targets := []float64{0.4, -0.7, 1.2}, []float64{0.5, -0.25}
+	grads, err := net.InputGradients(inputs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cost := func(ins []float64) float64 {
+		c, _, err := net.Evaluate(bs.SliceSource([][][]float64{{ins, targets}}), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return c
+	}
+
+	const h = 1e-6
+	for i := range inputs {
+		plus := append([]float64{}, inputs...)
+		minus := append([]float64{}, inputs...)
+		plus[i] += h
+		minus[i] -= h
+
+		if numeric := (cost(plus) - cost(minus)) / (2 * h); !approxEqual(grads[i], numeric, 1e-6) {
+			t.Errorf("Gradient for input %d is %v, expected %v from finite differences", i, grads[i], numeric)
+		}
+	}
+
+	if !sliceEqual(net.Weights(), weights, 0) {
+		t.Errorf("InputGradients changed the weights of the Network")
+	}
+}
+
+func TestInputOutputSize(t *testing.T) {
+	net := new(bs.Network)
+	out := net.Add(operators.Neurons(1), net.AddInput([]int{2})).Opt(optimizers.SGD())
+	if in, o := net.InputSize(), net.OutputSize(); in != -1 || o != -1 {
+		t.Errorf("Sizes before finalizing are %d inputs and %d outputs, expected -1 and -1", in, o)
+	}
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	if in, o := net.InputSize(), net.OutputSize(); in != 2 || o != 1 {
+		t.Errorf("Sizes are %d inputs and %d outputs, expected 2 and 1", in, o)
+	}
+
+	net = xorNet(t, 1)
+	if in, o := net.InputSize(), net.OutputSize(); in != 2 || o != 1 {
+		t.Errorf("XOR Network has %d inputs and %d outputs, expected 2 and 1", in, o)
+	}
+}
+
+// canceller is an elementwise identity Operator that calls 'cancel' when it is evaluated
+type canceller struct {
+	cancel func()
+}
+
+func (c canceller) TypeString() string              { return "canceller" }
+func (c canceller) Finalize(n *bs.Node) error       { return nil }
+func (c canceller) Deriv(n *bs.Node, i int) float64 { return 1 }
+
+func (c canceller) Value(in float64, i int) float64 {
+	c.cancel()
+	return in
+}
+
+func TestGetOutputsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := new(counter)
+
+	net := new(bs.Network)
+	l := net.Add(canceller{cancel}, net.AddInput([]int{2}))
+	l = net.Add(c, l)
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{1, 2}
+
+	// cancelled partway through, so the last Node is never evaluated
+	if _, err := net.GetOutputsContext(ctx, inputs); err != context.Canceled {
+		t.Errorf("Cancelling during evaluation gave error %v, expected context.Canceled", err)
+	} else if c.calls != 0 {
+		t.Errorf("Node after cancellation calculated %d values, expected 0", c.calls)
+	}
+
+	// already cancelled, so nothing is evaluated
+	if _, err := net.GetOutputsContext(ctx, inputs); err != context.Canceled {
+		t.Errorf("Already-cancelled Context gave error %v, expected context.Canceled", err)
+	} else if c.calls != 0 {
+		t.Errorf("Node calculated %d values with an already-cancelled Context, expected 0", c.calls)
+	}
+
+	// the partial evaluation was discarded
+	outs, err := net.GetOutputsContext(context.Background(), inputs)
+	if err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(outs, inputs, 0) {
+		t.Errorf("Outputs after cancellation are %v, expected %v", outs, inputs)
+	}
+}
+
+func TestReduceMeanOutputCount(t *testing.T) {
+	// hiddenChange returns the change in the weights of a hidden Node shared by 'outputs' identical
+	// outputs, all with the same target, after a single step
+	hiddenChange := func(outputs int, r bs.Reduction) []float64 {
+		net := new(bs.Network)
+		hidden := net.Add(operators.Neurons(1), net.AddInput([]int{2})).Opt(optimizers.SGD())
+		out := net.Add(operators.Neurons(outputs), hidden).Opt(optimizers.SGD())
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+			t.Fatal(err)
+		}
+
+		net.SetReduction(r)
+
+		// the hidden Node has weights 0.5, -0.3, and bias 0.1; each output has weight 0.7 and bias 0
+		weights := []float64{0.5, -0.3, 0.1}
+		for i := 0; i < outputs; i++ {
+			weights = append(weights, 0.7, 0)
+		}
+
+		if err := net.SetWeights(weights); err != nil {
+			t.Fatal(err)
+		}
+
+		targets := make([]float64, outputs)
+		for i := range targets {
+			targets[i] = 1
+		}
+
+		data := bs.SliceSource([][][]float64{{{1, 2}, targets}})
+		if err := net.Train(bs.TrainArgs{Data: data, RunCondition: bs.TrainUntil(1)}); err != nil {
+			t.Fatal(err)
+		}
+
+		changes := net.Weights()[:3]
+		for i := range changes {
+			changes[i] -= weights[i]
+		}
+
+		return changes
+	}
+
+	// with ReduceMean, the size of the update doesn't depend on the number of outputs
+	one, ten := hiddenChange(1, bs.ReduceMean), hiddenChange(10, bs.ReduceMean)
+	if !sliceEqual(one, ten, 1e-12) {
+		t.Errorf("Changes to hidden weights with ReduceMean are %v with 10 outputs, expected %v as with 1", ten, one)
+	}
+
+	// with ReduceSum, it grows with them
+	one, ten = hiddenChange(1, bs.ReduceSum), hiddenChange(10, bs.ReduceSum)
+	for i := range one {
+		if !approxEqual(ten[i], 10*one[i], 1e-12) {
+			t.Errorf("Change to hidden weight %d with ReduceSum is %v with 10 outputs, expected %v", i, ten[i], 10*one[i])
+		}
+	}
+}
+
+func TestWeightHistogram(t *testing.T) {
+	net := xorNet(t, 1)
+
+	// make every weight of the output Neurons equal. They come after the 9 of the hidden Neurons.
+	ws := net.Weights()
+	for i := 9; i < len(ws); i++ {
+		ws[i] = 0.25
+	}
+
+	if err := net.SetWeights(ws); err != nil {
+		t.Fatal(err)
+	}
+
+	hists := net.WeightHistogram(4)
+	if len(hists) != 2 {
+		t.Fatalf("Histograms are %v, expected one for each Node with weights", hists)
+	}
+
+	for _, n := range net.Nodes() {
+		if n.NumWeights() == 0 {
+			if _, ok := hists[n.Name()]; ok {
+				t.Errorf("Gave histogram for Node %q, which has no weights", n.Name())
+			}
+
+			continue
+		}
+
+		hist := hists[n.Name()]
+		if len(hist) != 4 {
+			t.Errorf("Histogram for Node %q has %d buckets, expected 4", n.Name(), len(hist))
+		}
+
+		var sum int
+		for _, c := range hist {
+			sum += c
+		}
+
+		if sum != n.NumWeights() {
+			t.Errorf("Histogram for Node %q counts %d weights, expected %d", n.Name(), sum, n.NumWeights())
+		}
+	}
+
+	// equal weights are all in a single bucket
+	if hist := hists["output neurons"]; !reflect.DeepEqual(hist, []int{4, 0, 0, 0}) {
+		t.Errorf("Histogram of equal weights is %v, expected [4 0 0 0]", hist)
+	}
+
+	if !sliceEqual(net.Weights(), ws, 0) {
+		t.Errorf("WeightHistogram changed the weights of the Network")
+	}
+}
+
+func TestInfer(t *testing.T) {
+	const goroutines, calls = 16, 50
+
+	// Operators that keep state for backpropagation are included, to check that they don't
+	net := new(bs.Network)
+	l := net.AddInput([]int{2})
+	l = net.Add(operators.Neurons(4), l).Opt(optimizers.SGD())
+	l = net.Add(operators.LayerNorm(), l).Opt(optimizers.SGD())
+	l = net.Add(operators.Dropout(0.5), l)
+	l = net.Add(operators.Neurons(1), l).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+	randomWeights(net, 1)
+
+	// the expected outputs for each set of inputs, calculated sequentially
+	inputs := make([][]float64, calls)
+	expected := make([][]float64, calls)
+	for i := range inputs {
+		inputs[i] = []float64{float64(i) / calls, 1 - 2*float64(i)/calls}
+
+		outs, err := net.GetOutputs(inputs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected[i] = outs
+	}
+
+	// run with -race to check that concurrent calls don't conflict
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			for j := 0; j < calls; j++ {
+				// each goroutine goes through the inputs in a different order
+				i := (j + g*7) % calls
+
+				outs, err := net.Infer(inputs[i])
+				if err != nil {
+					t.Error(err)
+					return
+				} else if !sliceEqual(outs, expected[i], 0) {
+					t.Errorf("Outputs for %v are %v, expected %v", inputs[i], outs, expected[i])
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	// the values stored in the Network are from the last call to GetOutputs
+	if outs, err := net.CurrentOutputs(); err != nil {
+		t.Fatal(err)
+	} else if !sliceEqual(outs, expected[calls-1], 0) {
+		t.Errorf("Outputs stored after Infer are %v, expected %v", outs, expected[calls-1])
+	}
+}
+
+func TestDeltaNorms(t *testing.T) {
+	const depth = 4
+
+	// each Node halves the values of the one before it, so the deltas halve going backwards
+	net := new(bs.Network)
+	l := net.AddInput([]int{2}).SetName("input")
+	for i := 0; i < depth; i++ {
+		l = net.Add(operators.Scale(0.5), l).SetName(fmt.Sprint("scale ", i))
+	}
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	// the outputs are 1/16 of the inputs: [0.5, 1]. Their deltas are [0.5, 1] - [0.2, 0.6]
+	norms, err := net.DeltaNorms([]float64{8, 16}, []float64{0.2, 0.6}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := math.Hypot(0.3, 0.4)
+	for i := depth - 1; i >= 0; i-- {
+		name := fmt.Sprint("scale ", i)
+		if !approxEqual(norms[name], expected, 1e-12) {
+			t.Errorf("Norm of deltas for %q is %v, expected %v", name, norms[name], expected)
+		}
+
+		expected /= 2
+	}
+
+	if !approxEqual(norms["input"], expected, 1e-12) {
+		t.Errorf("Norm of deltas for the input is %v, expected %v", norms["input"], expected)
+	}
+}
+
+func TestTieWeights(t *testing.T) {
+	net := new(bs.Network)
+	a := net.Add(operators.Neurons(3), net.AddInput([]int{2})).Opt(optimizers.SGD())
+	b := net.Add(operators.Neurons(3), net.AddInput([]int{2})).Opt(optimizers.SGD())
+	other := net.Add(operators.Neurons(4), a)
+	out := net.Add(operators.Neurons(1), net.Add(operators.Logistic(), a, b), other).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := net.TieWeights(a, other); err != bs.ErrTieMismatch {
+		t.Errorf("Tying Nodes of different sizes gave error %v, expected ErrTieMismatch", err)
+	}
+
+	randomWeights(net, 1)
+	if err := net.TieWeights(a, b); err != nil {
+		t.Fatal(err)
+	}
+
+	// the weights of a are the first 9, followed by those of b
+	tied := func() bool {
+		ws := net.Weights()
+		return sliceEqual(ws[:9], ws[9:18], 0)
+	}
+
+	if !tied() {
+		t.Fatalf("Weights were not copied by TieWeights")
+	}
+
+	start := net.Weights()[:9]
+
+	rng := rand.New(rand.NewSource(1))
+	dataset := make([][][]float64, 10)
+	for i := range dataset {
+		dataset[i] = [][]float64{{rng.Float64(), rng.Float64(), rng.Float64(), rng.Float64()}, {rng.Float64()}}
+	}
+
+	args := bs.TrainArgs{
+		Data:         bs.SliceSource(dataset),
+		RunCondition: bs.TrainUntil(5 * len(dataset)),
+		OnEpoch: func(r bs.EpochResult) {
+			if !tied() {
+				t.Errorf("Weights of tied Nodes differ after epoch %d: %v", r.Epoch, net.Weights()[:18])
+			}
+		},
+	}
+
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	} else if sliceEqual(net.Weights()[:9], start, 0) {
+		t.Errorf("Tied weights were not changed by training")
+	}
+}
+
+func TestJacobian(t *testing.T) {
+	net := new(bs.Network)
+	out := net.Add(operators.Neurons(2), net.AddInput([]int{3})).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	// each output has its weights for the three inputs, followed by a bias
+	if err := net.SetWeights([]float64{1, 2, 3, 0.5, -1, 0, 4, -2}); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{0.3, -0.2, 0.7}
+	jac, err := net.Jacobian(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// for a linear Network, the Jacobian is exactly the weights
+	expected := [][]float64{{1, 2, 3}, {-1, 0, 4}}
+	if !reflect.DeepEqual(jac, expected) {
+		t.Errorf("Jacobian is %v, expected %v", jac, expected)
+	}
+
+	// ... and matches finite differences
+	const h = 1e-3
+	for j := range inputs {
+		plus := append([]float64{}, inputs...)
+		minus := append([]float64{}, inputs...)
+		plus[j] += h
+		minus[j] -= h
+
+		outsPlus, err := net.GetOutputs(plus)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		outsMinus, err := net.GetOutputs(minus)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range jac {
+			if numeric := (outsPlus[i] - outsMinus[i]) / (2 * h); !approxEqual(jac[i][j], numeric, 1e-9) {
+				t.Errorf("Derivative of output %d w.r.t. input %d is %v, expected %v from finite differences", i, j, jac[i][j], numeric)
+			}
+		}
+	}
+}
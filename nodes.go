@@ -3,6 +3,7 @@ package badstudent
 import (
 	"github.com/sharnoff/tensors"
 	"fmt"
+	"math"
 )
 
 // String offers a universal method of gaining information about a Node without printing all of its
@@ -35,6 +36,27 @@ func (n *Node) Name() string {
 	return n.name
 }
 
+// key returns the Node's name, or the result of String if it has none. This is used to identify
+// Nodes in maps given by the Network (e.g. UpdateNorms).
+func (n *Node) key() string {
+	if n.name == "" {
+		return n.String()
+	}
+
+	return n.name
+}
+
+// OperatorType returns the TypeString of the Node's Operator, which is the same identifier that is
+// used to save and load it. Input Nodes and placeholders do not have Operators; for those,
+// OperatorType returns an empty string.
+func (n *Node) OperatorType() string {
+	if n.op == nil {
+		return ""
+	}
+
+	return n.op.TypeString()
+}
+
 // ID returns the non-negative integer given to the Node as a member of its Network. IDs are unique
 // within Networks.
 func (n *Node) ID() int {
@@ -69,6 +91,39 @@ func (n *Node) HasDelay() bool {
 	return n.Delay() != 0
 }
 
+// IsTrainable returns whether or not the weights of the Node will be changed by training, as set
+// by SetTrainable. Nodes without weights are always considered trainable.
+func (n *Node) IsTrainable() bool {
+	return !n.frozen
+}
+
+// Workers returns the maximum number of goroutines that should be used for calculations involving
+// the Node, as given by the Network's SetWorkers. Operators and Optimizers that split their work
+// between goroutines should use this as the limit.
+func (n *Node) Workers() int {
+	return n.host.Workers()
+}
+
+// IsTraining returns whether or not the Network is currently being trained, as opposed to being
+// tested or used otherwise. Operators that should only have an effect during training (like
+// Dropout) can use this to determine their behavior.
+func (n *Node) IsTraining() bool {
+	return n.host.training
+}
+
+// IsInferring returns whether or not the Node is being evaluated by (*Network).Infer. Because Infer
+// can be called from many goroutines at once, Operators must not change any of their own state
+// while it is true, such as values that are kept for backpropagation.
+func (n *Node) IsInferring() bool {
+	return n.inferring
+}
+
+// Epoch returns the number of epochs that have finished during the current call to Train. It is
+// only counted if the training data is Epochal, and will otherwise remain zero.
+func (n *Node) Epoch() int {
+	return n.host.epoch
+}
+
 // Size returns the number of values the Node produces.
 func (n *Node) Size() int {
 	return n.values.Size()
@@ -89,9 +144,12 @@ func (n *Node) Shape() tensors.Tensor {
 	return n.values
 }
 
-// HP returns the values of the given HyperParameter at the current iteration. If an unknown
+// HP returns the values of the given HyperParameter at the current iteration, counted across all
+// calls to Train, or at the current epoch if it is an EpochHyperParameter. If an unknown
 // HyperParameter is requested, HP will panic with ErrNoHP. This should only happen with custom
 // Optimizer types, which can be solved by proper usage of Optimizer.Needs().
+//
+// The value of "learning-rate" is multiplied by the Node's scale, as set by SetLearningRateScale.
 func (n *Node) HP(name string) float64 {
 	var hp HyperParameter
 	if hp = n.hyperParams[name]; hp == nil {
@@ -100,7 +158,24 @@ func (n *Node) HP(name string) float64 {
 		}
 	}
 
-	return hp.Value(n.host.longIter)
+	var v float64
+	if e, ok := hp.(EpochHyperParameter); ok {
+		v = e.EpochValue(n.host.epoch)
+	} else {
+		v = hp.Value(n.host.longIter + n.host.iter)
+	}
+
+	if name == "learning-rate" {
+		return n.lrScale * v
+	}
+
+	return v
+}
+
+// LearningRateScale returns the multiplier for the Node's learning rate, as set by
+// SetLearningRateScale.
+func (n *Node) LearningRateScale() float64 {
+	return n.lrScale
 }
 
 // Value returns the value of the Node at the specified (single-dimensional) index. Value will
@@ -109,6 +184,30 @@ func (n *Node) Value(index int) float64 {
 	return n.values.Values[index]
 }
 
+// Values returns a copy of the values of the Node from the last time the Network was evaluated,
+// which can be used to inspect intermediate results. Values has the same error conditions as
+// (*Network).CurrentOutputs: ErrNetNotFinalized and ErrNetNotEvaluated.
+func (n *Node) Values() ([]float64, error) {
+	var err error
+	if n.host.stat < finalized {
+		err = ErrNetNotFinalized
+	} else if n.host.stat < evaluated {
+		err = ErrNetNotEvaluated
+	}
+
+	if err != nil {
+		if n.host.panicErrors {
+			panic(err)
+		}
+
+		return nil, err
+	}
+
+	vs := make([]float64, n.Size())
+	copy(vs, n.values.Values)
+	return vs, nil
+}
+
 // PointValue returns the value corresponding to the given point. The point must be valid by the
 // dimensions of the Node, else tensors.Tensor.PointValue() will panic. More information about
 // possible errors can be found in the documentation for tensors.Interpreter.CheckPoint().
@@ -180,3 +279,50 @@ func (n *Node) NumInputs() int {
 func (n *Node) AllInputs() []float64 {
 	return n.inputs.getValues(true)
 }
+
+// NumWeights returns the number of weights that the Node has -- the length of the weights given by
+// its Operator, if it is Adjustable. Otherwise, NumWeights returns 0. These are the same weights
+// that are included in (*Network).Weights and SetWeights.
+func (n *Node) NumWeights() int {
+	if n.adj == nil {
+		return 0
+	}
+
+	defer n.releaseWeights()
+	return len(n.adj.Weights())
+}
+
+// WeightStats gives some basic statistics about the weights of the Node: their minimum, maximum,
+// mean, and standard deviation. This can be used to diagnose vanishing or exploding weights. If
+// the Node has no weights (i.e. its Operator is not Adjustable), WeightStats returns ErrNoWeights.
+func (n *Node) WeightStats() (min, max, mean, sd float64, err error) {
+	if n.adj == nil {
+		return 0, 0, 0, 0, ErrNoWeights
+	}
+
+	defer n.releaseWeights()
+	return weightStats(n.adj.Weights())
+}
+
+// weightStats returns the minimum, maximum, mean, and standard deviation of the given weights, or
+// ErrNoWeights if there are none.
+func weightStats(ws []float64) (min, max, mean, sd float64, err error) {
+	if len(ws) == 0 {
+		return 0, 0, 0, 0, ErrNoWeights
+	}
+
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, w := range ws {
+		min = math.Min(min, w)
+		max = math.Max(max, w)
+		mean += w
+	}
+	mean /= float64(len(ws))
+
+	for _, w := range ws {
+		sd += (w - mean) * (w - mean)
+	}
+	sd = math.Sqrt(sd / float64(len(ws)))
+
+	return min, max, mean, sd, nil
+}
@@ -0,0 +1,202 @@
+package badstudent_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestWeightStats(t *testing.T) {
+	const inputs, size = 100, 50
+
+	bs.Seed(1)
+	net := new(bs.Network)
+	hidden := net.Add(operators.Neurons(size), net.AddInput([]int{inputs})).Opt(optimizers.SGD()).Init(initializers.He())
+	out := net.Add(operators.Logistic(), hidden)
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	// He initialization gives a normal distribution with standard deviation √(2 / inputs),
+	// truncated at two standard deviations. The biases are all zero.
+	σ := math.Sqrt(2.0 / inputs)
+
+	min, max, mean, sd, err := hidden.WeightStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if min < -2*σ || max > 2*σ || min > -σ || max < σ {
+		t.Errorf("Weights range from %v to %v, expected to be within ±%v", min, max, 2*σ)
+	}
+
+	if math.Abs(mean) > 0.01 {
+		t.Errorf("Mean of the weights is %v, expected near zero", mean)
+	}
+
+	// truncation reduces the standard deviation to about 0.88σ
+	if sd < 0.8*σ || sd > σ {
+		t.Errorf("Standard deviation of the weights is %v, expected about %v", sd, 0.88*σ)
+	}
+
+	if _, _, _, _, err := out.WeightStats(); err != bs.ErrNoWeights {
+		t.Errorf("Node without weights gave error %v, expected ErrNoWeights", err)
+	}
+
+	// the only weights in the Network are those of the hidden Node
+	netMin, netMax, netMean, netSD, err := net.WeightStats()
+	if err != nil {
+		t.Fatal(err)
+	} else if netMin != min || netMax != max || netMean != mean || netSD != sd {
+		t.Errorf("Network gave statistics (%v, %v, %v, %v), expected (%v, %v, %v, %v)",
+			netMin, netMax, netMean, netSD, min, max, mean, sd)
+	}
+}
+
+func TestValues(t *testing.T) {
+	net := new(bs.Network)
+	hidden := net.Add(operators.Logistic(), net.AddInput([]int{2}))
+	out := net.Add(operators.Neurons(1), hidden).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := hidden.Values(); err != bs.ErrNetNotEvaluated {
+		t.Errorf("Values before evaluation gave error %v, expected ErrNetNotEvaluated", err)
+	}
+
+	logistic := func(x float64) float64 { return 1 / (1 + math.Exp(-x)) }
+
+	for _, ins := range [][]float64{{0, 1}, {-2, 3}} {
+		if _, err := net.GetOutputs(ins); err != nil {
+			t.Fatal(err)
+		}
+
+		vs, err := hidden.Values()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []float64{logistic(ins[0]), logistic(ins[1])}
+		if !sliceEqual(vs, expected, 1e-12) {
+			t.Errorf("Values after inputs %v are %v, expected %v", ins, vs, expected)
+		}
+
+		// changing the returned slice must not affect the Node
+		vs[0] = 100
+		if again, _ := hidden.Values(); again[0] == 100 {
+			t.Errorf("Changing the result of Values changed the values of the Node")
+		}
+	}
+}
+
+func TestBiasInit(t *testing.T) {
+	build := func(biasInit bs.Initializer) (*bs.Network, *bs.Node, bs.Biased) {
+		bs.Seed(2)
+		op := operators.Neurons(3)
+
+		net := new(bs.Network)
+		n := net.Add(op, net.AddInput([]int{4})).Opt(optimizers.SGD()).Init(initializers.Xavier())
+		if biasInit != nil {
+			n.BiasInit(biasInit)
+		}
+
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := net.Finalize(costfuncs.MSE(), n); err != nil {
+			t.Fatal(err)
+		}
+
+		return net, n, op
+	}
+
+	tests := []struct {
+		init bs.Initializer
+		bias float64
+	}{
+		// without BiasInit, biases are zero
+		{nil, 0},
+		{initializers.Constant(0.1), 0.1},
+	}
+
+	for _, test := range tests {
+		net, n, op := build(test.init)
+		bias := test.bias
+
+		var biases int
+		for i, w := range net.Weights() {
+			if op.IsBias(n, i) {
+				biases++
+				if w != bias {
+					t.Errorf("Bias at index %d was initialized to %v, expected %v", i, w, bias)
+				}
+			} else if w == 0 || w == bias {
+				t.Errorf("Weight at index %d was initialized to %v, expected a random value", i, w)
+			}
+		}
+
+		if biases != 3 {
+			t.Errorf("Found %d biases, expected 3", biases)
+		}
+	}
+}
+
+func TestNumWeights(t *testing.T) {
+	net := xorNet(t, 1)
+
+	// 2 inputs and a bias for each of 3 hidden Neurons, then 3 inputs and a bias for the output
+	expected := map[string]int{
+		"input":           0,
+		"hidden neurons":  9,
+		"hidden logistic": 0,
+		"output neurons":  4,
+		"output logistic": 0,
+	}
+
+	var sum int
+	for _, n := range net.Nodes() {
+		if num := n.NumWeights(); num != expected[n.Name()] {
+			t.Errorf("Node %q has %d weights, expected %d", n.Name(), num, expected[n.Name()])
+		}
+
+		sum += n.NumWeights()
+	}
+
+	if len(net.Weights()) != sum {
+		t.Errorf("Network has %d weights, expected the sum over each Node: %d", len(net.Weights()), sum)
+	}
+}
+
+func TestHPIteration(t *testing.T) {
+	net := new(bs.Network)
+	out := net.Add(operators.Neurons(1), net.AddInput([]int{2})).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Step(1).Add(3, 0.5).Add(6, 0.25))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	// the iterations are counted across calls to Train
+	var rates []float64
+	for _, until := range []int{4, 4} {
+		args := xorArgs(t, until)
+		args.SendStatus = func(int) bool { return true }
+		args.Update = func(bs.Result) { rates = append(rates, out.HP("learning-rate")) }
+
+		if err := net.Train(args); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []float64{1, 1, 0.5, 0.5, 0.5, 0.25, 0.25, 0.25}
+	if !sliceEqual(rates, expected, 0) {
+		t.Errorf("Learning rates are %v, expected %v", rates, expected)
+	}
+}
@@ -49,8 +49,8 @@ const default_paramShare bool = false
 // Conv returns a typical convolutional function with weights, available for any number of
 // dimensions, which implements badstudent.Operator.
 //
-// Conv does not return a completed Operator, however. The methods InputDims and Filter must be
-// called in order to provide enough information to Finalize the Operator. Other methods can be
+// Conv does not return a completed Operator, however. The method Filter must be called in order
+// to provide enough information to Finalize the Operator. Other methods can be
 // called to to further customize it -- they return *conv and do not check for errors, so they can
 // be chained.
 func Conv() *conv {
@@ -64,6 +64,17 @@ func Conv() *conv {
 	return c
 }
 
+// Conv1D returns a one-dimensional convolutional Operator, with a single filter of the given size
+// whose parameters are shared across the entire input. The input dimensions are determined from
+// the inputs to the Node, which must have a total size that fits with kernelSize and stride.
+//
+// Conv1D is simply a shortcut for:
+//	Conv().Filter(kernelSize).Stride(stride).ParamSharing(true)
+// and so can be customized further in the same manner as Conv.
+func Conv1D(kernelSize, stride int) *conv {
+	return Conv().Filter(kernelSize).Stride(stride).ParamSharing(true)
+}
+
 // ***************************************************
 // Customization functions
 // ***************************************************
@@ -85,9 +96,10 @@ func (c *conv) Dims(dims ...int) *conv {
 	return c
 }
 
-// InputDims sets the input dimensions of the convolutional Operator, for internal use. This is
-// REQUIRED unless the Operator is being loaded. InputDims will panic if called after the Operator
-// has been Finalized.
+// InputDims sets the input dimensions of the convolutional Operator, for internal use. If not set,
+// the input dimensions are taken from the inputs to the Node: the dimensions of a single input
+// Node, or the total size of multiple. InputDims will panic if called after the Operator has been
+// Finalized.
 func (c *conv) InputDims(dims ...int) *conv {
 	if c.convConstructor == nil {
 		panic("convolutional Operator has already been finalized")
@@ -228,7 +240,7 @@ func (c *conv) Size() (int, error) {
 		return 0, errors.Errorf("Filter has not been set")
 	}
 
-	dimList := [][]int{c.inputDims, c.dims, c.filter, c.Padding}
+	dimList := [][]int{c.inputDims, c.dims, c.filter, c.Str, c.Padding}
 	names := []string{"InputDims", "Dims", "Filter", "Stride", "Padding"} // only used in case of error
 	for i := range dimList {
 		if dimList[i] == nil {
@@ -249,7 +261,7 @@ func (c *conv) Size() (int, error) {
 	}
 
 	if c.Dep < 1 {
-		return 0, errors.Errorf("Depth is < 1 (%d)")
+		return 0, errors.Errorf("Depth is < 1 (%d)", c.Dep)
 	}
 
 	if c.Str == nil {
@@ -266,6 +278,14 @@ func (c *conv) Size() (int, error) {
 		c.Padding = make([]int, len(c.inputDims))
 	}
 
+	// a filter that doesn't fit within the inputs would give no outputs in that dimension
+	for i := range c.inputDims {
+		if c.filter[i] > c.inputDims[i]+2*c.Padding[i] {
+			return 0, errors.Errorf("Filter[%d] is larger than InputDims[%d] with padding (%d > %d + 2*%d)",
+				i, i, c.filter[i], c.inputDims[i], c.Padding[i])
+		}
+	}
+
 	if c.dims != nil { // if everything is filled in, check whether or not it works
 		for i := range c.inputDims {
 			in := c.inputDims[i] + 2*c.Padding[i]
@@ -314,9 +334,9 @@ func (c *conv) isPadding(point []int) bool {
 	copy(p, point)
 	mapSub(p, c.Padding)
 
-	// if any are less than 0, it's padding
+	// if any are less than 0 or past the end of the inputs, it's padding
 	for i := range p {
-		if p[i] < 0 || p[i] >= c.Outs.Dim(i) {
+		if p[i] < 0 || p[i] >= c.Ins.Dim(i) {
 			return true
 		}
 	}
@@ -329,8 +349,11 @@ func (c *conv) inputsTo(out_p []int) []int {
 	// here, underscores are used as a suffix to indicate the type of the variable. For example,
 	// x_i would be an index with name 'x', and x_p would be an n-dimensional point with name 'x'.
 
-	// topleft is the top-left base point for the filter, including padding
-	topLeft_p := mapMult(out_p, c.Str)
+	// topleft is the top-left base point for the filter, including padding. out_p is copied first
+	// because callers continue to use it afterwards.
+	topLeft_p := make([]int, len(out_p))
+	copy(topLeft_p, out_p)
+	mapMult(topLeft_p, c.Str)
 
 	// the list of input indexes to be supplied to c
 	inList := make([]int, c.Filt.Size())
@@ -421,6 +444,16 @@ func (t *conv) Finalize(n *bs.Node) error {
 	return nil
 }
 
+// InputSize returns the total number of inputs that the Operator expects, or -1 if that has not
+// yet been determined. It is the implementation of badstudent.FixedInput.
+func (t *conv) InputSize() int {
+	if t.Ins != nil {
+		return t.Ins.Size()
+	}
+
+	return -1
+}
+
 func (t *conv) Get() interface{} {
 	return *t
 }
@@ -430,6 +463,10 @@ func (t *conv) Blank() interface{} {
 }
 
 func (t *conv) OutputShape(ins []*bs.Node) (tensors.Tensor, error) {
+	if t.convConstructor != nil && t.inputDims == nil {
+		t.inputDims = inputDims(ins)
+	}
+
 	_, err := t.Size()
 	if err != nil {
 		return tensors.Tensor{}, err
@@ -465,8 +502,8 @@ func (t *conv) Evaluate(n *bs.Node, values []float64) {
 		values[v] = sum
 	}
 
-	opsPerThread, threadsPerCPU := 1, 1
-	utils.MultiThread(0, len(values), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 1
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
 }
 
 func (t *conv) InputDeltas(n *bs.Node) []float64 {
@@ -485,8 +522,8 @@ func (t *conv) InputDeltas(n *bs.Node) []float64 {
 		}
 	}
 
-	opsPerThread, threadsPerCPU := 1, 1
-	utils.MultiThread(0, n.Size(), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 1
+	utils.MultiThreadN(0, n.Size(), f, opsPerThread, n.Workers())
 
 	return uint64ToFloat64(atoms)
 }
@@ -497,20 +534,46 @@ func (t *conv) Grad(n *bs.Node, index int) float64 {
 	mod := index % filterSize
 	index /= filterSize
 
-	out := index % t.Outs.Size()
-	depth := index / t.Outs.Size()
+	if !t.ShareParams {
+		out := index % t.Outs.Size()
+		depth := index / t.Outs.Size()
+
+		return t.grad(n, out, mod, depth)
+	}
+
+	// with shared parameters, each weight is used for every output value at its depth, so its
+	// gradient is the sum over all of them
+	depth := index
+
+	var sum float64
+	for out := 0; out < t.Outs.Size(); out++ {
+		sum += t.grad(n, out, mod, depth)
+	}
+
+	return sum
+}
+
+// grad returns the component of the gradient of a weight that is due to a single output value.
+// 'mod' is the index of the weight within its filter.
+func (t *conv) grad(n *bs.Node, out, mod, depth int) float64 {
+	delta := n.Delta(out + depth*t.Outs.Size())
 
 	if mod == t.Filt.Size() { // if it's a bias
-		return t.Bias * n.Delta(out)
-	} else {
-		in_p := mapAdd(mapMult(t.Outs.Point(out), t.Str), t.Filt.Point(mod))
+		return t.Bias * delta
+	}
 
-		if t.isPadding(in_p) {
-			return t.PaddingValue * n.Delta(out+depth*t.Outs.Size())
-		} else {
-			return n.InputValue(t.Ins.Index(mapSub(in_p, t.Padding))) * n.Delta(out+depth*t.Outs.Size())
-		}
+	in_p := mapAdd(mapMult(t.Outs.Point(out), t.Str), t.Filt.Point(mod))
+
+	if t.isPadding(in_p) {
+		return t.PaddingValue * delta
 	}
+
+	return n.InputValue(t.Ins.Index(mapSub(in_p, t.Padding))) * delta
+}
+
+// IsBias is the implementation of badstudent.Biased
+func (t *conv) IsBias(n *bs.Node, index int) bool {
+	return index%(t.Filt.Size()+t.NumBiases) == t.Filt.Size()
 }
 
 func (t *conv) Weights() []float64 {
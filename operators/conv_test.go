@@ -0,0 +1,91 @@
+package operators_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestConv1D(t *testing.T) {
+	tests := []struct {
+		op     bs.Operator
+		inputs []float64
+		// the shared filter, followed by the bias
+		weights []float64
+		outs    []float64
+		// the deltas of the outputs, and the resulting deltas of the inputs
+		deltas, inDeltas []float64
+	}{
+		{
+			operators.Conv1D(3, 1).BiasValue(1),
+			[]float64{1, 2, 3, 4, 5},
+			[]float64{1, 2, -1, 0.5},
+			[]float64{2.5, 4.5, 6.5},
+			[]float64{1, -2, 0.5},
+			[]float64{1, 0, -4.5, 3, -0.5},
+		},
+		{
+			operators.Conv1D(2, 2).NoBiases(),
+			[]float64{1, 2, 3, 4},
+			[]float64{1, -1},
+			[]float64{-1, -1},
+			[]float64{3, -2},
+			[]float64{3, -3, -2, 2},
+		},
+	}
+
+	for i, test := range tests {
+		net := dense(t, test.op, len(test.inputs))
+		net.SetReduction(bs.ReduceSum)
+		if err := net.SetWeights(test.weights); err != nil {
+			t.Fatalf("Test %d: %v", i, err)
+		}
+
+		outs, err := net.GetOutputs(test.inputs)
+		if err != nil {
+			t.Fatal(err)
+		} else if !equal(outs, test.outs) {
+			t.Errorf("Test %d: outputs are %v, expected %v", i, outs, test.outs)
+			continue
+		}
+
+		// with a squared error cost, the delta of each output is (out - target)
+		targets := make([]float64, len(outs))
+		for i := range targets {
+			targets[i] = outs[i] - test.deltas[i]
+		}
+
+		ds, err := net.InputGradients(test.inputs, targets, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if !equal(ds, test.inDeltas) {
+			t.Errorf("Test %d: input deltas are %v, expected %v", i, ds, test.inDeltas)
+		}
+	}
+}
+
+func TestConv1DSizing(t *testing.T) {
+	invalid := []struct{ kernel, stride, inputs int }{
+		{5, 1, 4}, // kernel larger than inputs
+		{2, 2, 5}, // doesn't divide evenly
+		{2, 3, 8}, // stride larger than kernel
+	}
+
+	for _, test := range invalid {
+		net := new(bs.Network)
+		n := net.Add(operators.Conv1D(test.kernel, test.stride), net.AddInput([]int{test.inputs}))
+
+		if n != nil || net.Error() == nil {
+			t.Errorf("Conv1D(%d, %d) with %d inputs was added without error", test.kernel, test.stride, test.inputs)
+		} else if _, ok := net.Error().(bs.GetShapeError); !ok {
+			t.Errorf("Conv1D(%d, %d) with %d inputs gave %T, expected GetShapeError", test.kernel, test.stride, test.inputs, net.Error())
+		}
+	}
+}
+
+func TestConv1DGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.Conv1D(3, 1), 8)
+	testutil.AssertOperatorGradient(t, operators.Conv1D(2, 2), 8)
+}
@@ -0,0 +1,148 @@
+package operators
+
+import (
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/tensors"
+)
+
+type dropout struct {
+	Rate float64
+
+	// optional; gives the rate for each epoch instead of Rate
+	schedule func(int) float64
+
+	// whether or not each value was kept in the last evaluation. If nil, all of the values were
+	// kept, unscaled.
+	kept []bool
+
+	// the multiplier for kept values in the last evaluation: 1 / (1 - rate)
+	scale float64
+}
+
+// Dropout returns an operator that randomly sets values from its inputs to zero while training,
+// each with probability 'rate'. The remaining values are scaled by 1 / (1 - rate), so that the
+// expected value of each output is unchanged. When the Network is not being trained (e.g. during
+// testing), Dropout has no effect.
+//
+// 'rate' must be in the range [0, 1). Multiple inputs are concatenated, in order. Dropout uses
+// badstudent.Rand() to determine which values are dropped.
+func Dropout(rate float64) *dropout {
+	return &dropout{Rate: rate}
+}
+
+// Schedule sets a function to give the rate of Dropout for each epoch of training, instead of the
+// constant rate given to Dropout, and returns the same operator. Epochs are given by
+// (*badstudent.Node).Epoch(), starting from zero. Rates outside of [0, 1) are clamped to that range.
+// This can be used to increase the rate over the first few epochs of training; see LinearWarmup.
+//
+// Schedules cannot be saved, so the operator will use its constant rate once loaded.
+func (t *dropout) Schedule(f func(epoch int) float64) *dropout {
+	t.schedule = f
+	return t
+}
+
+// LinearWarmup returns a schedule for Dropout that increases linearly from zero to 'rate' over the
+// first 'epochs' epochs, after which it stays at 'rate'.
+func LinearWarmup(rate float64, epochs int) func(int) float64 {
+	return func(epoch int) float64 {
+		if epoch >= epochs {
+			return rate
+		}
+
+		return rate * float64(epoch) / float64(epochs)
+	}
+}
+
+// rate returns the current rate of dropout for the Node, which is always zero if the Network is
+// not being trained
+func (t *dropout) rate(n *bs.Node) float64 {
+	if !n.IsTraining() {
+		return 0
+	} else if t.schedule == nil {
+		return t.Rate
+	}
+
+	r := t.schedule(n.Epoch())
+	if r < 0 {
+		return 0
+	} else if r >= 1 {
+		// not quite 1, so that the scale is still finite
+		return 1 - 1e-9
+	}
+
+	return r
+}
+
+func (t *dropout) TypeString() string {
+	return "dropout"
+}
+
+func (t *dropout) Finalize(n *bs.Node) error {
+	if t.Rate < 0 || t.Rate >= 1 {
+		return errors.Errorf("Rate must be in the range [0, 1) (%v)", t.Rate)
+	}
+
+	return nil
+}
+
+func (t *dropout) Get() interface{} {
+	return t.Rate
+}
+
+func (t *dropout) Blank() interface{} {
+	return &t.Rate
+}
+
+func (t *dropout) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	ls := make([]tensors.Tensor, len(inputs))
+	for i := range ls {
+		ls[i] = inputs[i].Shape()
+	}
+
+	return bs.ConcatShape(ls)
+}
+
+func (t *dropout) Evaluate(n *bs.Node, values []float64) {
+	inputs := n.AllInputs()
+
+	rate := t.rate(n)
+	if rate == 0 {
+		if !n.IsInferring() {
+			t.kept = nil
+		}
+
+		copy(values, inputs)
+		return
+	}
+
+	if len(t.kept) != len(values) {
+		t.kept = make([]bool, len(values))
+	}
+
+	t.scale = 1 / (1 - rate)
+	rand := bs.Rand()
+
+	for i := range values {
+		t.kept[i] = rand.Float64() >= rate
+
+		if t.kept[i] {
+			values[i] = inputs[i] * t.scale
+		} else {
+			values[i] = 0
+		}
+	}
+}
+
+func (t *dropout) InputDeltas(n *bs.Node) []float64 {
+	ds := make([]float64, n.Size())
+	for i := range ds {
+		if t.kept == nil {
+			ds[i] = n.Delta(i)
+		} else if t.kept[i] {
+			ds[i] = n.Delta(i) * t.scale
+		}
+	}
+
+	return ds
+}
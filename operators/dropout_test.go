@@ -0,0 +1,151 @@
+package operators_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/tensors"
+)
+
+// dropRecorder is an identity Operator that records the fraction of its inputs that are zero,
+// for each epoch of training
+type dropRecorder struct {
+	// the number of zero values and the total number of values, for each epoch
+	zeros, totals map[int]int
+
+	// which of the values were zero, for each evaluation during training
+	masks [][]bool
+
+	// the fraction of zero values from the last evaluation outside of training
+	testZeros float64
+}
+
+func (r *dropRecorder) TypeString() string        { return "drop-recorder" }
+func (r *dropRecorder) Finalize(n *bs.Node) error { return nil }
+
+func (r *dropRecorder) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	return inputs[0].Shape(), nil
+}
+
+func (r *dropRecorder) Evaluate(n *bs.Node, values []float64) {
+	var zeros int
+	mask := make([]bool, len(values))
+	for i, v := range n.AllInputs() {
+		values[i] = v
+		if v == 0 {
+			zeros++
+			mask[i] = true
+		}
+	}
+
+	if n.IsTraining() {
+		r.zeros[n.Epoch()] += zeros
+		r.totals[n.Epoch()] += len(values)
+		r.masks = append(r.masks, mask)
+	} else {
+		r.testZeros = float64(zeros) / float64(len(values))
+	}
+}
+
+func (r *dropRecorder) InputDeltas(n *bs.Node) []float64 {
+	ds := make([]float64, n.Size())
+	for i := range ds {
+		ds[i] = n.Delta(i)
+	}
+
+	return ds
+}
+
+func TestDropoutSchedule(t *testing.T) {
+	const size, epochs = 2000, 6
+
+	bs.Seed(3)
+	rec := &dropRecorder{zeros: make(map[int]int), totals: make(map[int]int)}
+	schedule := operators.LinearWarmup(0.5, 4)
+
+	net := new(bs.Network)
+	l := net.Add(operators.Dropout(0.5).Schedule(schedule), net.AddInput([]int{size}))
+	l = net.Add(rec, l)
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	ones := make([]float64, size)
+	for i := range ones {
+		ones[i] = 1
+	}
+
+	data, err := bs.FromSource(bs.SliceSource([][][]float64{{ones, ones}, {ones, ones}}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := net.Train(bs.TrainArgs{TrainData: data, RunCondition: bs.TrainUntil(2 * epochs)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		if rec.totals[epoch] == 0 {
+			t.Errorf("No values were recorded for epoch %d", epoch)
+			continue
+		}
+
+		frac := float64(rec.zeros[epoch]) / float64(rec.totals[epoch])
+		if expected := schedule(epoch); math.Abs(frac-expected) > 0.04 {
+			t.Errorf("Fraction dropped in epoch %d was %v, expected about %v", epoch, frac, expected)
+		}
+	}
+
+	// outside of training, nothing is dropped
+	if _, err := net.GetOutputs(ones); err != nil {
+		t.Fatal(err)
+	} else if rec.testZeros != 0 {
+		t.Errorf("Fraction dropped outside of training was %v, expected 0", rec.testZeros)
+	}
+}
+
+func TestDropoutRepeatedInputs(t *testing.T) {
+	const size = 100
+
+	bs.Seed(5)
+	rec := &dropRecorder{zeros: make(map[int]int), totals: make(map[int]int)}
+
+	net := new(bs.Network)
+	l := net.Add(operators.Dropout(0.5), net.AddInput([]int{size}))
+	l = net.Add(rec, l)
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	ones := make([]float64, size)
+	for i := range ones {
+		ones[i] = 1
+	}
+
+	// both samples are in the same batch, so the changes from the first are saved, not applied,
+	// and the Network is otherwise unchanged before the second
+	data, err := bs.FromSource(bs.SliceSource([][][]float64{{ones, ones}, {ones, ones}}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := net.Train(bs.TrainArgs{TrainData: data, RunCondition: bs.TrainUntil(2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.masks) != 2 {
+		t.Fatalf("Evaluated %d times during training, expected 2", len(rec.masks))
+	}
+
+	same := true
+	for i := range rec.masks[0] {
+		same = same && rec.masks[0][i] == rec.masks[1][i]
+	}
+
+	if same {
+		t.Errorf("The same values were dropped for both samples")
+	}
+}
@@ -0,0 +1,100 @@
+package operators
+
+import (
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/tensors"
+	"math"
+)
+
+type embedding struct {
+	Vocab, Dim int
+
+	// Ws is organized as 'Vocab' rows, each of length 'Dim'
+	Ws []float64
+}
+
+// Embedding returns an Operator that looks up a learned vector of size 'dim' for each of 'vocab'
+// categories, avoiding the need for one-hot inputs. The single input value is the index of the
+// category, rounded to the nearest integer; if it is out of range, the output is zero. Only the
+// row of weights that was selected is given a gradient, and no deltas are passed back to the input.
+//
+// Both vocab and dim must be at least one, and the Node must have exactly one input value;
+// otherwise, it will fail to be added.
+func Embedding(vocab, dim int) *embedding {
+	return &embedding{Vocab: vocab, Dim: dim}
+}
+
+func (t *embedding) TypeString() string {
+	return "embedding"
+}
+
+func (t *embedding) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	if t.Vocab < 1 || t.Dim < 1 {
+		return tensors.Tensor{}, errors.Errorf("Vocab and Dim must be ≥ 1 (%d, %d)", t.Vocab, t.Dim)
+	}
+
+	return tensors.NewTensor([]int{t.Dim}), nil
+}
+
+func (t *embedding) InputSize() int {
+	return 1
+}
+
+func (t *embedding) Finalize(n *bs.Node) error {
+	// if it's been loaded from a file...
+	if len(t.Ws) != 0 {
+		return nil
+	}
+
+	t.Ws = make([]float64, t.Vocab*t.Dim)
+	return nil
+}
+
+func (t *embedding) Get() interface{} {
+	return *t
+}
+
+func (t *embedding) Blank() interface{} {
+	return t
+}
+
+// row returns the index of the row selected by the Node's input, or -1 if it is out of range
+func (t *embedding) row(n *bs.Node) int {
+	r := math.Round(n.InputValue(0))
+	if !(r >= 0 && r < float64(t.Vocab)) {
+		return -1
+	}
+
+	return int(r)
+}
+
+func (t *embedding) Evaluate(n *bs.Node, values []float64) {
+	r := t.row(n)
+	if r < 0 {
+		for i := range values {
+			values[i] = 0
+		}
+
+		return
+	}
+
+	copy(values, t.Ws[r*t.Dim:(r+1)*t.Dim])
+}
+
+func (t *embedding) InputDeltas(n *bs.Node) []float64 {
+	// the input is an index, so it has no meaningful derivative
+	return make([]float64, 1)
+}
+
+func (t *embedding) Grad(n *bs.Node, index int) float64 {
+	if index/t.Dim != t.row(n) {
+		return 0
+	}
+
+	return n.Delta(index % t.Dim)
+}
+
+func (t *embedding) Weights() []float64 {
+	return t.Ws
+}
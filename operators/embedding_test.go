@@ -0,0 +1,54 @@
+package operators_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestEmbedding(t *testing.T) {
+	const vocab, dim, index = 4, 2, 2
+
+	net := new(bs.Network)
+	out := net.Add(operators.Embedding(vocab, dim), net.AddInput([]int{1})).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	// every sample uses the same index, so only that row should be trained
+	dataset := make([][][]float64, 10)
+	for i := range dataset {
+		dataset[i] = [][]float64{{index}, {1, -1}}
+	}
+
+	before := append([]float64(nil), net.Weights()...)
+	if err := net.Train(bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(len(dataset))}); err != nil {
+		t.Fatal(err)
+	}
+
+	after := net.Weights()
+	for r := 0; r < vocab; r++ {
+		row, prev := after[r*dim:(r+1)*dim], before[r*dim:(r+1)*dim]
+		if changed := !equal(row, prev); changed != (r == index) {
+			t.Errorf("Row %d was changed: %v (from %v to %v)", r, changed, prev, row)
+		}
+	}
+
+	// the selected row is given as the output, and out-of-range indices give zeros
+	if outs, err := net.GetOutputs([]float64{index}); err != nil {
+		t.Fatal(err)
+	} else if !equal(outs, after[index*dim:(index+1)*dim]) {
+		t.Errorf("Output for index %d is %v, expected row %v", index, outs, after[index*dim:(index+1)*dim])
+	}
+
+	if outs, err := net.GetOutputs([]float64{vocab}); err != nil {
+		t.Fatal(err)
+	} else if !equal(outs, make([]float64, dim)) {
+		t.Errorf("Output for out-of-range index %d is %v, expected zeros", vocab, outs)
+	}
+}
@@ -0,0 +1,21 @@
+package operators_test
+
+import (
+	"math"
+)
+
+// equal returns whether or not each value in 'a' is equal to the value in 'b' at the same index,
+// to within rounding error
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-12 {
+			return false
+		}
+	}
+
+	return true
+}
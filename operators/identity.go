@@ -6,7 +6,10 @@ import (
 
 type identity int8
 
-// Identity returns an operator that returns its inputs
+// Identity returns an operator that returns its inputs. Deltas are passed back to the inputs
+// unchanged, and there are no weights. Because it is Elementwise, its size is always equal to that
+// of its inputs. Identity can be useful for combining multiple Nodes into one, or as a stand-in
+// while testing the structure of a Network.
 func Identity() identity {
 	return identity(0)
 }
@@ -26,3 +29,38 @@ func (t identity) Value(v float64, index int) float64 {
 func (t identity) Deriv(n *bs.Node, index int) float64 {
 	return 1
 }
+
+type scale float64
+
+// Scale returns an operator that multiplies each of its inputs by a constant factor. Deltas are
+// passed back to the inputs scaled by the same factor, and there are no weights. Because it is
+// Elementwise, its size is always equal to that of its inputs. Scale can be useful for
+// preprocessing inputs, or for changing the size of the gradients that reach earlier Nodes.
+func Scale(factor float64) *scale {
+	s := scale(factor)
+	return &s
+}
+
+func (t *scale) TypeString() string {
+	return "scale"
+}
+
+func (t *scale) Finalize(n *bs.Node) error {
+	return nil
+}
+
+func (t *scale) Get() interface{} {
+	return *t
+}
+
+func (t *scale) Blank() interface{} {
+	return t
+}
+
+func (t *scale) Value(v float64, index int) float64 {
+	return float64(*t) * v
+}
+
+func (t *scale) Deriv(n *bs.Node, index int) float64 {
+	return float64(*t)
+}
@@ -0,0 +1,83 @@
+package operators_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestStackedIdentity(t *testing.T) {
+	net := new(bs.Network).HandleErrors()
+	l := net.AddInput([]int{4})
+	for i := 0; i < 5; i++ {
+		l = net.Add(operators.Identity(), l)
+	}
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{1.5, -2, 0, 1e10}
+	if outs, err := net.GetOutputs(inputs); err != nil {
+		t.Fatal(err)
+	} else if !equal(outs, inputs) {
+		t.Errorf("Outputs are %v, expected the inputs %v", outs, inputs)
+	}
+
+	// the deltas of the inputs are exactly those of the outputs
+	deltas := []float64{1, -0.5, 3, 0}
+	targets := make([]float64, len(inputs))
+	for i := range targets {
+		targets[i] = inputs[i] - deltas[i]
+	}
+
+	if ds, err := net.InputGradients(inputs, targets, nil); err != nil {
+		t.Fatal(err)
+	} else if !equal(ds, deltas) {
+		t.Errorf("Input deltas are %v, expected %v", ds, deltas)
+	}
+}
+
+func TestScale(t *testing.T) {
+	const factor = -2.5
+
+	net := new(bs.Network).HandleErrors()
+	l := net.Add(operators.Scale(factor), net.AddInput([]int{3}))
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{1, -0.4, 0}
+	outs, err := net.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	} else if expected := []float64{-2.5, 1, 0}; !equal(outs, expected) {
+		t.Errorf("Outputs are %v, expected %v", outs, expected)
+	}
+
+	// the deltas of the inputs are those of the outputs, scaled by the same factor
+	deltas := []float64{1, -0.5, 2}
+	targets := make([]float64, len(outs))
+	for i := range targets {
+		targets[i] = outs[i] - deltas[i]
+	}
+
+	if ds, err := net.InputGradients(inputs, targets, nil); err != nil {
+		t.Fatal(err)
+	} else if expected := []float64{-2.5, 1.25, -5}; !equal(ds, expected) {
+		t.Errorf("Input deltas are %v, expected %v", ds, expected)
+	}
+}
+
+func TestScaleGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.Scale(-2.5), 4)
+}
@@ -0,0 +1,125 @@
+package operators
+
+import (
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+	"math"
+)
+
+// ****************************************
+// Impute
+// ****************************************
+
+type impute struct {
+	// the value for each missing input. If nil, missing inputs are replaced with zero.
+	Fill []float64
+}
+
+// Impute returns an operator that replaces missing values from its inputs -- given as NaN -- with
+// zero. Other values are passed through unchanged. Impute is intended to be placed directly after
+// an input Node, so that datasets with missing features can be given to the Network as they are.
+//
+// The derivative w.r.t. a missing input is zero. Multiple inputs are concatenated, in order. For
+// other fill values, see ImputeMean and ImputeLearned.
+func Impute() *impute {
+	return &impute{}
+}
+
+// ImputeMean returns a version of Impute that replaces each missing value with the corresponding
+// value in 'means' -- typically the mean of that feature over the dataset. The number of values
+// given must be equal to the size of the Node.
+func ImputeMean(means []float64) *impute {
+	return &impute{Fill: means}
+}
+
+func (t *impute) TypeString() string {
+	return "impute"
+}
+
+func (t *impute) Finalize(n *bs.Node) error {
+	if t.Fill != nil && len(t.Fill) != n.Size() {
+		return errors.Errorf("Number of fill values not equal to size of Node (%d != %d)", len(t.Fill), n.Size())
+	}
+
+	return nil
+}
+
+func (t *impute) Get() interface{} {
+	return *t
+}
+
+func (t *impute) Blank() interface{} {
+	return t
+}
+
+func (t *impute) Value(in float64, index int) float64 {
+	if !math.IsNaN(in) {
+		return in
+	} else if t.Fill == nil {
+		return 0
+	}
+
+	return t.Fill[index]
+}
+
+func (t *impute) Deriv(n *bs.Node, index int) float64 {
+	if math.IsNaN(n.InputValue(index)) {
+		return 0
+	}
+
+	return 1
+}
+
+// ****************************************
+// Learned Impute
+// ****************************************
+
+type learnedImpute struct {
+	impute
+}
+
+// ImputeLearned returns a version of Impute where the value used for each missing input is a
+// weight, learned during training. Because the weights are counted as biases, they are initialized
+// to zero (unless another value is given by (*badstudent.Node).BiasInit) and are not penalized.
+func ImputeLearned() *learnedImpute {
+	return &learnedImpute{}
+}
+
+func (t *learnedImpute) TypeString() string {
+	return "impute-learned"
+}
+
+func (t *learnedImpute) Finalize(n *bs.Node) error {
+	// if it's been loaded from a file...
+	if len(t.Fill) != 0 {
+		return t.impute.Finalize(n)
+	}
+
+	t.Fill = make([]float64, n.Size())
+	return nil
+}
+
+func (t *learnedImpute) Get() interface{} {
+	return *t
+}
+
+func (t *learnedImpute) Blank() interface{} {
+	return t
+}
+
+func (t *learnedImpute) Weights() []float64 {
+	return t.Fill
+}
+
+func (t *learnedImpute) Grad(n *bs.Node, index int) float64 {
+	if math.IsNaN(n.InputValue(index)) {
+		return n.Delta(index)
+	}
+
+	return 0
+}
+
+// IsBias is the implementation of badstudent.Biased
+func (t *learnedImpute) IsBias(n *bs.Node, index int) bool {
+	return true
+}
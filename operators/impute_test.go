@@ -0,0 +1,56 @@
+package operators_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestImpute(t *testing.T) {
+	nan := math.NaN()
+
+	tests := []struct {
+		op       bs.Operator
+		expected []float64
+	}{
+		{operators.Impute(), []float64{0.5, 0, -1}},
+		{operators.ImputeMean([]float64{10, 20, 30}), []float64{0.5, 20, -1}},
+	}
+
+	for _, test := range tests {
+		net := new(bs.Network)
+		imp := net.Add(test.op, net.AddInput([]int{3}))
+		out := net.Add(operators.Neurons(1), imp).Opt(optimizers.SGD())
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+			t.Fatal(err)
+		}
+
+		outs, err := net.GetOutputs([]float64{0.5, nan, -1})
+		if err != nil {
+			t.Fatal(err)
+		} else if math.IsNaN(outs[0]) {
+			t.Errorf("%s: output with a missing input is NaN", imp.OperatorType())
+		}
+
+		if vs, err := imp.Values(); err != nil {
+			t.Fatal(err)
+		} else if !equal(vs, test.expected) {
+			t.Errorf("%s: values are %v, expected %v", imp.OperatorType(), vs, test.expected)
+		}
+	}
+}
+
+func TestImputeMeanSize(t *testing.T) {
+	net := new(bs.Network)
+	if n := net.Add(operators.ImputeMean([]float64{1, 2}), net.AddInput([]int{3})); n != nil {
+		t.Errorf("ImputeMean with 2 values for 3 inputs was added without error")
+	} else if _, ok := net.Error().(bs.OperatorFinalizeError); !ok {
+		t.Errorf("ImputeMean with 2 values for 3 inputs gave error %v, expected type OperatorFinalizeError", net.Error())
+	}
+}
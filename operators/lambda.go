@@ -0,0 +1,42 @@
+package operators
+
+import (
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+)
+
+type lambda struct {
+	forward, backward func(float64) float64
+}
+
+// Lambda returns an operator that applies the given function to each of its inputs, where
+// 'backward' gives the derivative of 'forward' at each input value. There are no weights, and
+// because it is Elementwise, its size is always equal to that of its inputs. Lambda allows new
+// activation functions to be tried quickly, without writing a full Operator. If either function is
+// nil, the Node will fail to be added.
+//
+// Because the functions cannot be saved, Lambda is not registered, and Networks using it cannot be
+// loaded after saving.
+func Lambda(forward, backward func(float64) float64) *lambda {
+	return &lambda{forward, backward}
+}
+
+func (t *lambda) TypeString() string {
+	return "lambda"
+}
+
+func (t *lambda) Finalize(n *bs.Node) error {
+	if t.forward == nil || t.backward == nil {
+		return errors.Errorf("Lambda functions must not be nil")
+	}
+
+	return nil
+}
+
+func (t *lambda) Value(in float64, index int) float64 {
+	return t.forward(in)
+}
+
+func (t *lambda) Deriv(n *bs.Node, index int) float64 {
+	return t.backward(n.InputValue(index))
+}
@@ -0,0 +1,41 @@
+package operators_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// sigmoidLambda returns an implementation of Logistic with Lambda
+func sigmoidLambda() bs.Operator {
+	return operators.Lambda(sigmoid, func(x float64) float64 {
+		return sigmoid(x) * (1 - sigmoid(x))
+	})
+}
+
+func TestLambda(t *testing.T) {
+	op := sigmoidLambda().(bs.Elementwise)
+	for _, x := range []float64{-3, 0, 0.5, 4} {
+		if v, expected := op.Value(x, 0), sigmoid(x); v != expected {
+			t.Errorf("Value at %v is %v, expected %v", x, v, expected)
+		}
+	}
+
+	for _, ops := range [][2]func(float64) float64{{nil, math.Exp}, {math.Exp, nil}} {
+		net := new(bs.Network)
+		if n := net.Add(operators.Lambda(ops[0], ops[1]), net.AddInput([]int{2})); n != nil || net.Error() == nil {
+			t.Errorf("Lambda with a nil function was added without error")
+		}
+	}
+}
+
+func TestLambdaGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, sigmoidLambda(), 5)
+}
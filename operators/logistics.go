@@ -1,10 +1,15 @@
 package operators
 
 import (
+	"github.com/pkg/errors"
 	bs "github.com/sharnoff/badstudent"
 	"math"
 )
 
+// saturationMargin is the distance from the bounds of Logistic and Tanh within which their values
+// are considered saturated
+const saturationMargin float64 = 0.01
+
 // ****************************************
 // Logistic
 // ****************************************
@@ -36,6 +41,58 @@ func (t logistic) Deriv(n *bs.Node, index int) float64 {
 	return n.Value(index) * (1 - n.Value(index))
 }
 
+func (t logistic) Saturated(n *bs.Node, index int) bool {
+	v := n.Value(index)
+	return v < saturationMargin || v > 1-saturationMargin
+}
+
+// ****************************************
+// Bounded
+// ****************************************
+
+type bounded struct {
+	Lower, Upper float64
+}
+
+// Bounded returns an elementwise application of the logistic function, scaled to give values in
+// the range (lower, upper): lower + (upper - lower) * logistic(x). This is useful for the outputs
+// of regression problems where the targets are known to lie within a range. Lower must be less
+// than upper, otherwise the Node will fail to be added.
+func Bounded(lower, upper float64) *bounded {
+	return &bounded{lower, upper}
+}
+
+func (t *bounded) TypeString() string {
+	return "bounded"
+}
+
+func (t *bounded) Get() interface{} {
+	return *t
+}
+
+func (t *bounded) Blank() interface{} {
+	return t
+}
+
+func (t *bounded) Finalize(n *bs.Node) error {
+	if !(t.Lower < t.Upper) {
+		return errors.Errorf("Lower bound must be less than upper bound (%v >= %v)", t.Lower, t.Upper)
+	}
+
+	return nil
+}
+
+func (t *bounded) Value(in float64, index int) float64 {
+	return t.Lower + (t.Upper-t.Lower)*(0.5+0.5*math.Tanh(0.5*in))
+}
+
+func (t *bounded) Deriv(n *bs.Node, index int) float64 {
+	// with σ = logistic(x) = (v - lower) / (upper - lower), the derivative is
+	// (upper - lower) * σ * (1 - σ), which simplifies to:
+	v := n.Value(index)
+	return (v - t.Lower) * (t.Upper - v) / (t.Upper - t.Lower)
+}
+
 // ****************************************
 // Tanh
 // ****************************************
@@ -64,6 +121,10 @@ func (t tanh) Deriv(n *bs.Node, index int) float64 {
 	return 1 - (n.Value(index) * n.Value(index))
 }
 
+func (t tanh) Saturated(n *bs.Node, index int) bool {
+	return math.Abs(n.Value(index)) > 1-saturationMargin
+}
+
 // ****************************************
 // Softsign
 // ****************************************
@@ -0,0 +1,78 @@
+package operators_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestLogisticGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.Logistic(), 5)
+}
+
+func TestBoundedGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.Bounded(-2, 3), 5)
+}
+
+func TestBoundedRange(t *testing.T) {
+	op := operators.Bounded(-2, 3)
+
+	for _, in := range []float64{-1e300, -800, -40, 0, 40, 800, 1e300, math.Inf(-1), math.Inf(1)} {
+		if v := op.Value(in, 0); !(v >= -2 && v <= 3) {
+			t.Errorf("Value at %v is %v, expected within [-2, 3]", in, v)
+		}
+	}
+
+	if v := op.Value(0, 0); v != 0.5 {
+		t.Errorf("Value at 0 is %v, expected the midpoint 0.5", v)
+	}
+
+	for _, bounds := range [][2]float64{{1, 1}, {2, -1}} {
+		net := new(bs.Network)
+		if n := net.Add(operators.Bounded(bounds[0], bounds[1]), net.AddInput([]int{2})); n != nil {
+			t.Errorf("Bounded(%v, %v) was added without error", bounds[0], bounds[1])
+		} else if _, ok := net.Error().(bs.OperatorFinalizeError); !ok {
+			t.Errorf("Bounded(%v, %v) gave error %v, expected type OperatorFinalizeError", bounds[0], bounds[1], net.Error())
+		}
+	}
+}
+
+func TestLogisticSaturation(t *testing.T) {
+	net := new(bs.Network)
+	in := net.AddInput([]int{4})
+	sigmoid := net.Add(operators.Logistic(), in).SetName("sigmoid")
+	relu := net.Add(operators.ReLU(), in).SetName("relu")
+	if err := net.Finalize(costfuncs.MSE(), sigmoid, relu); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := net.SaturationReport(); r != nil {
+		t.Errorf("Report before evaluation is %v, expected nil", r)
+	}
+
+	tests := []struct {
+		inputs        []float64
+		sigmoid, relu float64
+	}{
+		{[]float64{0, 1, -1, 2}, 0, 0.5},
+		{[]float64{-50, 50, 100, 0.1}, 0.75, 0.25},
+		{[]float64{-1e300, 1e300, -20, 20}, 1, 0.5},
+	}
+
+	for _, test := range tests {
+		if _, err := net.GetOutputs(test.inputs); err != nil {
+			t.Fatal(err)
+		}
+
+		r := net.SaturationReport()
+		if r["sigmoid"] != test.sigmoid || r["relu"] != test.relu {
+			t.Errorf("Report for inputs %v is %v, expected sigmoid: %v, relu: %v", test.inputs, r, test.sigmoid, test.relu)
+		} else if len(r) != 2 {
+			t.Errorf("Report for inputs %v includes %d Nodes, expected only the activations", test.inputs, len(r))
+		}
+	}
+}
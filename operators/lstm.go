@@ -40,7 +40,9 @@ func LSTM(net *bs.Network, size int, update, ignore, forget, sel *bs.Node) (outp
 type add int8
 
 // Add returns an elementwise addition operator that implements badstudent.Operator. All inputs to
-// its Node must have size equal to the Node.
+// its Node must have size equal to the Node. Because the deltas of each value are passed unchanged
+// to the corresponding value of every input, Add can be used to create residual (skip)
+// connections.
 func Add() add {
 	return add(0)
 }
@@ -62,6 +64,10 @@ func (t add) Finalize(n *bs.Node) error {
 }
 
 func (t add) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	if len(inputs) == 0 {
+		return tensors.Tensor{}, errors.Errorf("Must have at least one input")
+	}
+
 	return tensors.NewTensor(inputs[0].Dims()), nil
 }
 
@@ -76,8 +82,8 @@ func (t add) Evaluate(n *bs.Node, values []float64) {
 	}
 
 	// just random constants. Have not been optimized
-	opsPerThread, threadsPerCPU := runtime.NumCPU()*2, 1
-	utils.MultiThread(0, len(values), f, opsPerThread, threadsPerCPU)
+	opsPerThread := runtime.NumCPU() * 2
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
 }
 
 func (t add) InputDeltas(n *bs.Node) []float64 {
@@ -88,8 +94,8 @@ func (t add) InputDeltas(n *bs.Node) []float64 {
 	}
 
 	// just random constants. Have not been optimized
-	opsPerThread, threadsPerCPU := runtime.NumCPU()*2, 1
-	utils.MultiThread(0, n.Size(), f, opsPerThread, threadsPerCPU)
+	opsPerThread := runtime.NumCPU() * 2
+	utils.MultiThreadN(0, len(ds), f, opsPerThread, n.Workers())
 
 	return ds
 }
@@ -123,6 +129,10 @@ func (t mult) Finalize(n *bs.Node) error {
 }
 
 func (t mult) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	if len(inputs) == 0 {
+		return tensors.Tensor{}, errors.Errorf("Must have at least one input")
+	}
+
 	return tensors.NewTensor(inputs[0].Dims()), nil
 }
 
@@ -137,8 +147,8 @@ func (t mult) Evaluate(n *bs.Node, values []float64) {
 	}
 
 	// just random constants. Have not been optimized
-	opsPerThread, threadsPerCPU := runtime.NumCPU()*2, 1
-	utils.MultiThread(0, len(values), f, opsPerThread, threadsPerCPU)
+	opsPerThread := runtime.NumCPU() * 2
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
 }
 
 func (t mult) InputDeltas(n *bs.Node) []float64 {
@@ -149,8 +159,8 @@ func (t mult) InputDeltas(n *bs.Node) []float64 {
 	}
 
 	// just random constants. Have not been optimized
-	opsPerThread, threadsPerCPU := runtime.NumCPU()*2, 1
-	utils.MultiThread(0, n.Size(), f, opsPerThread, threadsPerCPU)
+	opsPerThread := runtime.NumCPU() * 2
+	utils.MultiThreadN(0, len(ds), f, opsPerThread, n.Workers())
 
 	return ds
 }
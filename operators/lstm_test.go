@@ -0,0 +1,56 @@
+package operators_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestAdd(t *testing.T) {
+	net := new(bs.Network).HandleErrors()
+	out := net.Add(operators.Add(), net.AddInput([]int{3}), net.AddInput([]int{3}))
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []float64{1, 2, 3, 0.5, -4, 10}
+
+	outs, err := net.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	} else if expected := []float64{1.5, -2, 13}; !equal(outs, expected) {
+		t.Errorf("Outputs are %v, expected %v", outs, expected)
+	}
+
+	// both inputs are given the deltas of the outputs, unchanged
+	deltas := []float64{1, -2, 0.5}
+	targets := make([]float64, len(outs))
+	for i := range targets {
+		targets[i] = outs[i] - deltas[i]
+	}
+
+	ds, err := net.InputGradients(inputs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if expected := append(deltas, deltas...); !equal(ds, expected) {
+		t.Errorf("Input deltas are %v, expected %v", ds, expected)
+	}
+}
+
+func TestAddSizes(t *testing.T) {
+	net := new(bs.Network)
+	if n := net.Add(operators.Add(), net.AddInput([]int{3}), net.AddInput([]int{4})); n != nil || net.Error() == nil {
+		t.Errorf("Inputs of different sizes were added without error")
+	}
+}
+
+func TestAddGradient(t *testing.T) {
+	testutil.AssertOperatorGradientInputs(t, operators.Add(), 4, 4)
+	testutil.AssertOperatorGradientInputs(t, operators.Add(), 3, 3, 3)
+}
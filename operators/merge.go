@@ -0,0 +1,145 @@
+package operators
+
+import (
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/utils"
+	"github.com/sharnoff/tensors"
+	"runtime"
+)
+
+// ****************************************
+// Weighted Add
+// ****************************************
+
+type weightedAdd struct {
+	// whether or not there is a separate coefficient for each value
+	PerElement bool
+
+	// the coefficients for the first input, followed by those for the second.
+	Ws []float64
+}
+
+// WeightedAdd returns an operator that combines two inputs as α*a + β*b, where α and β are
+// learned. If 'perElement' is false, α and β are single values shared by every element; otherwise,
+// each element has its own pair. Both inputs must have size equal to the Node. This can be used for
+// gated skip connections, where the Network learns how much of each input to keep.
+//
+// The coefficients are set by the Node's Initializer, like any other weights. Because they will
+// typically start at one (giving plain addition, as with Add), it is recommended to use:
+//	net.Add(operators.WeightedAdd(false), a, b).Init(initializers.Constant(1))
+func WeightedAdd(perElement bool) *weightedAdd {
+	return &weightedAdd{PerElement: perElement}
+}
+
+func (t *weightedAdd) TypeString() string {
+	return "weighted-add"
+}
+
+// numCoeffs returns the number of coefficients for each input
+func (t *weightedAdd) numCoeffs(n *bs.Node) int {
+	if t.PerElement {
+		return n.Size()
+	}
+
+	return 1
+}
+
+func (t *weightedAdd) Finalize(n *bs.Node) error {
+	if n.NumInputNodes() != 2 {
+		return errors.Errorf("Must have exactly two inputs (has %d)", n.NumInputNodes())
+	} else if n.Input(1).Size() != n.Input(0).Size() {
+		return errors.Errorf("All inputs must have equal size (n.InputSize(%d) (%d) != n.InputSize(%d) (%d))",
+			1, n.Input(1).Size(), 0, n.Input(0).Size())
+	}
+
+	// if it's been loaded from a file...
+	if len(t.Ws) != 0 {
+		if len(t.Ws) != 2*t.numCoeffs(n) {
+			return errors.Errorf("Number of saved weights not equal to expected (%d != %d)", len(t.Ws), 2*t.numCoeffs(n))
+		}
+
+		return nil
+	}
+
+	t.Ws = make([]float64, 2*t.numCoeffs(n))
+	return nil
+}
+
+func (t *weightedAdd) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	if len(inputs) == 0 {
+		return tensors.Tensor{}, errors.Errorf("Must have at least one input")
+	}
+
+	return tensors.NewTensor(inputs[0].Dims()), nil
+}
+
+func (t *weightedAdd) Get() interface{} {
+	return *t
+}
+
+func (t *weightedAdd) Blank() interface{} {
+	return t
+}
+
+// coeffs returns the coefficients α and β for the given index
+func (t *weightedAdd) coeffs(index int) (float64, float64) {
+	if !t.PerElement {
+		return t.Ws[0], t.Ws[1]
+	}
+
+	return t.Ws[index], t.Ws[len(t.Ws)/2+index]
+}
+
+func (t *weightedAdd) Evaluate(n *bs.Node, values []float64) {
+	inputs := n.AllInputs()
+
+	f := func(i int) {
+		α, β := t.coeffs(i)
+		values[i] = α*inputs[i] + β*inputs[n.Size()+i]
+	}
+
+	// just random constants. Have not been optimized
+	opsPerThread := runtime.NumCPU() * 2
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
+}
+
+func (t *weightedAdd) InputDeltas(n *bs.Node) []float64 {
+	ds := make([]float64, n.NumInputs())
+
+	f := func(i int) {
+		α, β := t.coeffs(i)
+		ds[i] = α * n.Delta(i)
+		ds[n.Size()+i] = β * n.Delta(i)
+	}
+
+	// just random constants. Have not been optimized
+	opsPerThread := runtime.NumCPU() * 2
+	utils.MultiThreadN(0, n.Size(), f, opsPerThread, n.Workers())
+
+	return ds
+}
+
+func (t *weightedAdd) Weights() []float64 {
+	return t.Ws
+}
+
+func (t *weightedAdd) Grad(n *bs.Node, index int) float64 {
+	// the offset of the input that the coefficient applies to
+	in := 0
+	if index >= len(t.Ws)/2 {
+		in = n.Size()
+		index -= len(t.Ws) / 2
+	}
+
+	if t.PerElement {
+		return n.InputValue(in+index) * n.Delta(index)
+	}
+
+	var sum float64
+	for i := 0; i < n.Size(); i++ {
+		sum += n.InputValue(in+i) * n.Delta(i)
+	}
+
+	return sum
+}
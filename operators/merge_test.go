@@ -0,0 +1,60 @@
+package operators_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestWeightedAddGradient(t *testing.T) {
+	testutil.AssertOperatorGradientInputs(t, operators.WeightedAdd(false), 4, 4)
+	testutil.AssertOperatorGradientInputs(t, operators.WeightedAdd(true), 4, 4)
+}
+
+func TestWeightedAddFit(t *testing.T) {
+	const alpha, beta = 2, -0.5
+
+	net := new(bs.Network)
+	a, b := net.AddInput([]int{2}), net.AddInput([]int{2})
+	out := net.Add(operators.WeightedAdd(false), a, b).Opt(optimizers.SGD()).Init(initializers.Constant(1))
+	net.AddHP("learning-rate", hyperparams.Constant(0.05))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	// the targets are exactly alpha*a + beta*b
+	rng := rand.New(rand.NewSource(1))
+	dataset := make([][][]float64, 50)
+	for i := range dataset {
+		ins := make([]float64, 4)
+		for j := range ins {
+			ins[j] = rng.Float64()*2 - 1
+		}
+
+		targets := []float64{alpha*ins[0] + beta*ins[2], alpha*ins[1] + beta*ins[3]}
+		dataset[i] = [][]float64{ins, targets}
+	}
+
+	if err := net.Train(bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(20 * len(dataset))}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ws := net.Weights(); math.Abs(ws[0]-alpha) > 1e-3 || math.Abs(ws[1]-beta) > 1e-3 {
+		t.Errorf("Coefficients after training are %v, expected [%v %v]", ws, alpha, beta)
+	}
+}
+
+func TestWeightedAddSizes(t *testing.T) {
+	net := new(bs.Network)
+	if n := net.Add(operators.WeightedAdd(false), net.AddInput([]int{3}), net.AddInput([]int{4})); n != nil || net.Error() == nil {
+		t.Errorf("Inputs of different sizes were added without error")
+	}
+}
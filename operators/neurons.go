@@ -133,8 +133,8 @@ func (t *neurons) Evaluate(n *bs.Node, values []float64) {
 		values[v] = sum
 	}
 
-	opsPerThread, threadsPerCPU := 1, 1
-	utils.MultiThread(0, len(values), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 1
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
 }
 
 func (t *neurons) InputDeltas(n *bs.Node) []float64 {
@@ -146,8 +146,8 @@ func (t *neurons) InputDeltas(n *bs.Node) []float64 {
 		}
 	}
 
-	opsPerThread, threadsPerCPU := 1, 1
-	utils.MultiThread(0, n.NumInputs(), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 1
+	utils.MultiThreadN(0, n.NumInputs(), f, opsPerThread, n.Workers())
 
 	return ds
 }
@@ -162,6 +162,11 @@ func (t *neurons) Grad(n *bs.Node, index int) float64 {
 	}
 }
 
+// IsBias is the implementation of badstudent.Biased
+func (t *neurons) IsBias(n *bs.Node, index int) bool {
+	return index%(n.NumInputs()+t.NumBiases) >= n.NumInputs()
+}
+
 func (t *neurons) Weights() []float64 {
 	return t.Ws
 }
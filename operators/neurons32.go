@@ -0,0 +1,192 @@
+package operators
+
+import (
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/utils"
+	"github.com/sharnoff/tensors"
+)
+
+type neurons32 struct {
+	Size int
+
+	// the weights, stored at single precision. They are organized in the same way as for neurons.
+	Ws []float32
+
+	// always either 0 or 1, as for neurons
+	NumBiases int
+
+	// the value multiplied by bias
+	Bias float64
+
+	// a double-precision copy of Ws, given by Weights so that the weights can be set and updated.
+	// It is only kept until the Network releases it (or until it is next needed by the Operator),
+	// at which point any changes are copied back into Ws. This is nil whenever Ws is current.
+	view []float64
+}
+
+// Neurons32 returns a layer of perceptrons that is the same as Neurons, except that its weights are
+// stored as float32 instead of float64, halving the memory that they use. Values, deltas, and
+// gradients are still calculated at double precision.
+//
+// Because Optimizers and Initializers need a []float64 to write to, Weights returns a temporary
+// double-precision copy of the weights, which is converted back to single precision and discarded
+// once the Network is done with it (see badstudent.TemporaryWeights), so that only the float32
+// weights are kept between uses. Any changes to the weights are therefore rounded to float32, and
+// changes that are too small relative to a weight will be lost entirely.
+//
+// As with Neurons, the value of the biases can be set by BiasValue, and the number of biases can
+// be set by Biases.
+func Neurons32(size int) *neurons32 {
+	n := new(neurons32)
+	n.Size = size
+	n.Bias = defaultValue["neurons-bias"]
+	n.NumBiases = default_numBiases
+	return n
+}
+
+// ***************************************************
+// Customization Functions
+// ***************************************************
+
+// NoBiases changes the neurons to not have any biases.
+func (n *neurons32) NoBiases() *neurons32 {
+	n.NumBiases = 0
+	return n
+}
+
+// WithBiases changes the neurons to have biases, if they did not already
+func (n *neurons32) WithBiases() *neurons32 {
+	n.NumBiases = 1
+	return n
+}
+
+// BiasValue sets the value multiplied by the biases. The default value can be set by
+// SetDefault("neurons-bias")
+func (n *neurons32) BiasValue(b float64) *neurons32 {
+	n.Bias = b
+	return n
+}
+
+// ***************************************************
+// Helper Functions
+// ***************************************************
+
+// sync copies any changes from the double-precision view of the weights back into Ws, and then
+// discards the view
+func (t *neurons32) sync() {
+	if t.view == nil {
+		return
+	}
+
+	for i, w := range t.view {
+		t.Ws[i] = float32(w)
+	}
+
+	t.view = nil
+}
+
+func (t *neurons32) weight(n *bs.Node, in, val int) float64 {
+	return float64(t.Ws[val*(n.NumInputs()+t.NumBiases)+in])
+}
+
+// ***************************************************
+// Interface-required functions
+// ***************************************************
+
+func (t *neurons32) TypeString() string {
+	return "neurons32"
+}
+
+func (t *neurons32) Finalize(n *bs.Node) error {
+	// if it's been loaded from a file...
+	if len(t.Ws) != 0 {
+		return nil
+	}
+
+	t.Ws = make([]float32, (n.NumInputs()+t.NumBiases)*t.Size)
+	return nil
+}
+
+func (t *neurons32) Get() interface{} {
+	t.sync()
+	return *t
+}
+
+func (t *neurons32) Blank() interface{} {
+	return t
+}
+
+func (t *neurons32) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	return tensors.NewTensor([]int{t.Size}), nil
+}
+
+func (t *neurons32) Evaluate(n *bs.Node, values []float64) {
+	t.sync()
+
+	inputs := n.AllInputs()
+	f := func(v int) {
+		var sum float64
+		for in := range inputs {
+			sum += t.weight(n, in, v) * inputs[in]
+		}
+
+		if t.NumBiases != 0 {
+			sum += t.Bias * t.weight(n, n.NumInputs(), v)
+		}
+
+		values[v] = sum
+	}
+
+	opsPerThread := 1
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
+}
+
+func (t *neurons32) InputDeltas(n *bs.Node) []float64 {
+	t.sync()
+
+	ds := make([]float64, n.NumInputs())
+
+	f := func(in int) {
+		for v := 0; v < n.Size(); v++ {
+			ds[in] += n.Delta(v) * t.weight(n, in, v)
+		}
+	}
+
+	opsPerThread := 1
+	utils.MultiThreadN(0, n.NumInputs(), f, opsPerThread, n.Workers())
+
+	return ds
+}
+
+func (t *neurons32) Grad(n *bs.Node, index int) float64 {
+	in := index % (n.NumInputs() + t.NumBiases)
+	v := (index - in) / (n.NumInputs() + t.NumBiases)
+	if in < n.NumInputs() {
+		return n.InputValue(in) * n.Delta(v)
+	} else {
+		return t.Bias * n.Delta(v)
+	}
+}
+
+// IsBias is the implementation of badstudent.Biased
+func (t *neurons32) IsBias(n *bs.Node, index int) bool {
+	return index%(n.NumInputs()+t.NumBiases) >= n.NumInputs()
+}
+
+// Weights returns a double-precision copy of the weights, which will be copied back when they are
+// released or next used. Repeated calls give the same slice until then.
+func (t *neurons32) Weights() []float64 {
+	if t.view == nil {
+		t.view = make([]float64, len(t.Ws))
+		for i, w := range t.Ws {
+			t.view[i] = float64(w)
+		}
+	}
+
+	return t.view
+}
+
+// ReleaseWeights is the implementation of badstudent.TemporaryWeights
+func (t *neurons32) ReleaseWeights() {
+	t.sync()
+}
@@ -0,0 +1,130 @@
+package operators_test
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	_ "github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	_ "github.com/sharnoff/badstudent/optimizers"
+)
+
+// dense returns a finalized Network with a single dense Node using 'op', after the given number of
+// inputs
+func dense(tb testing.TB, op bs.Operator, inputs int) *bs.Network {
+	tb.Helper()
+
+	net := new(bs.Network).HandleErrors()
+	out := net.Add(op, net.AddInput([]int{inputs}))
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		tb.Fatalf("Failed to finalize Network: %v", err)
+	}
+
+	return net
+}
+
+func TestNeurons32MatchesNeurons(t *testing.T) {
+	const inputs, size = 20, 10
+
+	rng := rand.New(rand.NewSource(1))
+
+	net64 := dense(t, operators.Neurons(size), inputs)
+	net32 := dense(t, operators.Neurons32(size), inputs)
+
+	if net64.NumWeights() != net32.NumWeights() {
+		t.Fatalf("Neurons32 has %d weights, expected %d", net32.NumWeights(), net64.NumWeights())
+	}
+
+	ws := make([]float64, net64.NumWeights())
+	for i := range ws {
+		ws[i] = rng.Float64()*2 - 1
+	}
+
+	if err := net64.SetWeights(ws); err != nil {
+		t.Fatal(err)
+	} else if err := net32.SetWeights(ws); err != nil {
+		t.Fatal(err)
+	}
+
+	for trial := 0; trial < 10; trial++ {
+		ins := make([]float64, inputs)
+		for i := range ins {
+			ins[i] = rng.Float64()*2 - 1
+		}
+
+		outs64, err := net64.GetOutputs(ins)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		outs32, err := net32.GetOutputs(ins)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// each weight is rounded with a relative error of at most 2^-24; summed over all of the
+		// inputs, the error is bounded by the sum of the magnitudes of each term
+		for v := range outs64 {
+			var bound float64
+			for in := range ins {
+				bound += math.Abs(ws[v*(inputs+1)+in] * ins[in])
+			}
+			bound = (bound + math.Abs(ws[v*(inputs+1)+inputs])) * 0x1p-24
+
+			if diff := math.Abs(outs64[v] - outs32[v]); diff > bound {
+				t.Errorf("Trial %d, output %d: float32 gives %v, float64 gives %v (difference %v > %v)", trial, v, outs32[v], outs64[v], diff, bound)
+			}
+		}
+	}
+
+	// the weights should be stored exactly as float32
+	for i, w := range net32.Weights() {
+		if w != float64(float32(ws[i])) {
+			t.Errorf("Weight %d is %v, expected %v", i, w, float64(float32(ws[i])))
+		}
+	}
+}
+
+// the bytes still allocated after creating and training a large dense Node using the Operator
+// from 'op', averaged over b.N Networks
+func benchmarkDenseMemory(b *testing.B, op func(size int) bs.Operator) {
+	const inputs, size = 1000, 1000
+
+	data := bs.SliceSource([][][]float64{{make([]float64, inputs), make([]float64, size)}})
+	nets := make([]*bs.Network, b.N)
+
+	b.ReportAllocs()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	for i := range nets {
+		nets[i] = dense(b, op(size), inputs)
+
+		args := bs.TrainArgs{Data: data, RunCondition: bs.TrainUntil(2)}
+		if err := nets[i].Train(args); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(nets)
+
+	b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/float64(b.N), "retained-B/op")
+}
+
+func BenchmarkNeuronsMemory(b *testing.B) {
+	benchmarkDenseMemory(b, func(size int) bs.Operator { return operators.Neurons(size) })
+}
+
+func BenchmarkNeurons32Memory(b *testing.B) {
+	benchmarkDenseMemory(b, func(size int) bs.Operator { return operators.Neurons32(size) })
+}
@@ -0,0 +1,255 @@
+package operators
+
+import (
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/tensors"
+	"math"
+)
+
+// ****************************************
+// Normalize
+// ****************************************
+
+type normalize struct {
+	Mean []float64
+	SD   []float64
+}
+
+// Normalize returns an Elementwise operator that standardizes each value with the given mean and
+// standard deviation: (x - mean[i]) / sd[i]. The statistics are fixed; they are not changed by
+// training, but are saved with the Network. Any standard deviations that are zero (or negative)
+// are replaced by 1, so that constant values are only shifted.
+//
+// Usually, Normalize is placed directly after the inputs to a Network, with statistics given by
+// FitNormalize. The length of both slices must be equal to the size of the Node.
+func Normalize(mean, sd []float64) *normalize {
+	t := &normalize{Mean: mean, SD: make([]float64, len(sd))}
+	for i := range sd {
+		if sd[i] > 0 {
+			t.SD[i] = sd[i]
+		} else {
+			t.SD[i] = 1
+		}
+	}
+
+	return t
+}
+
+// FitNormalize returns a Normalize operator with the mean and standard deviation of each input
+// value of the samples provided by 'src'. The source is Reset before it is used. This is intended
+// for use with a Normalize Node that directly takes the inputs of the Network.
+//
+// FitNormalize returns bs.ErrNoData if the source gives no samples, and an error if the samples
+// have different numbers of inputs. If src is a bs.CheckedSource, any errors it gives will also be
+// returned.
+func FitNormalize(src bs.DataSource) (*normalize, error) {
+	src.Reset()
+
+	// Welford's algorithm, so that only a single pass through the data is needed
+	var count int
+	var mean, m2 []float64
+	for {
+		ins, _, ok := src.Next()
+		if !ok {
+			break
+		}
+
+		if count == 0 {
+			mean = make([]float64, len(ins))
+			m2 = make([]float64, len(ins))
+		} else if len(ins) != len(mean) {
+			return nil, errors.Errorf("Sample %d has a different number of inputs from the first (%d != %d)", count, len(ins), len(mean))
+		}
+
+		count++
+		for i, x := range ins {
+			d := x - mean[i]
+			mean[i] += d / float64(count)
+			m2[i] += d * (x - mean[i])
+		}
+	}
+
+	if c, ok := src.(bs.CheckedSource); ok && c.Err() != nil {
+		return nil, c.Err()
+	} else if count == 0 {
+		return nil, bs.ErrNoData
+	}
+
+	sd := make([]float64, len(m2))
+	for i := range m2 {
+		sd[i] = math.Sqrt(m2[i] / float64(count))
+	}
+
+	return Normalize(mean, sd), nil
+}
+
+func (t *normalize) TypeString() string {
+	return "normalize"
+}
+
+func (t *normalize) Finalize(n *bs.Node) error {
+	if len(t.Mean) != n.Size() {
+		return errors.Errorf("Number of means not equal to size of Node (%d != %d)", len(t.Mean), n.Size())
+	} else if len(t.SD) != n.Size() {
+		return errors.Errorf("Number of standard deviations not equal to size of Node (%d != %d)", len(t.SD), n.Size())
+	}
+
+	return nil
+}
+
+func (t *normalize) Get() interface{} {
+	return *t
+}
+
+func (t *normalize) Blank() interface{} {
+	return t
+}
+
+func (t *normalize) Value(in float64, index int) float64 {
+	return (in - t.Mean[index]) / t.SD[index]
+}
+
+func (t *normalize) Deriv(n *bs.Node, index int) float64 {
+	return 1 / t.SD[index]
+}
+
+// ****************************************
+// Layer Normalization
+// ****************************************
+
+// added to the variance, so that constant values do not give division by zero
+const layerNormEpsilon float64 = 1e-5
+
+type layerNorm struct {
+	// the scales, followed by the shifts
+	Ws []float64
+
+	// the normalized values and the reciprocal of the standard deviation from the last evaluation,
+	// for backpropagation
+	normed []float64
+	invSD  float64
+}
+
+// LayerNorm returns an operator that normalizes its inputs using their own mean and variance, then
+// applies a learned scale and shift to each value: scale[i] * (x[i] - mean) / sd + shift[i]. Unlike
+// batch normalization, the statistics are calculated separately for each sample, so they do not
+// depend on batches. Multiple inputs are concatenated, in order.
+//
+// The shifts are biases, so they are initialized to zero (unless another value is given by
+// (*badstudent.Node).BiasInit). The scales are set by the Node's Initializer; it is recommended to
+// start them at one, with:
+//	net.Add(operators.LayerNorm(), in).Init(initializers.Constant(1))
+func LayerNorm() *layerNorm {
+	return &layerNorm{}
+}
+
+func (t *layerNorm) TypeString() string {
+	return "layer-norm"
+}
+
+func (t *layerNorm) Finalize(n *bs.Node) error {
+	// if it's been loaded from a file...
+	if len(t.Ws) != 0 {
+		if len(t.Ws) != 2*n.Size() {
+			return errors.Errorf("Number of saved weights not equal to expected (%d != %d)", len(t.Ws), 2*n.Size())
+		}
+
+		return nil
+	}
+
+	t.Ws = make([]float64, 2*n.Size())
+	return nil
+}
+
+func (t *layerNorm) Get() interface{} {
+	return *t
+}
+
+func (t *layerNorm) Blank() interface{} {
+	return t
+}
+
+func (t *layerNorm) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	ls := make([]tensors.Tensor, len(inputs))
+	for i := range ls {
+		ls[i] = inputs[i].Shape()
+	}
+
+	return bs.ConcatShape(ls)
+}
+
+func (t *layerNorm) Evaluate(n *bs.Node, values []float64) {
+	inputs := n.AllInputs()
+	size := float64(len(values))
+
+	var mean float64
+	for _, x := range inputs {
+		mean += x
+	}
+	mean /= size
+
+	var variance float64
+	for _, x := range inputs {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= size
+
+	// the normalized values are only kept for backpropagation, which never follows Infer
+	normed := t.normed
+	if n.IsInferring() || len(normed) != len(values) {
+		normed = make([]float64, len(values))
+	}
+
+	invSD := 1 / math.Sqrt(variance+layerNormEpsilon)
+	scales, shifts := t.Ws[:len(values)], t.Ws[len(values):]
+
+	for i, x := range inputs {
+		normed[i] = (x - mean) * invSD
+		values[i] = scales[i]*normed[i] + shifts[i]
+	}
+
+	if !n.IsInferring() {
+		t.normed, t.invSD = normed, invSD
+	}
+}
+
+func (t *layerNorm) InputDeltas(n *bs.Node) []float64 {
+	ds := make([]float64, n.Size())
+	size := float64(len(ds))
+
+	// the deltas of the normalized values, along with their mean and their mean product with the
+	// normalized values
+	var mean, meanNormed float64
+	for i := range ds {
+		ds[i] = n.Delta(i) * t.Ws[i]
+
+		mean += ds[i]
+		meanNormed += ds[i] * t.normed[i]
+	}
+	mean /= size
+	meanNormed /= size
+
+	for i := range ds {
+		ds[i] = t.invSD * (ds[i] - mean - t.normed[i]*meanNormed)
+	}
+
+	return ds
+}
+
+func (t *layerNorm) Weights() []float64 {
+	return t.Ws
+}
+
+func (t *layerNorm) Grad(n *bs.Node, index int) float64 {
+	if index < n.Size() {
+		return n.Delta(index) * t.normed[index]
+	}
+
+	return n.Delta(index - n.Size())
+}
+
+// IsBias is the implementation of badstudent.Biased
+func (t *layerNorm) IsBias(n *bs.Node, index int) bool {
+	return index >= n.Size()
+}
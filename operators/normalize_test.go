@@ -0,0 +1,122 @@
+package operators_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestFitNormalize(t *testing.T) {
+	const samples = 100
+
+	// the first value is spread widely, the second is offset, and the third is constant
+	rng := rand.New(rand.NewSource(1))
+	dataset := make([][][]float64, samples)
+	for i := range dataset {
+		dataset[i] = [][]float64{{rng.NormFloat64() * 50, rng.Float64() + 10, 3}, nil}
+	}
+
+	op, err := operators.FitNormalize(bs.SliceSource(dataset))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	net := dense(t, op, 3)
+
+	var sum, sumSquares [3]float64
+	for _, d := range dataset {
+		outs, err := net.GetOutputs(d[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, v := range outs {
+			sum[i] += v
+			sumSquares[i] += v * v
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		mean := sum[i] / samples
+		variance := sumSquares[i]/samples - mean*mean
+
+		if math.Abs(mean) > 1e-9 || math.Abs(variance-1) > 1e-9 {
+			t.Errorf("Value %d has mean %v and variance %v after normalizing, expected 0 and 1", i, mean, variance)
+		}
+	}
+
+	// the constant value has zero variance, so it is only shifted
+	if sum[2] != 0 || sumSquares[2] != 0 {
+		t.Errorf("Constant value was not normalized to zero (sum %v, sum of squares %v)", sum[2], sumSquares[2])
+	}
+
+	// the statistics are saved with the Network
+	clone, err := net.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range dataset[:5] {
+		a, _ := net.GetOutputs(d[0])
+		b, err := clone.GetOutputs(d[0])
+		if err != nil {
+			t.Fatal(err)
+		} else if !equal(a, b) {
+			t.Errorf("Outputs for %v differ after saving and loading: %v != %v", d[0], a, b)
+		}
+	}
+
+	if _, err := operators.FitNormalize(bs.SliceSource(nil)); err != bs.ErrNoData {
+		t.Errorf("Fitting to no data gave error %v, expected ErrNoData", err)
+	}
+}
+
+func TestNormalizeGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.Normalize([]float64{1, -2, 0}, []float64{0.5, 3, 0}), 3)
+}
+
+func TestLayerNormGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.LayerNorm(), 6)
+}
+
+func TestLayerNorm(t *testing.T) {
+	net := new(bs.Network)
+	in := net.AddInput([]int{5})
+	norm := net.Add(operators.LayerNorm(), in).Opt(optimizers.SGD()).Init(initializers.Constant(1))
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), norm); err != nil {
+		t.Fatal(err)
+	}
+
+	// with scales of one and shifts of zero, the outputs are just the normalized values
+	for _, inputs := range [][]float64{{1, 2, 3, 4, 5}, {-300, 20, 0.5, 7, 1000}} {
+		outs, err := net.GetOutputs(inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var mean, variance float64
+		for _, v := range outs {
+			mean += v
+		}
+		mean /= float64(len(outs))
+
+		for _, v := range outs {
+			variance += (v - mean) * (v - mean)
+		}
+		variance /= float64(len(outs))
+
+		// the small constant added to the variance makes it slightly less than one
+		if math.Abs(mean) > 1e-12 || math.Abs(variance-1) > 1e-5 {
+			t.Errorf("Outputs for %v have mean %v and variance %v, expected 0 and 1", inputs, mean, variance)
+		}
+	}
+}
@@ -68,8 +68,9 @@ func (p *maxPool) Dims(dims ...int) *maxPool {
 }
 
 // InputDims sets the input dimensions of the pooling Operator, for internal use.
-// This is REQUIRED unless the Operator is being loaded. InputDims will panic if
-// called after the pooling Operator has been Finalized.
+// If not set, the input dimensions are taken from the inputs to the Node: the
+// dimensions of a single input Node, or the total size of multiple. InputDims will
+// panic if called after the pooling Operator has been Finalized.
 func (p *avgPool) InputDims(dims ...int) *avgPool {
 	if p.poolConstructor == nil {
 		panic("pooling Operator has been finalized")
@@ -185,7 +186,7 @@ type maxPool struct {
 
 // AvgPool returns the average pooling function, which implements
 // badstudent.Operator. AvgPool can be customized with the methods available on
-// pool. Setting InputDims and FilterSize is required.
+// pool. Setting Filter is required.
 //
 // The default value of padding can be set by SetDefault("pool-padding").
 //
@@ -195,9 +196,23 @@ func AvgPool() *avgPool {
 	return &avgPool{ap}
 }
 
+// AvgPool1D returns a one-dimensional average-pooling Operator, with the input dimensions
+// determined from the inputs to its Node. Each output is the mean of a window of inputs, and its
+// delta is divided equally between them. The sizing is checked when it is added to a Network,
+// which will give type GetShapeError if the window does not fit within the inputs or the windows
+// do not divide them evenly. It is simply a shortcut for:
+//	AvgPool().Filter(window).Stride(stride)
+func AvgPool1D(window, stride int) *avgPool {
+	return AvgPool().Filter(window).Stride(stride)
+}
+
 // MaxPool returns the max-pooling function, which implements badstudent.Operator.
-// MaxPool can be customized with the methods available on pool. Setting InputDims
-// and FilterSize is required.
+// MaxPool can be customized with the methods available on pool. Setting Filter is
+// required.
+//
+// During evaluation, MaxPool records the input that each output value came from (its
+// 'switch'), so that deltas are only passed back to that input. If there are multiple
+// equal maximums, the first is used.
 //
 // N.B.: MaxPool cannot be used in a node with Delay, because the functioning of
 // saving switches does not work with calculating deltas.
@@ -210,6 +225,13 @@ func MaxPool() *maxPool {
 	return mp
 }
 
+// MaxPool1D returns a one-dimensional max-pooling Operator, with the input dimensions
+// determined from the inputs to its Node. It is simply a shortcut for:
+//	MaxPool().Filter(window).Stride(stride)
+func MaxPool1D(window, stride int) *maxPool {
+	return MaxPool().Filter(window).Stride(stride)
+}
+
 // ***************************************************
 // Basic list operations:
 // ***************************************************
@@ -239,6 +261,22 @@ func mapSub(base, subtrahend []int) []int {
 // Shared Methods / Helper functions:
 // ***************************************************
 
+// inputDims returns the dimensions of the values from the given inputs, for Operators that can
+// infer their input dimensions. A single input gives its own dimensions, and multiple are
+// collapsed into one dimension.
+func inputDims(inputs []*bs.Node) []int {
+	if len(inputs) == 1 {
+		return inputs[0].Dims()
+	}
+
+	var size int
+	for _, in := range inputs {
+		size += in.Size()
+	}
+
+	return []int{size}
+}
+
 // MustSize calls Size, but panics if it encounters an error.
 func (p *pool) MustSize() int {
 	size, err := p.Size()
@@ -298,6 +336,14 @@ func (p *pool) Size() (int, error) {
 		p.Padding = make([]int, len(p.inputDims))
 	}
 
+	// a filter that doesn't fit within the inputs would give no outputs in that dimension
+	for i := range p.inputDims {
+		if p.filter[i] > p.inputDims[i]+2*p.Padding[i] {
+			return 0, errors.Errorf("Filter[%d] is larger than InputDims[%d] with padding (%d > %d + 2*%d)",
+				i, i, p.filter[i], p.inputDims[i], p.Padding[i])
+		}
+	}
+
 	if p.dims != nil { // if everything is filled in, check whether or not it works
 		for i := range p.inputDims {
 			in := p.inputDims[i] + 2*p.Padding[i]
@@ -353,6 +399,10 @@ func (p *pool) Finalize(n *bs.Node) error {
 }
 
 func (p *pool) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	if p.poolConstructor != nil && p.inputDims == nil {
+		p.inputDims = inputDims(inputs)
+	}
+
 	_, err := p.Size()
 	if err != nil {
 		return tensors.Tensor{}, err
@@ -361,6 +411,16 @@ func (p *pool) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
 	return tensors.NewTensor(p.Outs.Dims), nil
 }
 
+// InputSize returns the total number of inputs that the Operator expects, or -1 if that has not
+// yet been determined. It is the implementation of badstudent.FixedInput.
+func (p *pool) InputSize() int {
+	if p.Ins != nil {
+		return p.Ins.Size()
+	}
+
+	return -1
+}
+
 func (p *pool) Get() interface{} {
 	return *p
 }
@@ -374,9 +434,9 @@ func (po *pool) isPadding(point []int) bool {
 	copy(p, point)
 	mapSub(p, po.Padding)
 
-	// if any are less than 0, it's padding
+	// if any are less than 0 or past the end of the inputs, it's padding
 	for i := range p {
-		if p[i] < 0 || p[i] > po.Outs.Dim(i) {
+		if p[i] < 0 || p[i] >= po.Ins.Dim(i) {
 			return true
 		}
 	}
@@ -446,8 +506,8 @@ func (t *avgPool) Evaluate(n *bs.Node, values []float64) {
 		values[v] = sum / float64(len(ins))
 	}
 
-	opsPerThread, threadsPerCPU := 1, 1
-	utils.MultiThread(0, len(values), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 1
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
 }
 
 func (t *avgPool) InputDeltas(n *bs.Node) []float64 {
@@ -463,8 +523,8 @@ func (t *avgPool) InputDeltas(n *bs.Node) []float64 {
 		}
 	}
 
-	opsPerThread, threadsPerCPU := 1, 1
-	utils.MultiThread(0, n.Size(), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 1
+	utils.MultiThreadN(0, n.Size(), f, opsPerThread, n.Workers())
 
 	return uint64ToFloat64(atoms)
 }
@@ -490,8 +550,12 @@ func (t *maxPool) Finalize(n *bs.Node) error {
 func (t *maxPool) Evaluate(n *bs.Node, values []float64) {
 	inputs := n.AllInputs()
 
-	// note: switches will sometimes be zero
-	t.switches = make([]int, len(values))
+	// note: switches will sometimes be zero. They're only kept for backpropagation, which never
+	// follows Infer.
+	switches := make([]int, len(values))
+	if !n.IsInferring() {
+		t.switches = switches
+	}
 
 	f := func(v int) {
 		ins := t.inputsTo(v)
@@ -500,24 +564,25 @@ func (t *maxPool) Evaluate(n *bs.Node, values []float64) {
 		if ins[0] != -1 { // if it's not padding
 			max = inputs[ins[0]]
 		}
-		t.switches[v] = ins[0]
+		switches[v] = ins[0]
 
+		// only strictly greater values replace the current max, so ties go to the first
 		for i := 1; i < len(ins); i++ {
-			v := t.PaddingValue
+			in := t.PaddingValue
 			if ins[i] != -1 {
-				v = inputs[ins[i]]
+				in = inputs[ins[i]]
 			}
 
-			if v > max {
-				max, t.switches[i] = v, ins[i]
+			if in > max {
+				max, switches[v] = in, ins[i]
 			}
 		}
 
 		values[v] = max
 	}
 
-	opsPerThread, threadsPerCPU := 1, 1
-	utils.MultiThread(0, len(values), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 1
+	utils.MultiThreadN(0, len(values), f, opsPerThread, n.Workers())
 }
 
 func (t *maxPool) InputDeltas(n *bs.Node) []float64 {
@@ -529,8 +594,8 @@ func (t *maxPool) InputDeltas(n *bs.Node) []float64 {
 		}
 	}
 
-	opsPerThread, threadsPerCPU := 10, 1
-	utils.MultiThread(0, n.Size(), f, opsPerThread, threadsPerCPU)
+	opsPerThread := 10
+	utils.MultiThreadN(0, n.Size(), f, opsPerThread, n.Workers())
 
 	return uint64ToFloat64(atoms)
 }
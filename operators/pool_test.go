@@ -0,0 +1,138 @@
+package operators_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestAvgPool1D(t *testing.T) {
+	inputs := []float64{1, 2, 3, 4, 5, 6}
+
+	tests := []struct {
+		window, stride int
+		outs           []float64
+		// the deltas of the outputs, and the resulting deltas of the inputs
+		deltas, inDeltas []float64
+	}{
+		{2, 2, []float64{1.5, 3.5, 5.5}, []float64{3, -6, 0.5}, []float64{1.5, 1.5, -3, -3, 0.25, 0.25}},
+		{3, 1, []float64{2, 3, 4, 5}, []float64{3, -6, 0.3, 0}, []float64{1, -1, -0.9, -1.9, 0.1, 0}},
+		{6, 6, []float64{3.5}, []float64{1.2}, []float64{0.2, 0.2, 0.2, 0.2, 0.2, 0.2}},
+	}
+
+	for _, test := range tests {
+		net := dense(t, operators.AvgPool1D(test.window, test.stride), len(inputs))
+		net.SetReduction(bs.ReduceSum)
+
+		outs, err := net.GetOutputs(inputs)
+		if err != nil {
+			t.Fatal(err)
+		} else if !equal(outs, test.outs) {
+			t.Errorf("AvgPool1D(%d, %d): outputs are %v, expected %v", test.window, test.stride, outs, test.outs)
+			continue
+		}
+
+		// with a squared error cost, the delta of each output is (out - target)
+		targets := make([]float64, len(outs))
+		for i := range targets {
+			targets[i] = outs[i] - test.deltas[i]
+		}
+
+		ds, err := net.InputGradients(inputs, targets, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if !equal(ds, test.inDeltas) {
+			t.Errorf("AvgPool1D(%d, %d): input deltas are %v, expected %v", test.window, test.stride, ds, test.inDeltas)
+		}
+	}
+}
+
+func TestAvgPool1DSizing(t *testing.T) {
+	invalid := []struct{ window, stride, inputs int }{
+		{5, 1, 4}, // window larger than inputs
+		{2, 2, 5}, // doesn't divide evenly
+		{2, 3, 8}, // stride larger than window
+		{0, 1, 4},
+	}
+
+	for _, test := range invalid {
+		net := new(bs.Network)
+		n := net.Add(operators.AvgPool1D(test.window, test.stride), net.AddInput([]int{test.inputs}))
+
+		if n != nil || net.Error() == nil {
+			t.Errorf("AvgPool1D(%d, %d) with %d inputs was added without error", test.window, test.stride, test.inputs)
+		} else if _, ok := net.Error().(bs.GetShapeError); !ok {
+			t.Errorf("AvgPool1D(%d, %d) with %d inputs gave %T, expected GetShapeError", test.window, test.stride, test.inputs, net.Error())
+		}
+	}
+}
+
+func TestAvgPool1DGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.AvgPool1D(2, 2), 8)
+	testutil.AssertOperatorGradient(t, operators.AvgPool1D(3, 1), 7)
+}
+
+func TestMaxPool1DGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.MaxPool1D(2, 2), 8)
+	testutil.AssertOperatorGradient(t, operators.MaxPool1D(3, 1), 7)
+}
+
+func TestMaxPool1D(t *testing.T) {
+	// ties between equal values give the delta only to the first of them
+	inputs := []float64{3, 3, 1, 5, 5, 2}
+
+	tests := []struct {
+		window, stride   int
+		outs             []float64
+		deltas, inDeltas []float64
+	}{
+		{2, 2, []float64{3, 5, 5}, []float64{1, 2, 3}, []float64{1, 0, 0, 2, 3, 0}},
+		{3, 1, []float64{3, 5, 5, 5}, []float64{1, 2, 3, 4}, []float64{1, 0, 0, 9, 0, 0}},
+	}
+
+	for _, test := range tests {
+		net := dense(t, operators.MaxPool1D(test.window, test.stride), len(inputs))
+		net.SetReduction(bs.ReduceSum)
+
+		outs, err := net.GetOutputs(inputs)
+		if err != nil {
+			t.Fatal(err)
+		} else if !equal(outs, test.outs) {
+			t.Errorf("MaxPool1D(%d, %d): outputs are %v, expected %v", test.window, test.stride, outs, test.outs)
+			continue
+		}
+
+		targets := make([]float64, len(outs))
+		for i := range targets {
+			targets[i] = outs[i] - test.deltas[i]
+		}
+
+		ds, err := net.InputGradients(inputs, targets, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if !equal(ds, test.inDeltas) {
+			t.Errorf("MaxPool1D(%d, %d): input deltas are %v, expected %v", test.window, test.stride, ds, test.inDeltas)
+		}
+	}
+}
+
+func TestMaxPool1DSizing(t *testing.T) {
+	invalid := []struct{ window, stride, inputs int }{
+		{5, 1, 4},
+		{2, 2, 5},
+		{2, 3, 8},
+	}
+
+	for _, test := range invalid {
+		net := new(bs.Network)
+		n := net.Add(operators.MaxPool1D(test.window, test.stride), net.AddInput([]int{test.inputs}))
+
+		if n != nil || net.Error() == nil {
+			t.Errorf("MaxPool1D(%d, %d) with %d inputs was added without error", test.window, test.stride, test.inputs)
+		} else if _, ok := net.Error().(bs.GetShapeError); !ok {
+			t.Errorf("MaxPool1D(%d, %d) with %d inputs gave %T, expected GetShapeError", test.window, test.stride, test.inputs, net.Error())
+		}
+	}
+}
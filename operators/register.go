@@ -14,6 +14,7 @@ func init() {
 		func() bs.Operator { return Softplus() },
 		func() bs.Operator { return Softsign() },
 		func() bs.Operator { return Neurons(0) },
+		func() bs.Operator { return Neurons32(0) },
 		func() bs.Operator { return AvgPool() },
 		func() bs.Operator { return MaxPool() },
 		func() bs.Operator { return Softmax() },
@@ -24,6 +25,17 @@ func init() {
 		func() bs.Operator { return ReLU() },
 		func() bs.Operator { return ELU() },
 		func() bs.Operator { return Add() },
+		func() bs.Operator { return GELU() },
+		func() bs.Operator { return Normalize(nil, nil) },
+		func() bs.Operator { return Flatten() },
+		func() bs.Operator { return Dropout(0) },
+		func() bs.Operator { return Bounded(0, 1) },
+		func() bs.Operator { return Impute() },
+		func() bs.Operator { return ImputeLearned() },
+		func() bs.Operator { return WeightedAdd(false) },
+		func() bs.Operator { return LayerNorm() },
+		func() bs.Operator { return Scale(1) },
+		func() bs.Operator { return Embedding(0, 0) },
 	}
 
 	if err := bs.RegisterAll(list); err != nil {
@@ -4,9 +4,11 @@
 // * Parametric ReLU
 // * ELU
 // * Softplus (because it's similar)
+// * GELU
 package operators
 
 import (
+	"github.com/pkg/errors"
 	bs "github.com/sharnoff/badstudent"
 	"math"
 )
@@ -40,6 +42,10 @@ func (t relu) Deriv(n *bs.Node, index int) float64 {
 	return math.Max(n.Value(index), 0)
 }
 
+func (t relu) Saturated(n *bs.Node, index int) bool {
+	return n.Value(index) == 0
+}
+
 // ****************************************
 // Leaky ReLU
 // ****************************************
@@ -99,6 +105,15 @@ func (t *prelu) TypeString() string {
 }
 
 func (t *prelu) Finalize(n *bs.Node) error {
+	// if it's been loaded from a file...
+	if len(t.Ws) != 0 {
+		if len(t.Ws) != n.Size() {
+			return errors.Errorf("Number of saved weights not equal to size of Node (%d != %d)", len(t.Ws), n.Size())
+		}
+
+		return nil
+	}
+
 	t.Ws = make([]float64, n.Size())
 	return nil
 }
@@ -192,10 +207,58 @@ func (t softplus) Finalize(n *bs.Node) error {
 }
 
 func (t softplus) Value(in float64, index int) float64 {
-	return math.Log(1 + math.Exp(in))
+	// log(1 + e^x), rearranged so that e^x can't overflow for large x:
+	// max(x, 0) + log(1 + e^-|x|)
+	return math.Max(in, 0) + math.Log1p(math.Exp(-math.Abs(in)))
 }
 
 func (t softplus) Deriv(n *bs.Node, index int) float64 {
 	// 1 / (1 + e^-x)
 	return 1.0 / (1 + math.Exp(-n.InputValue(index)))
 }
+
+// ****************************************
+// GELU
+// ****************************************
+
+type gelu int8
+
+// GELU returns the Gaussian Error Linear Unit activation function: x * Φ(x), where Φ is the
+// cumulative distribution function of the standard normal distribution. It is calculated with the
+// usual tanh approximation:
+//	0.5 * x * (1 + tanh(√(2/π) * (x + 0.044715 * x^3)))
+func GELU() gelu {
+	return gelu(0)
+}
+
+// constants for the tanh approximation of GELU
+const (
+	geluScale float64 = 0.7978845608028654 // √(2/π)
+	geluCubic float64 = 0.044715
+)
+
+func (t gelu) TypeString() string {
+	return "gelu"
+}
+
+func (t gelu) Finalize(n *bs.Node) error {
+	return nil
+}
+
+func (t gelu) Value(in float64, index int) float64 {
+	return 0.5 * in * (1 + math.Tanh(geluScale*(in+geluCubic*in*in*in)))
+}
+
+func (t gelu) Deriv(n *bs.Node, index int) float64 {
+	in := n.InputValue(index)
+	th := math.Tanh(geluScale * (in + geluCubic*in*in*in))
+
+	// sech^2 of the inner term. For very large inputs, this is zero; returning early avoids
+	// multiplying it by infinity.
+	sech2 := 1 - th*th
+	if sech2 == 0 {
+		return 0.5 * (1 + th)
+	}
+
+	return 0.5*(1+th) + 0.5*in*sech2*geluScale*(1+3*geluCubic*in*in)
+}
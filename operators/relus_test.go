@@ -0,0 +1,76 @@
+package operators_test
+
+import (
+	"math"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestGELUGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.GELU(), 5)
+}
+
+func TestSoftplusGradient(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.Softplus(), 5)
+}
+
+func TestSoftplusExtremes(t *testing.T) {
+	inputs := []float64{-1000, -30, 0, 30, 1000}
+	// reference values: log(1+e^x) and 1/(1+e^-x), from exact arithmetic
+	outs := []float64{0, 9.357622968839299e-14, math.Ln2, 30.000000000000092, 1000}
+	derivs := []float64{0, 9.357622968839299e-14, 0.5, 0.9999999999999064, 1}
+
+	net := dense(t, operators.Softplus(), len(inputs))
+	net.SetReduction(bs.ReduceSum)
+
+	got, err := net.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// with targets one below the outputs, the deltas of the outputs are all one, so the deltas of
+	// the inputs are the derivatives
+	targets := make([]float64, len(got))
+	for i := range targets {
+		targets[i] = got[i] - 1
+	}
+
+	ds, err := net.InputGradients(inputs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tiny values are compared relative to their size
+	close := func(a, b float64) bool { return a == b || math.Abs(a-b) <= 1e-12*math.Abs(b) }
+
+	for i, x := range inputs {
+		if !close(got[i], outs[i]) {
+			t.Errorf("Softplus(%v) = %v, expected %v", x, got[i], outs[i])
+		}
+
+		if !close(ds[i], derivs[i]) {
+			t.Errorf("Derivative of Softplus at %v is %v, expected %v", x, ds[i], derivs[i])
+		}
+	}
+}
+
+func TestGELUApproximation(t *testing.T) {
+	inputs := []float64{-4, -2, -1, -0.5, 0, 0.5, 1, 2, 4}
+
+	net := dense(t, operators.GELU(), len(inputs))
+	outs, err := net.GetOutputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tanh approximation is within a thousandth of the exact value, x * Φ(x)
+	for i, x := range inputs {
+		exact := 0.5 * x * (1 + math.Erf(x/math.Sqrt2))
+		if e := math.Abs(outs[i] - exact); e > 1e-3 {
+			t.Errorf("GELU(%v) = %v, which differs from the exact value %v by %v", x, outs[i], exact, e)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package operators
+
+import (
+	"github.com/pkg/errors"
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/tensors"
+)
+
+type reshape struct {
+	// the dimensions of the output. If nil, the output is flattened to one dimension.
+	Dims []int
+}
+
+// Reshape returns an operator that changes the dimensions of its inputs without changing their
+// values. Deltas are likewise passed back unchanged. The total size of the given dimensions must
+// be equal to the total size of the inputs. Multiple inputs are concatenated, in order.
+//
+// Reshape is mainly useful for going between multi-dimensional operators (like Conv) and those
+// that are not.
+func Reshape(dims ...int) *reshape {
+	return &reshape{dims}
+}
+
+// Flatten returns a Reshape operator that gives its inputs as a single dimension.
+func Flatten() *reshape {
+	return &reshape{}
+}
+
+func (t *reshape) TypeString() string {
+	return "reshape"
+}
+
+func (t *reshape) Finalize(n *bs.Node) error {
+	return nil
+}
+
+func (t *reshape) Get() interface{} {
+	return *t
+}
+
+func (t *reshape) Blank() interface{} {
+	return t
+}
+
+func (t *reshape) OutputShape(inputs []*bs.Node) (tensors.Tensor, error) {
+	var size int
+	for _, in := range inputs {
+		size += in.Size()
+	}
+
+	if t.Dims == nil {
+		return tensors.NewTensor([]int{size}), nil
+	}
+
+	outSize := 1
+	for i, d := range t.Dims {
+		if d < 1 {
+			return tensors.Tensor{}, errors.Errorf("Dimension %d is less than 1 (%d)", i, d)
+		}
+
+		outSize *= d
+	}
+
+	if outSize != size {
+		return tensors.Tensor{}, errors.Errorf("Size of dimensions not equal to total size of inputs (%d != %d)", outSize, size)
+	}
+
+	dims := make([]int, len(t.Dims))
+	copy(dims, t.Dims)
+	return tensors.NewTensor(dims), nil
+}
+
+func (t *reshape) Evaluate(n *bs.Node, values []float64) {
+	copy(values, n.AllInputs())
+}
+
+func (t *reshape) InputDeltas(n *bs.Node) []float64 {
+	ds := make([]float64, n.NumInputs())
+	for i := range ds {
+		ds[i] = n.Delta(i)
+	}
+
+	return ds
+}
@@ -0,0 +1,54 @@
+package operators_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+)
+
+func TestReshape(t *testing.T) {
+	net := new(bs.Network).HandleErrors()
+	in := net.AddInput([]int{2, 3})
+	grid := net.Add(operators.Reshape(3, 2), in)
+	flat := net.Add(operators.Flatten(), grid)
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), flat); err != nil {
+		t.Fatal(err)
+	}
+
+	if dims := grid.Dims(); len(dims) != 2 || dims[0] != 3 || dims[1] != 2 {
+		t.Errorf("Reshaped dimensions are %v, expected [3 2]", dims)
+	} else if dims := flat.Dims(); len(dims) != 1 || dims[0] != 6 {
+		t.Errorf("Flattened dimensions are %v, expected [6]", dims)
+	}
+
+	inputs := []float64{1, -2, 3, 0.5, 7, -8}
+	if outs, err := net.GetOutputs(inputs); err != nil {
+		t.Fatal(err)
+	} else if !equal(outs, inputs) {
+		t.Errorf("Outputs are %v, expected the inputs %v", outs, inputs)
+	}
+
+	deltas := []float64{0.5, 1, -1, 2, 0, 3}
+	targets := make([]float64, len(inputs))
+	for i := range targets {
+		targets[i] = inputs[i] - deltas[i]
+	}
+
+	if ds, err := net.InputGradients(inputs, targets, nil); err != nil {
+		t.Fatal(err)
+	} else if !equal(ds, deltas) {
+		t.Errorf("Input deltas are %v, expected %v", ds, deltas)
+	}
+}
+
+func TestReshapeSize(t *testing.T) {
+	net := new(bs.Network)
+	if n := net.Add(operators.Reshape(4, 2), net.AddInput([]int{2, 3})); n != nil || net.Error() == nil {
+		t.Errorf("Reshape from 6 values to 8 was added without error")
+	}
+}
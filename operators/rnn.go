@@ -0,0 +1,24 @@
+package operators
+
+import (
+	bs "github.com/sharnoff/badstudent"
+)
+
+// SimpleRNN creates a basic (Elman) recurrent layer, given the Node to provide its input. At each
+// time-step, the hidden state is calculated by a layer of Neurons, followed by Tanh, from both the
+// input and the hidden state of the previous time-step.
+//
+// Returns the hidden state, without delay, so it can be used as Network outputs, in addition to
+// the loop that provides the previous hidden state, with a delay of 1. As with LSTM, any Nodes
+// that take the hidden state as input will have to add their own delay if they are part of another
+// loop.
+//
+// The hidden state can be reset between sequences with (*Network).ClearDelays().
+func SimpleRNN(net *bs.Network, size int, input *bs.Node) (hidden, hiddenDelay *bs.Node) {
+	hLoop := net.Placeholder([]int{size})      // previous hidden state
+	ns := net.Add(Neurons(size), input, hLoop) // combination of input and previous state
+	h := net.Add(Tanh(), ns)                   // hidden state
+	hLoop.Replace(Identity(), h).SetDelay(1)
+
+	return h, hLoop
+}
@@ -0,0 +1,165 @@
+package operators_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+)
+
+// echoSequences returns 'count' sequences of 'length' random inputs, where the target output at
+// each time-step is the input from the step before. The first step of each sequence has no target.
+func echoSequences(rng *rand.Rand, count, length int) [][][]float64 {
+	var dataset [][][]float64
+	for s := 0; s < count; s++ {
+		prev := []float64(nil)
+		for i := 0; i < length; i++ {
+			in := []float64{float64(rng.Intn(2))*2 - 1}
+			dataset = append(dataset, [][]float64{in, prev})
+			prev = in
+		}
+	}
+
+	return dataset
+}
+
+// rnn returns a finalized Network with a SimpleRNN layer of the given size and a single linear
+// output, with random weights
+func rnn(t *testing.T, rng *rand.Rand, size int, lr float64) *bs.Network {
+	t.Helper()
+
+	net := new(bs.Network).HandleErrors()
+	hidden, _ := operators.SimpleRNN(net, size, net.AddInput([]int{1}))
+	out := net.Add(operators.Neurons(1), hidden)
+
+	net.AddHP("learning-rate", hyperparams.Constant(lr))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := net.Weights()
+	for i := range ws {
+		ws[i] = (rng.Float64()*2 - 1) * 0.8
+	}
+	net.SetWeights(ws)
+
+	return net
+}
+
+// The gradients from backpropagation through time are checked against finite differences of the
+// total cost of a sequence. With SGD and a learning rate of one, the change to each weight after
+// training on the sequence as a single batch is exactly the negative of its gradient.
+func TestSimpleRNNGradient(t *testing.T) {
+	const length = 6
+
+	rng := rand.New(rand.NewSource(1))
+	net := rnn(t, rng, 3, 1)
+	seq := echoSequences(rng, 1, length)
+
+	cost := func(ws []float64) float64 {
+		net.SetWeights(ws)
+		net.ResetState()
+
+		var sum float64
+		for _, d := range seq {
+			outs, err := net.GetOutputs(d[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if d[1] != nil {
+				sum += 0.5 * (outs[0] - d[1][0]) * (outs[0] - d[1][0])
+			}
+		}
+
+		net.ResetState()
+		return sum
+	}
+
+	const epsilon = 1e-6
+
+	weights := net.Weights()
+	numeric := make([]float64, len(weights))
+	for i := range weights {
+		ws := append([]float64(nil), weights...)
+		ws[i] = weights[i] + epsilon
+		plus := cost(ws)
+		ws[i] = weights[i] - epsilon
+		minus := cost(ws)
+
+		numeric[i] = (plus - minus) / (2 * epsilon)
+	}
+
+	net.SetWeights(weights)
+	data, err := bs.SeqData(seq, length, length)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := net.Train(bs.TrainArgs{TrainData: data, RunCondition: bs.TrainUntil(length)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, w := range net.Weights() {
+		if grad := weights[i] - w; math.Abs(grad-numeric[i]) > 1e-6 {
+			t.Errorf("Gradient of weight %d is %v, estimated %v", i, grad, numeric[i])
+		}
+	}
+}
+
+func TestSimpleRNNEcho(t *testing.T) {
+	const length = 5
+
+	rng := rand.New(rand.NewSource(1))
+	net := rnn(t, rng, 4, 0.05)
+
+	// before training, the outputs shouldn't already match
+	test := echoSequences(rng, 10, length)
+	echoError := func() float64 {
+		var worst float64
+		for s := 0; s < len(test); s += length {
+			inputs := make([][]float64, length)
+			for i := range inputs {
+				inputs[i] = test[s+i][0]
+			}
+
+			net.ResetState()
+			outs, err := net.StepSequence(inputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 1; i < length; i++ {
+				worst = math.Max(worst, math.Abs(outs[i][0]-test[s+i][1][0]))
+			}
+		}
+
+		return worst
+	}
+
+	if e := echoError(); e < 0.5 {
+		t.Fatalf("Untrained Network already echoes its inputs (error %v)", e)
+	}
+
+	data, err := bs.SeqData(echoSequences(rng, 50, length), 1, length)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = net.Train(bs.TrainArgs{
+		TrainData:    data,
+		RunCondition: bs.TrainUntil(200 * 50 * length),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := echoError(); e > 0.2 {
+		t.Errorf("After training, outputs differ from the previous inputs by up to %v", e)
+	}
+}
@@ -45,10 +45,18 @@ func (t softmax) Evaluate(n *bs.Node, values []float64) {
 	}
 }
 
+// InputDeltas uses the full Jacobian of softmax, because every output depends on every input:
+// d(value i)/d(input j) = value i * ([i == j] - value j). Summed over the outputs, the delta of
+// input j is value j * (delta j - sum(delta i * value i)).
 func (t softmax) InputDeltas(n *bs.Node) []float64 {
+	var weighted float64
+	for i := 0; i < n.Size(); i++ {
+		weighted += n.Delta(i) * n.Value(i)
+	}
+
 	ds := make([]float64, n.Size())
 	for i := range ds {
-		ds[i] = n.Value(i) * (1 - n.Value(i))
+		ds[i] = n.Value(i) * (n.Delta(i) - weighted)
 	}
 
 	return ds
@@ -28,10 +28,9 @@ func (s sgd) Run(n *bs.Node, a bs.Adjustable, ch []float64) {
 		ch[i] += -1 * η * a.Grad(n, i)
 	}
 
-	// just arbitrary constants
-	threadsPerCPU := 1
+	// just an arbitrary constant
 	opsPerThread := runtime.NumCPU() * 2
-	utils.MultiThread(0, len(ch), f, opsPerThread, threadsPerCPU)
+	utils.MultiThreadN(0, len(ch), f, opsPerThread, n.Workers())
 }
 
 func (s sgd) Needs() []string {
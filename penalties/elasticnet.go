@@ -2,7 +2,6 @@ package penalties
 
 import (
 	bs "github.com/sharnoff/badstudent"
-	"math"
 )
 
 type elasticNet struct {
@@ -24,7 +23,7 @@ func (p *elasticNet) TypeString() string {
 
 func (p *elasticNet) Penalize(n *bs.Node, adj bs.Adjustable, index int) float64 {
 	w := adj.Weights()[index]
-	return adj.Grad(n, index) + p.λ * ((1 - p.α) *2*w + p.α*math.Copysign(1, w))
+	return adj.Grad(n, index) + p.λ*((1-p.α)*2*w+p.α*sign(w))
 }
 
 func (p *elasticNet) Get() interface{} {
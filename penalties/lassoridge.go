@@ -2,7 +2,6 @@ package penalties
 
 import (
 	bs "github.com/sharnoff/badstudent"
-	"math"
 )
 
 // **********************************************
@@ -29,7 +28,19 @@ func (p *l1) TypeString() string {
 func (p *l1) Penalize(n *bs.Node, adj bs.Adjustable, index int) float64 {
 	λ := float64(*p)
 	w := adj.Weights()[index]
-	return adj.Grad(n, index) + λ * math.Copysign(1, w)
+	return adj.Grad(n, index) + λ*sign(w)
+}
+
+// sign returns the sign of x: -1, 0, or 1. This is used as the subgradient of |x|, which is taken
+// to be 0 at x = 0.
+func sign(x float64) float64 {
+	if x > 0 {
+		return 1
+	} else if x < 0 {
+		return -1
+	}
+
+	return 0
 }
 
 func (p *l1) Get() interface{} {
@@ -0,0 +1,66 @@
+package penalties_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	_ "github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+	"github.com/sharnoff/badstudent/penalties"
+)
+
+func TestL1Sparsity(t *testing.T) {
+	const features, samples = 5, 200
+
+	// only the first feature is used by the target; the rest are noise
+	rng := rand.New(rand.NewSource(1))
+	dataset := make([][][]float64, samples)
+	for i := range dataset {
+		ins := make([]float64, features)
+		for f := range ins {
+			ins[f] = rng.Float64()*2 - 1
+		}
+
+		dataset[i] = [][]float64{ins, {2*ins[0] + 1}}
+	}
+
+	net := new(bs.Network).SetPenalty(penalties.L1(0.05))
+	out := net.Add(operators.Neurons(1), net.AddInput([]int{features})).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0.05))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := net.Weights()
+	for i := range ws {
+		ws[i] = 1
+	}
+	net.SetWeights(ws)
+
+	args := bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(50 * samples)}
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	// the weights of the inputs come first, followed by the bias
+	ws = net.Weights()
+	for i := 1; i < features; i++ {
+		if math.Abs(ws[i]) > 0.01 {
+			t.Errorf("Weight of noise feature %d is %v, expected it to be near zero", i, ws[i])
+		}
+	}
+
+	if ws[0] < 1.5 {
+		t.Errorf("Weight of the used feature is %v, expected it to stay near 2", ws[0])
+	}
+
+	// the bias isn't penalized, so it stays at the value given by the data
+	if b := ws[features]; math.Abs(b-1) > 0.02 {
+		t.Errorf("Bias is %v, expected it to be unaffected by L1 (near 1)", b)
+	}
+}
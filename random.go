@@ -0,0 +1,97 @@
+package badstudent
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// splitMix is a rand.Source implementing the SplitMix64 generator. Unlike the sources given by
+// math/rand, its entire state is a single number, so it can be saved and restored exactly (see
+// SaveTrainingState).
+type splitMix struct {
+	state uint64
+}
+
+func (s *splitMix) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *splitMix) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (s *splitMix) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// lockedSource is a rand.Source that is safe for concurrent use, so that the shared generator can
+// be used from multiple goroutines.
+type lockedSource struct {
+	mux sync.Mutex
+	src splitMix
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.src.Seed(seed)
+}
+
+// getState returns the state of the source, without advancing it
+func (s *lockedSource) getState() uint64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.src.state
+}
+
+// setState restores a state given by getState
+func (s *lockedSource) setState(state uint64) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.src.state = state
+}
+
+var source = &lockedSource{src: splitMix{uint64(time.Now().UnixNano())}}
+var generator = rand.New(source)
+
+// Seed sets the seed of the random number generator that is shared by badstudent and its
+// subpackages, so that results can be reproduced. Until Seed is called, the generator is seeded
+// from the current time.
+//
+// The components that use randomness are:
+//	* initializers: Uniform, Normal, and TruncNormal, along with every Initializer that uses them
+//	* operators: Dropout
+// Randomness used by anything else (e.g. shuffling a dataset before providing it) is not affected.
+// Shuffling with TrainArgs.Shuffle uses its own generator, which is seeded from this one only if
+// TrainArgs.Seed is zero.
+//
+// Note that results will only be identical if the order that random values are requested is the
+// same, so Networks should be constructed in the same order between runs.
+func Seed(seed int64) {
+	source.Seed(seed)
+}
+
+// Rand returns the random number generator that is shared by badstudent and its subpackages. It is
+// safe for concurrent use. Any custom types that require randomness should use it, so that their
+// results can be reproduced by Seed.
+func Rand() *rand.Rand {
+	return generator
+}
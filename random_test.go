@@ -0,0 +1,39 @@
+package badstudent_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/initializers"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestSeed(t *testing.T) {
+	// build returns the initial weights of a Network, as chosen by a random Initializer
+	build := func(seed int64) []float64 {
+		bs.Seed(seed)
+
+		net := new(bs.Network)
+		l := net.Add(operators.Neurons(4), net.AddInput([]int{3})).Opt(optimizers.SGD()).Init(initializers.Xavier())
+		l = net.Add(operators.Neurons(2), net.Add(operators.Tanh(), l)).Opt(optimizers.SGD()).Init(initializers.Xavier())
+
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+			t.Fatal(err)
+		}
+
+		return net.Weights()
+	}
+
+	a, b := build(1), build(1)
+	if !sliceEqual(a, b, 0) {
+		t.Errorf("Networks with the same seed had different initial weights:\n%v\n%v", a, b)
+	}
+
+	if c := build(2); sliceEqual(a, c, 0) {
+		t.Errorf("Networks with different seeds had the same initial weights")
+	}
+}
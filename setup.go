@@ -95,6 +95,7 @@ func (net *Network) AddInput(dims []int) *Node {
 		outputs:     new(nodeGroup),
 		values:      values,
 		outputIndex: -1,
+		lrScale:     1,
 
 		delay:       make(chan []float64, 0),
 		delayDeltas: make(chan []float64, 0),
@@ -139,11 +140,15 @@ func (err OperatorFinalizeError) Error() string {
 //	(1) len(inputs) == 0,
 //	(2) any Node in inputs is nil,
 //	(3) any Node in inputs belongs to a different Network than 'net',
-//	(4) op is not valid (i.e. does not implement Layer or Elementwise), or
-//	(5) op.Finalize() returns error
+//	(4) any Node in inputs has a size of zero,
+//	(5) op is not valid (i.e. does not implement Layer or Elementwise),
+//	(6) op.OutputShape() returns error, or gives a shape with a size of zero,
+//	(7) op is a FixedInput, and the total size of the inputs is not what it requires, or
+//	(8) op.Finalize() returns error
 // (0) and (1) give type NilArgError's, (2) gives ErrNilInputNode, (3) gives
-// ErrDifferentNetworkInput to BOTH Networks, (4) gives ErrInvalidOperator, and (5) gives type
-// OperatorFinalizeError.
+// ErrDifferentNetworkInput to BOTH Networks, (4) gives ErrZeroSizeInput, (5) gives
+// ErrInvalidOperator, (6) gives type GetShapeError or ErrZeroSize, (7) gives type
+// SizeMismatchError, and (8) gives type OperatorFinalizeError.
 func (net *Network) Add(op Operator, inputs ...*Node) *Node {
 	if net == nil {
 		panic(ErrNilNet)
@@ -172,6 +177,9 @@ func (net *Network) Add(op Operator, inputs ...*Node) *Node {
 			in.host.setError(ErrDifferentNetworkInput)
 			net.setError(ErrDifferentNetworkInput)
 			return nil
+		} else if in.Size() == 0 {
+			net.setError(ErrZeroSizeInput)
+			return nil
 		}
 	}
 
@@ -184,6 +192,14 @@ func (net *Network) Add(op Operator, inputs ...*Node) *Node {
 	if err != nil {
 		net.setError(err)
 		return nil
+	} else if shape.Size() == 0 {
+		net.setError(ErrZeroSize)
+		return nil
+	}
+
+	if err := checkInputSize(op, inputs); err != nil {
+		net.setError(err)
+		return nil
 	}
 
 	// we don't yet know that all of the inputs are safe, but we're going to make the Node anyways
@@ -195,6 +211,7 @@ func (net *Network) Add(op Operator, inputs ...*Node) *Node {
 		outputs:     new(nodeGroup),
 		values:      shape,
 		hyperParams: make(map[string]HyperParameter),
+		lrScale:     1,
 
 		delay:       make(chan []float64, 0),
 		delayDeltas: make(chan []float64, 0),
@@ -253,6 +270,26 @@ func ConcatShape(inputs []tensors.Tensor) (shape tensors.Tensor, err error) {
 	return tensors.NewTensor([]int{size}), nil
 }
 
+// checkInputSize returns type SizeMismatchError if the Operator is a FixedInput and the total size
+// of the inputs does not match what it requires.
+func checkInputSize(op Operator, inputs []*Node) error {
+	f, ok := op.(FixedInput)
+	if !ok || f.InputSize() < 0 {
+		return nil
+	}
+
+	var size int
+	for _, in := range inputs {
+		size += in.Size()
+	}
+
+	if size != f.InputSize() {
+		return SizeMismatchError{f.InputSize(), size, "Node inputs"}
+	}
+
+	return nil
+}
+
 func getShape(op Operator, inputs []*Node) (shape tensors.Tensor, err error) {
 	if l, ok := op.(Layer); ok {
 		// because of tensor constructors, we know that the dimensions should be fine. If someone's
@@ -285,6 +322,7 @@ func getShape(op Operator, inputs []*Node) (shape tensors.Tensor, err error) {
 // not stay up-to-date):
 //	(0) If any dimensions are < 0, tensors.DimsValueError;
 //	(1) If len(dims) == 0, tensors.ErrZeroDims
+// Placeholder will also set ErrZeroSize if any dimension is equal to zero.
 func (net *Network) Placeholder(dims []int) *Node {
 	// placeholders are marked by setting Node.inputs != nil and Node.op == nil
 	// all placeholders have values defined.
@@ -300,6 +338,13 @@ func (net *Network) Placeholder(dims []int) *Node {
 
 	net.initialize()
 
+	for _, d := range dims {
+		if d == 0 {
+			net.setError(ErrZeroSize)
+			return nil
+		}
+	}
+
 	values, err := tensors.NewTensorSafe(dims)
 	if err != nil {
 		net.setError(err)
@@ -311,6 +356,7 @@ func (net *Network) Placeholder(dims []int) *Node {
 		values:  values,
 		inputs:  new(nodeGroup),
 		outputs: new(nodeGroup),
+		lrScale: 1,
 	}
 
 	n.id = net.newID(n)
@@ -327,7 +373,8 @@ func (n *Node) isPlaceholder() bool {
 	return n.inputs != nil && n.op == nil
 }
 
-// UnequalShapeError documents different shapes of Tensors when Replace()-ing a placeholder Node.
+// UnequalShapeError documents different shapes of Tensors when Replace()-ing a placeholder Node, or
+// when changing the Operator of a Node with SetOperator.
 type UnequalShapeError struct {
 	Placeholder, Replaced tensors.Tensor
 }
@@ -396,6 +443,11 @@ func (n *Node) Replace(op Operator, inputs ...*Node) *Node {
 		return nil
 	}
 
+	if err := checkInputSize(op, inputs); err != nil {
+		n.host.setError(err)
+		return nil
+	}
+
 	if !tensors.Equals(n.values.Interpreter, shape.Interpreter) {
 		n.host.setError(UnequalShapeError{n.values, shape})
 		return nil
@@ -436,11 +488,87 @@ func (n *Node) Replace(op Operator, inputs ...*Node) *Node {
 	return n
 }
 
+// SetOperator replaces the Operator of a Node that has already been added, keeping its inputs and
+// the Nodes that take input from it. The new Operator must give the same shape as the old one, so
+// that the rest of the Network is unaffected. The weights of the old Operator are discarded, and
+// those of the new one will be initialized with the default once the Network is finalized; because
+// Init sets the weights immediately, it must be called again after SetOperator to use a different
+// Initializer. If any error is returned, the Node will not have been changed.
+//
+// SetOperator has several error conditions:
+//	(0) the Network has already been finalized,
+//	(1) the Node is an input or a placeholder (see Replace),
+//	(2) op is nil,
+//	(3) op is not valid (i.e. does not implement Layer or Elementwise),
+//	(4) op.OutputShape() returns error,
+//	(5) op is a FixedInput, and the total size of the inputs is not what it requires,
+//	(6) the shape given by op is not equal to the current shape of the Node, or
+//	(7) op.Finalize() returns error
+// (0) gives ErrNetFinalized, (1) gives ErrNoOperator, (2) gives type NilArgError, (3) gives
+// ErrInvalidOperator, (4) gives type GetShapeError, (5) gives type SizeMismatchError, (6) gives
+// type UnequalShapeError, and (7) gives type OperatorFinalizeError. If the Network has already
+// encountered an error, SetOperator does nothing and returns it. If PanicErrors() has been called,
+// errors will be panicked, not returned.
+func (n *Node) SetOperator(op Operator) error {
+	if err := n.host.Error(); err != nil {
+		return err
+	}
+
+	err := n.setOperator(op)
+	if err != nil && n.host.panicErrors {
+		panic(err)
+	}
+
+	return err
+}
+
+func (n *Node) setOperator(op Operator) error {
+	if n.host.stat >= finalized {
+		return ErrNetFinalized
+	} else if n.op == nil {
+		return ErrNoOperator
+	} else if op == nil {
+		return NilArgError{"Operator"}
+	} else if !isValid(op) {
+		return ErrInvalidOperator
+	}
+
+	shape, err := getShape(op, n.inputs.nodes)
+	if err != nil {
+		return err
+	} else if err := checkInputSize(op, n.inputs.nodes); err != nil {
+		return err
+	} else if !tensors.Equals(n.values.Interpreter, shape.Interpreter) {
+		return UnequalShapeError{n.values, shape}
+	}
+
+	lyr, elem, adj := castAll(op)
+
+	if err := op.Finalize(n); err != nil {
+		return OperatorFinalizeError{n, err}
+	}
+
+	n.op, n.lyr, n.elem, n.adj = op, lyr, elem, adj
+
+	// the new weights have not been set
+	n.initialized = false
+
+	return nil
+}
+
 // Finalize completes the structure of the Network by adding a cost function and declaring one or
 // more Nodes as the outputs to the Network. If any error is returned, the Network and output Nodes
 // will not have been changed. Additionally, if an error has already been encountered earlier by
 // the Network, Finalize will do nothing and return that error.
 //
+// If multiple output Nodes are given, the outputs of the Network (and the targets given for
+// training) are the concatenation of their values, in the order provided.
+//
+// The outputs can only be set once. After Finalize has succeeded, any further calls will return
+// ErrNetFinalized, leaving the existing outputs exactly as they were. Because a failed call makes
+// no changes, Finalize can simply be called again with a different set of outputs; only the set
+// given to the successful call is used.
+//
 // Finalize will not (intentionally) panic, but does have several error conditions (in order of
 // precedence):
 // 	(0) net == nil:                           ErrNilNet,
@@ -493,10 +621,12 @@ func (net *Network) finalize(isLoading bool, cf CostFunction, outputs ...*Node)
 		return NilArgError{"Provided CostFunction"}
 	}
 
-	// this ensures that all given output Nodes remain unchanged
+	// this ensures that the Network and all given output Nodes remain unchanged
 	var allGood = false
 	defer func() {
 		if !allGood {
+			net.outputs = nil
+
 			for _, out := range outputs {
 				if out != nil {
 					out.outputIndex = -1
@@ -561,6 +691,15 @@ func (net *Network) finalize(isLoading bool, cf CostFunction, outputs ...*Node)
 
 	net.cf = cf
 
+	// apply the default penalty to all Nodes that don't already have one
+	if net.pen != nil {
+		for _, n := range net.nodesByID {
+			if n.pen == nil && n.adj != nil {
+				n.pen = net.pen
+			}
+		}
+	}
+
 	if net.defaultInit == nil {
 		net.defaultInit = defaultInitializer
 	}
@@ -577,14 +716,19 @@ func (net *Network) finalize(isLoading bool, cf CostFunction, outputs ...*Node)
 		// if it needs initializing
 		if n.adj != nil && !isLoading {
 
-			if net.defaultInit != nil {
-				net.defaultInit.Set(n, n.adj.Weights())
-			} else if defaultInitializer != nil {
-				defaultInitializer.Set(n, n.adj.Weights())
-			} else {
-				return NoInitializerError{n}
+			// Nodes that were given an Initializer by Init already have their weights set
+			if !n.initialized {
+				if net.defaultInit != nil {
+					net.defaultInit.Set(n, n.adj.Weights())
+				} else if defaultInitializer != nil {
+					defaultInitializer.Set(n, n.adj.Weights())
+				} else {
+					return NoInitializerError{n}
+				}
 			}
 
+			n.initBiases()
+			n.releaseWeights()
 		}
 	}
 
@@ -857,7 +1001,7 @@ func (n *Node) SetDelay(delay int) *Node {
 // Init initializes the weights of the Node. This is only required if the Node's Operator is
 // Adjustable. If not provided, the default Initializer (first from the Network, DefaultInit(),
 // then from the package-wide, SetDefaultInitializer()) will be used instead, assuming they are
-// set.
+// set. Biases are initialized separately, by BiasInit.
 //
 // Init should be prioritized last in method chains, if possible.
 //
@@ -874,9 +1018,61 @@ func (n *Node) Init(i Initializer) *Node {
 	}
 
 	i.Set(n, n.adj.Weights())
+	n.releaseWeights()
+	n.initialized = true
+	return n
+}
+
+// BiasInit sets the Initializer for the biases of the Node, separately from its other weights.
+// This only has an effect if the Node's Operator is Biased (e.g. Neurons or Conv). If not provided,
+// biases are initialized to zero. For ReLU networks, initializing the biases to a small positive
+// constant can help to prevent dead units.
+//
+// Unlike Init, the biases are not initialized until the Network is finalized, so that they are not
+// overwritten by the initialization of the other weights.
+//
+// BiasInit has the same panic and error conditions as Init.
+func (n *Node) BiasInit(i Initializer) *Node {
+	if n == nil || n.host.err != nil {
+		return n
+	} else if n.host.stat >= finalized {
+		panic(ErrNetFinalized)
+	} else if i == nil {
+		n.host.setError(NilArgError{"Initializer"})
+		return n
+	}
+
+	n.biasInit = i
 	return n
 }
 
+// initBiases initializes the biases of the Node, if its Operator is Biased, with the Initializer
+// given by BiasInit (or zero, if there was none).
+func (n *Node) initBiases() {
+	b, ok := n.adj.(Biased)
+	if !ok {
+		return
+	}
+
+	ws := n.adj.Weights()
+
+	var indexes []int
+	for i := range ws {
+		if b.IsBias(n, i) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	biases := make([]float64, len(indexes))
+	if n.biasInit != nil {
+		n.biasInit.Set(n, biases)
+	}
+
+	for i, index := range indexes {
+		ws[index] = biases[i]
+	}
+}
+
 // DefaultInit sets the Initializers of all Nodes in the Network that do not (or
 // will not) have Initializers directly set.
 //
@@ -905,3 +1101,38 @@ func (n *Node) SetName(name string) *Node {
 	n.name = name
 	return n
 }
+
+// SetTrainable sets whether or not the weights of the Node will be changed by training. Nodes are
+// trainable by default. Deltas are still passed through Nodes that are not trainable, so any Nodes
+// before them will be trained as usual. This can be used to fine-tune parts of a Network that has
+// already been trained.
+//
+// Unlike most other setup methods, SetTrainable can be called at any time, including after the
+// Network has been finalized. If the Node's Operator is not Adjustable, SetTrainable has no
+// measurable effect.
+func (n *Node) SetTrainable(trainable bool) *Node {
+	if n == nil {
+		return n
+	}
+
+	n.frozen = !trainable
+	return n
+}
+
+// SetLearningRateScale sets a multiplier for the "learning-rate" HyperParameter of the Node, so
+// that the effective learning rate used by its Optimizer is the usual value multiplied by 'scale'.
+// The default scale is 1. This allows different parts of a Network to be trained at different
+// rates -- for example, using a smaller rate for Nodes that have already been trained.
+//
+// Like SetTrainable, SetLearningRateScale can be called at any time. It will panic with
+// ErrInvalidLRScale if scale ≤ 0; SetTrainable should be used instead to stop training a Node.
+func (n *Node) SetLearningRateScale(scale float64) *Node {
+	if n == nil {
+		return n
+	} else if scale <= 0 {
+		panic(ErrInvalidLRScale)
+	}
+
+	n.lrScale = scale
+	return n
+}
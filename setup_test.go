@@ -0,0 +1,329 @@
+package badstudent_test
+
+import (
+	"testing"
+	"time"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestFixedInputSize(t *testing.T) {
+	// a convolution with fixed input dimensions requires exactly that many inputs in total, even
+	// when they're split between several Nodes
+	conv := func() bs.Operator { return operators.Conv1D(3, 1).InputDims(6) }
+
+	net := new(bs.Network)
+	if n := net.Add(conv(), net.AddInput([]int{3}), net.AddInput([]int{3})); n == nil || net.Error() != nil {
+		t.Fatalf("Matching inputs gave error: %v", net.Error())
+	}
+
+	net = new(bs.Network)
+	n := net.Add(conv(), net.AddInput([]int{3}), net.AddInput([]int{4}))
+
+	if n != nil {
+		t.Errorf("Mismatched inputs were added without error")
+	} else if err, ok := net.Error().(bs.SizeMismatchError); !ok {
+		t.Errorf("Mismatched inputs gave %T (%v), expected SizeMismatchError", net.Error(), net.Error())
+	} else if err.Expected != 6 || err.Given != 7 {
+		t.Errorf("Error was for %d inputs instead of %d, expected 7 instead of 6", err.Given, err.Expected)
+	}
+}
+
+func TestFinalizeCycle(t *testing.T) {
+	build := func(delay bool) (*bs.Network, *bs.Node) {
+		net := new(bs.Network)
+		loop := net.Placeholder([]int{2}).SetName("loop")
+		hidden := net.Add(operators.Neurons(2), net.AddInput([]int{2}), loop).SetName("hidden")
+		out := net.Add(operators.Tanh(), hidden).SetName("out")
+		loop.Replace(operators.Identity(), out)
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if delay {
+			loop.SetDelay(1)
+		}
+
+		return net, out
+	}
+
+	finalize := func(net *bs.Network, out *bs.Node) error {
+		done := make(chan error, 1)
+		go func() { done <- net.Finalize(costfuncs.MSE(), out) }()
+
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("Finalize did not return within one second")
+			return nil
+		}
+	}
+
+	err := finalize(build(false))
+	cycle, ok := err.(bs.InstantCycleError)
+	if !ok {
+		t.Fatalf("Cycle without delay gave %T (%v), expected InstantCycleError", err, err)
+	}
+
+	// every Node in the cycle is named, and the first is repeated at the end
+	names := make(map[string]bool)
+	for _, n := range cycle.Stack {
+		names[n.Name()] = true
+	}
+
+	if len(cycle.Stack) != 4 || cycle.Stack[0] != cycle.Stack[3] {
+		t.Errorf("Cycle was reported as %v", err)
+	} else if !names["loop"] || !names["hidden"] || !names["out"] {
+		t.Errorf("Reported cycle doesn't include every Node: %v", err)
+	}
+
+	if err := finalize(build(true)); err != nil {
+		t.Errorf("Cycle with delay gave error: %v", err)
+	}
+}
+
+func TestMultipleOutputs(t *testing.T) {
+	net := new(bs.Network)
+	in := net.AddInput([]int{2})
+	a := net.Add(operators.Neurons(1), in).Opt(optimizers.SGD())
+	b := net.Add(operators.Neurons(2), in).Opt(optimizers.SGD())
+
+	// with a learning rate of one, the change to each weight is exactly its gradient
+	net.AddHP("learning-rate", hyperparams.Constant(1))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), a, b); err != nil {
+		t.Fatal(err)
+	}
+
+	// the weights of 'a', then of each neuron in 'b'; the bias of each neuron is last
+	weights := []float64{1, 0, 0, 0, 1, 0, 1, 1, 0}
+	inputs := []float64{1, 2}
+
+	tests := []struct {
+		targets []float64
+		cost    float64
+		changes []float64
+	}{
+		// only 'a' is off, by one
+		{[]float64{0, 2, 3}, 0.5, []float64{-1, -2, -1, 0, 0, 0, 0, 0, 0}},
+		// only the second value of 'b' is off, by two
+		{[]float64{1, 2, 1}, 2, []float64{0, 0, 0, 0, 0, 0, -2, -4, -2}},
+		{[]float64{0, 2, 1}, 2.5, []float64{-1, -2, -1, 0, 0, 0, -2, -4, -2}},
+	}
+
+	for i, test := range tests {
+		if err := net.SetWeights(weights); err != nil {
+			t.Fatal(err)
+		}
+
+		// the outputs are in the order given to Finalize
+		if outs, err := net.GetOutputs(inputs); err != nil {
+			t.Fatal(err)
+		} else if expected := []float64{1, 2, 3}; !sliceEqual(outs, expected, 0) {
+			t.Fatalf("Outputs are %v, expected %v", outs, expected)
+		}
+
+		data := bs.SliceSource([][][]float64{{inputs, test.targets}})
+		if cost, _, err := net.Evaluate(data, nil, nil); err != nil {
+			t.Fatal(err)
+		} else if cost != test.cost {
+			t.Errorf("Test %d: cost is %v, expected %v", i, cost, test.cost)
+		}
+
+		if err := net.Train(bs.TrainArgs{Data: data, RunCondition: bs.TrainUntil(1)}); err != nil {
+			t.Fatal(err)
+		}
+
+		for w, after := range net.Weights() {
+			if after-weights[w] != test.changes[w] {
+				t.Errorf("Test %d: weight %d changed by %v, expected %v", i, w, after-weights[w], test.changes[w])
+			}
+		}
+	}
+}
+
+func TestSetTrainable(t *testing.T) {
+	net := new(bs.Network)
+	first := net.Add(operators.Neurons(3), net.AddInput([]int{2})).Opt(optimizers.SGD())
+	frozen := net.Add(operators.Neurons(3), net.Add(operators.Logistic(), first)).Opt(optimizers.SGD())
+	last := net.Add(operators.Neurons(1), net.Add(operators.Logistic(), frozen)).Opt(optimizers.SGD())
+	frozen.SetTrainable(false)
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.5))
+	if err := net.Finalize(costfuncs.MSE(), last); err != nil {
+		t.Fatal(err)
+	}
+	randomWeights(net, 1)
+
+	// the weights of each Node, as a slice of all of the weights in the Network
+	weights := func(n *bs.Node) []float64 {
+		var start int
+		for _, m := range net.Nodes() {
+			if m == n {
+				break
+			}
+			start += m.NumWeights()
+		}
+
+		return net.Weights()[start : start+n.NumWeights()]
+	}
+
+	before := map[*bs.Node][]float64{first: weights(first), frozen: weights(frozen), last: weights(last)}
+
+	if err := net.Train(xorArgs(t, 10*len(xorData))); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sliceEqual(weights(frozen), before[frozen], 0) {
+		t.Errorf("Weights of the frozen Node changed")
+	}
+
+	// deltas are still passed through the frozen Node, so the Node before it is trained
+	if sliceEqual(weights(first), before[first], 0) {
+		t.Errorf("Weights of the Node before the frozen one didn't change")
+	} else if sliceEqual(weights(last), before[last], 0) {
+		t.Errorf("Weights of the Node after the frozen one didn't change")
+	}
+}
+
+func TestSetLearningRateScale(t *testing.T) {
+	net := new(bs.Network)
+	in := net.AddInput([]int{2})
+	a := net.Add(operators.Neurons(1), in).Opt(optimizers.SGD())
+	b := net.Add(operators.Neurons(1), in).Opt(optimizers.SGD()).SetLearningRateScale(0.25)
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), a, b); err != nil {
+		t.Fatal(err)
+	}
+
+	// both Nodes start with the same weights and have the same targets, so they have the same
+	// gradients
+	weights := []float64{0.3, -0.5, 0.1, 0.3, -0.5, 0.1}
+	if err := net.SetWeights(weights); err != nil {
+		t.Fatal(err)
+	}
+
+	data := bs.SliceSource([][][]float64{{{1, 2}, {1, 1}}})
+	if err := net.Train(bs.TrainArgs{Data: data, RunCondition: bs.TrainUntil(1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := net.Weights()
+	for i := 0; i < 3; i++ {
+		changeA, changeB := ws[i]-weights[i], ws[3+i]-weights[3+i]
+		if changeA == 0 || !approxEqual(changeB, 0.25*changeA, 1e-12) {
+			t.Errorf("Weight %d changed by %v with a scale of 1, and %v with a scale of 0.25", i, changeA, changeB)
+		}
+	}
+}
+
+func TestAddInvalidSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(net *bs.Network) *bs.Node
+		msg  string
+	}{
+		{"no inputs", func(net *bs.Network) *bs.Node {
+			return net.Add(operators.Neurons(2))
+		}, "Node 'inputs' is nil"},
+		{"zero-size input", func(net *bs.Network) *bs.Node {
+			return net.Add(operators.Neurons(2), net.AddInput([]int{0}))
+		}, bs.ErrZeroSizeInput.Error()},
+		{"zero-size Node", func(net *bs.Network) *bs.Node {
+			return net.Add(operators.Neurons(0), net.AddInput([]int{2}))
+		}, bs.ErrZeroSize.Error()},
+		{"zero-size placeholder", func(net *bs.Network) *bs.Node {
+			return net.Placeholder([]int{2, 0})
+		}, bs.ErrZeroSize.Error()},
+	}
+
+	for _, test := range tests {
+		net := new(bs.Network)
+		if n := test.add(net); n != nil {
+			t.Errorf("Adding with %s succeeded, expected error %q", test.name, test.msg)
+		} else if net.Error() == nil || net.Error().Error() != test.msg {
+			t.Errorf("Adding with %s gave error %v, expected %q", test.name, net.Error(), test.msg)
+		}
+	}
+}
+
+func TestFinalizeOutputsOnce(t *testing.T) {
+	net := new(bs.Network)
+	in := net.AddInput([]int{2})
+	a := net.Add(operators.Neurons(3), in).Opt(optimizers.SGD())
+	b := net.Add(operators.Neurons(1), a).Opt(optimizers.SGD())
+
+	// without a learning rate, this fails -- and makes no changes
+	if err := net.Finalize(costfuncs.MSE(), a); err == nil {
+		t.Fatal("Finalize without a learning rate succeeded")
+	}
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := net.Finalize(costfuncs.MSE(), b); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(when string) {
+		if a.IsOutput() || !b.IsOutput() || net.OutputSize() != 1 {
+			t.Errorf("%s: outputs are a: %v, b: %v with size %d; expected only b, with size 1", when, a.IsOutput(), b.IsOutput(), net.OutputSize())
+		}
+	}
+
+	check("After finalizing")
+
+	if err := net.Finalize(costfuncs.MSE(), a); err != bs.ErrNetFinalized {
+		t.Errorf("Finalizing again gave error %v, expected ErrNetFinalized", err)
+	}
+
+	check("After finalizing again")
+}
+
+func TestSetOperator(t *testing.T) {
+	net := new(bs.Network)
+	in := net.AddInput([]int{2})
+	hiddenNeurons := net.Add(operators.Neurons(3), in).Opt(optimizers.SGD())
+	hidden := net.Add(operators.Logistic(), hiddenNeurons)
+	l := net.Add(operators.Neurons(1), hidden).Opt(optimizers.SGD())
+	l = net.Add(operators.Logistic(), l)
+
+	if err := in.SetOperator(operators.Tanh()); err != bs.ErrNoOperator {
+		t.Errorf("Setting the Operator of an input gave error %v, expected ErrNoOperator", err)
+	}
+
+	if err := hiddenNeurons.SetOperator(operators.Neurons(4)); err == nil {
+		t.Errorf("Setting an Operator with a different size succeeded")
+	} else if _, ok := err.(bs.UnequalShapeError); !ok {
+		t.Errorf("Setting an Operator with a different size gave error %v, expected type UnequalShapeError", err)
+	}
+
+	if err := hidden.SetOperator(operators.Tanh()); err != nil {
+		t.Fatal(err)
+	} else if typ := hidden.OperatorType(); typ != "tanh" {
+		t.Errorf("Operator type after SetOperator is %q, expected \"tanh\"", typ)
+	}
+
+	net.AddHP("learning-rate", hyperparams.Constant(0.5))
+	if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	randomWeights(net, 1)
+	if err := net.Train(xorArgs(t, 2000*len(xorData))); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range xorData {
+		if outs, err := net.GetOutputs(d[0]); err != nil {
+			t.Fatal(err)
+		} else if !bs.CorrectRound(outs, d[1]) {
+			t.Errorf("Output for %v is %v after training, expected %v", d[0], outs, d[1])
+		}
+	}
+
+	if err := hidden.SetOperator(operators.Logistic()); err != bs.ErrNetFinalized {
+		t.Errorf("Setting an Operator after finalizing gave error %v, expected ErrNetFinalized", err)
+	}
+}
@@ -2,6 +2,7 @@ package badstudent
 
 import (
 	"github.com/sharnoff/tensors"
+	"sync"
 )
 
 // Network is the main structure that is used to learn to map input to output functions. A Network
@@ -12,13 +13,28 @@ type Network struct {
 	// a list of all of the Nodes, stored such that their id is their index in this slice
 	nodesByID []*Node
 
+	// a cached list of all of the Nodes in topological order, given by SortedNodes. It is nil
+	// until it is first requested.
+	sortedNodes []*Node
+
+	// a cached list of the Nodes that the outputs depend on, in topological order, so that
+	// Networks without delay can be evaluated without recursion. It is nil until first needed.
+	evalOrder []*Node
+
 	// whether or not the network should panic when it encounters an error
 	panicErrors bool
 
+	// the maximum number of goroutines used for each calculation, as set by SetWorkers. If zero,
+	// runtime.NumCPU() is used instead.
+	workers int
+
 	err error
 
 	cf CostFunction
 
+	// how the costs of each output are combined, as set by SetReduction
+	reduction Reduction
+
 	defaultInit Initializer
 	defaultOpt  func() Optimizer
 	hyperParams map[string]HyperParameter
@@ -38,10 +54,45 @@ type Network struct {
 	// Whether or not there are changes to weights that have not been applied yet
 	hasSavedChanges bool
 
+	// Whether or not the Nodes are currently using the moving average of their weights, as set by
+	// UseEMAWeights
+	usingEMA bool
+
+	// Whether or not the Network is currently being trained, as opposed to being tested or used
+	// otherwise. Operators can access this through (*Node).IsTraining()
+	training bool
+
+	// the factor that the deltas of the outputs are multiplied by during training, as given by
+	// TrainArgs.LossScale. Zero is treated as one.
+	lossScale float64
+
+	// the number of epochs that have finished during the current call to Train
+	epoch int
+
+	// the generator used to shuffle the training data in the current (or most recent) call to
+	// Train, along with the data it shuffles, so that they can be saved by SaveTrainingState. Both
+	// are nil if the data was not shuffled.
+	shuffleSrc *splitMix
+	shuffled   Shuffleable
+
+	// the order of the training data restored by LoadTrainingState, to be given to it once
+	// training is resumed
+	resumeOrder []int
+
+	// the L2 norms of the changes to each Node's weights in each epoch, as given by UpdateNorms.
+	// normStart stores the weights of each Node (by id) at the start of the current epoch, and is
+	// nil if norms are not being recorded.
+	updateNorms map[string][]float64
+	normStart   [][]float64
+
 	// Whether or not there are any Nodes in the Network with delay. If there are, a different
 	// protocol must be followed
 	hasDelay bool
 
+	// held by Infer while it reads evalOrder, which may need to be calculated first, so that
+	// concurrent calls do not interfere with each other
+	syncMux sync.Mutex
+
 	stat status
 }
 
@@ -101,9 +152,36 @@ type Node struct {
 	opt Optimizer
 	pen Penalty
 
+	// whether or not the weights have been set by Init, in which case they won't be initialized
+	// by default
+	initialized bool
+
+	// the Initializer for the biases of the Node, as set by BiasInit. If nil, biases are
+	// initialized to zero.
+	biasInit Initializer
+
+	// whether or not the Node has been excluded from training by SetTrainable
+	frozen bool
+
+	// the Node that this Node shares its weights with, as set by TieWeights. If nil, the weights
+	// are not shared, or this Node is the one that holds them.
+	tiedTo *Node
+
+	// the Nodes that share the weights of this Node, as set by TieWeights
+	tied []*Node
+
+	// the multiplier for the "learning-rate" HyperParameter of this Node, as set by
+	// SetLearningRateScale. Defaults to 1.
+	lrScale float64
+
 	// changes to the weights that have been delayed until the end of the batch
 	delayedWeights []float64
 
+	// the exponential moving average of the weights, if it is being tracked (see
+	// TrainArgs.EMADecay). While the Network is using the averaged weights, this instead holds the
+	// raw weights.
+	emaWeights []float64
+
 	// these are exclusively for the Optimizer
 	hyperParams map[string]HyperParameter
 
@@ -130,6 +208,9 @@ type Node struct {
 	delayDeltas  chan []float64
 	storedValues [][]float64
 
+	// whether or not the Node is a copy that is being evaluated by Network.Infer
+	inferring bool
+
 	// Whether or not the current task assigned by the Network has been completed. This value takes
 	// on different meanings depending on what the Network is doing. It is included as a sort of
 	// permenant auxiliary information storage.
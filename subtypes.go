@@ -106,9 +106,73 @@ type Adjustable interface {
 	// Weights should return the actual stored weights so that they can be
 	// efficiently set and updated. It is recommended that Weights be simple to
 	// allow for compile-time in-lining and prevent overhead.
+	//
+	// Because Initializers and Optimizers write directly to the returned slice,
+	// Operators that store their weights at a lower precision (e.g. float32)
+	// must keep the slice they return and copy any changes back before the
+	// weights are next used. They should also implement TemporaryWeights, so
+	// that the slice can be discarded once the Network is done with it. See
+	// operators.Neurons32 for an example.
 	Weights() []float64
 }
 
+// TemporaryWeights is an optional interface for Adjustable Operators that store their weights in a
+// different form, so that the slice given by Weights is a temporary copy. Once the Network has
+// finished using the weights -- e.g. at the end of each training iteration, or once they have been
+// set -- ReleaseWeights is called, at which point the Operator should store any changes to the
+// copy and discard it. The next call to Weights may then give a new slice.
+type TemporaryWeights interface {
+	Adjustable
+
+	ReleaseWeights()
+}
+
+// Biased is an optional interface for Adjustable Operators that have biases among their weights.
+// Penalties are not applied to biases.
+type Biased interface {
+	Adjustable
+
+	// IsBias returns whether or not the weight at the given index is a bias.
+	IsBias(n *Node, index int) bool
+}
+
+// Stateful is an optional interface for Operators that keep internal state between evaluations
+// (e.g. the hidden state of a recurrent layer), apart from their weights. ResetState is called on
+// each of them by (*Network).ResetState, which is done between sequences during training and
+// testing.
+type Stateful interface {
+	Operator
+
+	// ResetState clears any internal state of the Operator, so that the next evaluation is as if
+	// it were the first. It must not change any weights.
+	ResetState(n *Node)
+}
+
+// FixedInput is an optional interface for Operators that require a particular total number of
+// input values. If it is implemented, the total size of a Node's inputs will be checked against it
+// as the Node is added, so that mismatches are caught before they can cause issues during
+// evaluation or backpropagation.
+type FixedInput interface {
+	Operator
+
+	// InputSize returns the total number of input values that the Operator requires. A negative
+	// value indicates that there is no particular requirement.
+	//
+	// InputSize is called after OutputShape, if the Operator is a Layer.
+	InputSize() int
+}
+
+// Saturating is an optional interface for activation Operators whose values can become saturated
+// -- where the derivative is zero or nearly so, and little is learned through them. It is used by
+// (*Network).SaturationReport.
+type Saturating interface {
+	Operator
+
+	// Saturated returns whether the value of the Node at the given index is saturated. It is only
+	// called after the Node has been evaluated.
+	Saturated(n *Node, index int) bool
+}
+
 func isValid(o Operator) bool {
 	if _, ok := o.(Layer); ok {
 		return true
@@ -181,6 +245,47 @@ type CostFunction interface {
 	Derivs(outs, targets []float64) []float64
 }
 
+// LabelCostFunction is an optional interface for CostFunctions used for classification, where the
+// targets can instead be given by the index of the correct class (see Datum.Label). This allows the
+// cost and derivatives to be calculated without constructing a one-hot vector of targets.
+//
+// The results must be the same as those given by Cost and Derivs with a one-hot vector of targets
+// where only the value at 'label' is 1. CostFunctions that do not implement LabelCostFunction can
+// still be used with labels; the one-hot vector will be constructed for them.
+type LabelCostFunction interface {
+	CostFunction
+
+	// LabelCost returns the cost of the outputs, given the index of the correct output. 'label'
+	// will be guaranteed to be in range.
+	LabelCost(outs []float64, label int) float64
+
+	// LabelDerivs returns the derivatives of each output w.r.t. the total cost, given the index of
+	// the correct output.
+	LabelDerivs(outs []float64, label int) []float64
+}
+
+// SizedCostFunction is an optional interface for CostFunctions that are only compatible with
+// certain numbers of outputs. If it is implemented, the size of the Network's outputs is checked
+// at the start of training, so that mismatches are caught before the first sample.
+type SizedCostFunction interface {
+	CostFunction
+
+	// CheckSize returns an error describing the issue if the CostFunction cannot be used with the
+	// given number of outputs, or nil if it can.
+	CheckSize(outputs int) error
+}
+
+// SeparableCostFunction is an optional interface for CostFunctions that can give the cost of each
+// output on its own. It is used by the Reductions ReduceSum and ReduceMean, which would otherwise
+// call Cost separately with each output. CostFunctions that combine others, which need all of the
+// outputs at once to know which applies where, should implement it.
+type SeparableCostFunction interface {
+	CostFunction
+
+	// OutputCosts returns the cost of each output, such that their sum is the cost of all of them.
+	OutputCosts(outs, targets []float64) []float64
+}
+
 // HyperParameter is the method for providing user-defined values to Optimizers.
 // Like Operators, they must be registered before they can be loaded.
 //
@@ -199,6 +304,17 @@ type HyperParameter interface {
 	Value(iter int) float64
 }
 
+// EpochHyperParameter is an optional interface for HyperParameters that are scheduled by epoch
+// instead of by iteration. If it is implemented, EpochValue is used in place of Value, given the
+// number of epochs that have finished during the current call to Train (see Node.Epoch).
+type EpochHyperParameter interface {
+	HyperParameter
+
+	// EpochValue returns the desired value of the HyperParameter at a given epoch. Like Value, it
+	// should give the same result for repeated calls with the same epoch.
+	EpochValue(epoch int) float64
+}
+
 // Initializer sets the initial weights in an Adjustable Operator.
 type Initializer interface {
 	Set(n *Node, weights []float64)
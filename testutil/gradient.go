@@ -0,0 +1,172 @@
+// Package testutil provides helpers for testing code built on badstudent -- mainly new Operators.
+package testutil
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+const (
+	// the step used for the central differences
+	gradEpsilon float64 = 1e-6
+
+	// the relative tolerance between the analytic and numerical gradients
+	gradTolerance float64 = 1e-4
+)
+
+// AssertOperatorGradient checks the derivatives given by an Operator against numerical estimates
+// from finite differences. It builds a Network with a single input Node of size 'inputSize',
+// followed by a Node with the Operator, and compares the derivatives of a squared-error cost
+// w.r.t. each input (from InputDeltas or Deriv) and each weight (from Grad), reporting any that
+// do not match with t.Errorf. The weights are checked only if the Operator is Adjustable and has
+// any.
+//
+// For example, to check Logistic:
+//
+//	func TestLogisticGradient(t *testing.T) {
+//		testutil.AssertOperatorGradient(t, operators.Logistic(), 5)
+//	}
+//
+// The inputs, targets, and initial weights are random, but seeded, so results are repeatable.
+// Operators with kinks (e.g. ReLU) may occasionally disagree with the estimates if an input falls
+// very close to one.
+func AssertOperatorGradient(t testing.TB, op bs.Operator, inputSize int) {
+	t.Helper()
+	AssertOperatorGradientInputs(t, op, inputSize)
+}
+
+// AssertOperatorGradientInputs is the same as AssertOperatorGradient, but for Operators that take
+// more than one input Node. The Operator is given one input Node for each size in 'inputSizes',
+// in order. For example, to check WeightedAdd:
+//
+//	testutil.AssertOperatorGradientInputs(t, operators.WeightedAdd(true), 4, 4)
+func AssertOperatorGradientInputs(t testing.TB, op bs.Operator, inputSizes ...int) {
+	t.Helper()
+
+	rng := rand.New(rand.NewSource(1))
+
+	net := new(bs.Network).HandleErrors()
+	inNodes := make([]*bs.Node, len(inputSizes))
+	var inputSize int
+	for i, size := range inputSizes {
+		inNodes[i] = net.AddInput([]int{size})
+		inputSize += size
+	}
+
+	n := net.Add(op, inNodes...)
+	if net.Error() != nil {
+		t.Fatalf("Failed to add Operator %q: %v", op.TypeString(), net.Error())
+		return
+	}
+
+	n.Opt(optimizers.SGD()).AddHP("learning-rate", hyperparams.Constant(1))
+
+	// with ReduceSum, the cost is exactly the sum of 0.5*(out - target)^2, matching sqError
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), n); err != nil {
+		t.Fatalf("Failed to finalize Network with Operator %q: %v", op.TypeString(), err)
+		return
+	}
+
+	inputs := randSlice(rng, inputSize)
+	targets := randSlice(rng, net.OutputSize())
+
+	if ws := net.Weights(); len(ws) != 0 {
+		for i := range ws {
+			ws[i] = rng.Float64()*2 - 1
+		}
+
+		net.SetWeights(ws)
+	}
+
+	cost := func(ins []float64) float64 {
+		outs, err := net.GetOutputs(ins)
+		if err != nil {
+			t.Fatalf("Failed to get outputs: %v", err)
+		}
+
+		return sqError(outs, targets)
+	}
+
+	// inputs
+	inGrads, err := net.InputGradients(inputs, targets, nil)
+	if err != nil {
+		t.Fatalf("Failed to get input gradients: %v", err)
+		return
+	}
+
+	for i := range inputs {
+		ins := append([]float64(nil), inputs...)
+
+		ins[i] = inputs[i] + gradEpsilon
+		plus := cost(ins)
+		ins[i] = inputs[i] - gradEpsilon
+		minus := cost(ins)
+
+		if numeric := (plus - minus) / (2 * gradEpsilon); !approxEqual(inGrads[i], numeric) {
+			t.Errorf("Operator %q: gradient of input %d is %v, estimated %v", op.TypeString(), i, inGrads[i], numeric)
+		}
+	}
+
+	// weights. Weightless Operators have nothing more to check.
+	weights := net.Weights()
+	if len(weights) == 0 {
+		return
+	}
+
+	wGrads, err := net.WeightGradients(inputs, targets, nil)
+	if err != nil {
+		t.Fatalf("Failed to get weight gradients: %v", err)
+		return
+	}
+
+	ws := append([]float64(nil), weights...)
+	for i := range weights {
+		ws[i] = weights[i] + gradEpsilon
+		net.SetWeights(ws)
+		plus := cost(inputs)
+
+		ws[i] = weights[i] - gradEpsilon
+		net.SetWeights(ws)
+		minus := cost(inputs)
+
+		ws[i] = weights[i]
+
+		if numeric := (plus - minus) / (2 * gradEpsilon); !approxEqual(wGrads[i], numeric) {
+			t.Errorf("Operator %q: gradient of weight %d is %v, estimated %v", op.TypeString(), i, wGrads[i], numeric)
+		}
+	}
+
+	net.SetWeights(weights)
+}
+
+func randSlice(rng *rand.Rand, size int) []float64 {
+	s := make([]float64, size)
+	for i := range s {
+		s[i] = rng.Float64()*2 - 1
+	}
+
+	return s
+}
+
+func sqError(outs, targets []float64) float64 {
+	var sum float64
+	for i := range outs {
+		sum += 0.5 * (outs[i] - targets[i]) * (outs[i] - targets[i])
+	}
+
+	return sum
+}
+
+// approxEqual returns whether or not the analytic and numerical gradients are within the tolerance,
+// relative to their magnitude (or absolute, if they're small)
+func approxEqual(analytic, numeric float64) bool {
+	scale := math.Max(1, math.Abs(analytic)+math.Abs(numeric))
+	return math.Abs(analytic-numeric) <= gradTolerance*scale
+}
@@ -0,0 +1,52 @@
+package testutil_test
+
+import (
+	"fmt"
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/testutil"
+)
+
+func TestAssertOperatorGradientSigmoid(t *testing.T) {
+	testutil.AssertOperatorGradient(t, operators.Logistic(), 5)
+}
+
+// recorder is a testing.TB that records failures instead of reporting them
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+// square is an elementwise Operator giving x^2, but with the derivative of x^2 / 2 -- off by a
+// factor of two
+type square int8
+
+func (square) TypeString() string              { return "square" }
+func (square) Finalize(n *bs.Node) error       { return nil }
+func (square) Value(in float64, i int) float64 { return in * in }
+
+func (square) Deriv(n *bs.Node, i int) float64 {
+	return n.InputValue(i)
+}
+
+func TestAssertOperatorGradientWrongDerivative(t *testing.T) {
+	const size = 5
+
+	r := &recorder{TB: t}
+	testutil.AssertOperatorGradient(r, square(0), size)
+
+	// every input should be reported, as none of them are zero
+	if len(r.errors) != size {
+		t.Errorf("Reported %d errors for an incorrect derivative, expected %d: %q", len(r.errors), size, r.errors)
+	}
+}
@@ -0,0 +1,240 @@
+package badstudent
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TrainAllError wraps an error from training one of the Networks given to TrainAll
+type TrainAllError struct {
+	// the index of the Network in the slice given to TrainAll
+	Index int
+
+	Err error
+}
+
+func (err TrainAllError) Error() string {
+	return fmt.Sprintf("Failed to train Network %d: %v", err.Index, err.Err)
+}
+
+// TrainAll trains each of the Networks on the same data at once, while only reading each sample
+// from 'data' a single time. This is useful for comparing Networks (e.g. with different
+// hyperparameters) on data that is expensive to read, like those from files.
+//
+// Each Network is trained by Train, in a separate goroutine, with the TrainArgs at the same index
+// in 'args'. The Data and TrainData fields of each are ignored; the samples from 'data' are used
+// instead, in the same way as TrainArgs.Data (i.e. with a batch size of 1). Samples are kept only
+// until every Network has used them, but if some Networks are much slower than others, many may
+// need to be kept at once.
+//
+// Because the Networks are trained concurrently, the other fields of the TrainArgs (e.g. TestData
+// or Validation) must not be shared between them, and any callbacks must be safe to call from
+// multiple goroutines.
+//
+// TrainAll has several error conditions:
+//	(0) If len(nets) == 0 or 'data' is nil, type NilArgError;
+//	(1) If len(args) != len(nets), type SizeMismatchError;
+//	(2) If any Network has not been finalized, ErrNetNotFinalized;
+//	(3) If the Networks' input or output sizes differ, type SizeMismatchError;
+//	(4) If training any of the Networks fails, type TrainAllError;
+// In the case of (4), the other Networks will still be trained in full, and the error is from the
+// first Network (by index) that failed.
+func TrainAll(nets []*Network, data DataSource, args []TrainArgs) error {
+	if len(nets) == 0 {
+		return NilArgError{"nets"}
+	} else if data == nil {
+		return NilArgError{"DataSource"}
+	} else if len(args) != len(nets) {
+		return SizeMismatchError{len(nets), len(args), "TrainArgs"}
+	}
+
+	for i, net := range nets {
+		if net.stat < finalized {
+			return ErrNetNotFinalized
+		} else if net.InputSize() != nets[0].InputSize() {
+			return SizeMismatchError{nets[0].InputSize(), net.InputSize(), fmt.Sprintf("inputs of Network %d", i)}
+		} else if net.OutputSize() != nets[0].OutputSize() {
+			return SizeMismatchError{nets[0].OutputSize(), net.OutputSize(), fmt.Sprintf("outputs of Network %d", i)}
+		}
+	}
+
+	shared := &sharedSource{src: data, readers: len(nets)}
+	data.Reset()
+
+	errs := make([]error, len(nets))
+
+	var wg sync.WaitGroup
+	wg.Add(len(nets))
+
+	for i := range nets {
+		r, src := shared.reader()
+
+		go func(i int) {
+			defer wg.Done()
+			defer r.leave()
+
+			a := args[i]
+			a.Data, a.TrainData = src, nil
+			errs[i] = nets[i].Train(a)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return TrainAllError{i, err}
+		}
+	}
+
+	return nil
+}
+
+// sharedSource allows a single DataSource to be read by multiple sharedReaders, as is used by
+// TrainAll. Each sample (including the ends of each pass through the source) is read once and
+// kept until every reader has used it.
+//
+// Because multiple readers will reach the end of each pass at different times, the end is treated
+// as a sample in its own right, and the source is Reset as soon as it is reached. Calls to Reset
+// on the readers do nothing.
+type sharedSource struct {
+	mux sync.Mutex
+	src DataSource
+
+	// the number of readers that are still in use
+	readers int
+
+	// the samples that have been read but not yet used by all readers. 'start' is the position of
+	// buffer[0] in the sequence of all samples.
+	buffer []sharedSample
+	start  int
+}
+
+type sharedSample struct {
+	inputs, outputs []float64
+	label           int
+
+	// false if this marks the end of a pass through the source (or an error)
+	ok  bool
+	err error
+
+	// the number of readers that have yet to use this sample
+	remaining int
+}
+
+// sharedReader is a single reader of a sharedSource. It implements CheckedSource, and if the
+// source is a LabeledSource, it will be wrapped by labeledReader to implement that as well. (In
+// that case, FromSource will only use NextLabel.)
+type sharedReader struct {
+	s *sharedSource
+
+	// the position of the next sample in the sequence of all samples
+	pos int
+	err error
+}
+
+type labeledReader struct {
+	*sharedReader
+}
+
+// reader returns a new reader for the source, along with the DataSource that should be used for it
+func (s *sharedSource) reader() (*sharedReader, DataSource) {
+	r := &sharedReader{s: s}
+	if _, ok := s.src.(LabeledSource); ok {
+		return r, labeledReader{r}
+	}
+
+	return r, r
+}
+
+// next returns the next sample for the reader, reading it from the source if no other reader has
+// yet done so
+func (r *sharedReader) next() sharedSample {
+	s := r.s
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	index := r.pos - s.start
+	if index == len(s.buffer) {
+		s.buffer = append(s.buffer, s.read())
+	}
+
+	sample := s.buffer[index]
+	s.buffer[index].remaining--
+	r.pos++
+
+	s.prune()
+	return sample
+}
+
+// read reads the next sample from the source. It assumes that the lock is held.
+func (s *sharedSource) read() sharedSample {
+	sample := sharedSample{remaining: s.readers}
+
+	if l, ok := s.src.(LabeledSource); ok {
+		sample.inputs, sample.label, sample.ok = l.NextLabel()
+	} else {
+		sample.inputs, sample.outputs, sample.ok = s.src.Next()
+	}
+
+	if !sample.ok {
+		if c, ok := s.src.(CheckedSource); ok {
+			sample.err = c.Err()
+		}
+
+		s.src.Reset()
+	}
+
+	return sample
+}
+
+// prune removes the samples that have been used by every reader. It assumes that the lock is held.
+func (s *sharedSource) prune() {
+	var i int
+	for i < len(s.buffer) && s.buffer[i].remaining <= 0 {
+		i++
+	}
+
+	if i == 0 {
+		return
+	}
+
+	// copy so that the old samples can be garbage collected
+	s.buffer = append([]sharedSample(nil), s.buffer[i:]...)
+	s.start += i
+}
+
+// leave marks the reader as no longer in use, so that the samples it has not yet used will not be
+// kept for it
+func (r *sharedReader) leave() {
+	s := r.s
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for i := r.pos - s.start; i < len(s.buffer); i++ {
+		s.buffer[i].remaining--
+	}
+
+	s.readers--
+	s.prune()
+}
+
+func (r *sharedReader) Next() ([]float64, []float64, bool) {
+	sample := r.next()
+	r.err = sample.err
+
+	return sample.inputs, sample.outputs, sample.ok
+}
+
+func (r labeledReader) NextLabel() ([]float64, int, bool) {
+	sample := r.next()
+	r.err = sample.err
+
+	return sample.inputs, sample.label, sample.ok
+}
+
+func (r *sharedReader) Reset() {}
+
+func (r *sharedReader) Err() error {
+	return r.err
+}
@@ -0,0 +1,76 @@
+package badstudent_test
+
+import (
+	"testing"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestTrainAll(t *testing.T) {
+	const iterations = 3 * 4 // three passes through xorData
+
+	reads := func(src *countingSource) int {
+		var n int
+		for _, pass := range src.passes {
+			n += len(pass)
+		}
+
+		return n
+	}
+
+	// train each Network separately, for comparison
+	var expected [][]float64
+	var expectedReads int
+	for _, seed := range []int64{1, 2} {
+		net := xorNet(t, seed)
+		src := &countingSource{DataSource: bs.SliceSource(xorData)}
+		if err := net.Train(bs.TrainArgs{Data: src, RunCondition: bs.TrainUntil(iterations)}); err != nil {
+			t.Fatal(err)
+		}
+
+		expected = append(expected, net.Weights())
+		expectedReads = reads(src)
+	}
+
+	nets := []*bs.Network{xorNet(t, 1), xorNet(t, 2)}
+	args := []bs.TrainArgs{{RunCondition: bs.TrainUntil(iterations)}, {RunCondition: bs.TrainUntil(iterations)}}
+
+	src := &countingSource{DataSource: bs.SliceSource(xorData)}
+	if err := bs.TrainAll(nets, src, args); err != nil {
+		t.Fatal(err)
+	}
+
+	// the data is read as many times as it would be for a single Network
+	if r := reads(src); r != expectedReads {
+		t.Errorf("TrainAll read %d samples, expected %d", r, expectedReads)
+	}
+
+	for i, net := range nets {
+		if ws := net.Weights(); !sliceEqual(ws, expected[i], 0) {
+			t.Errorf("Weights of Network %d are %v, expected %v from training alone", i, ws, expected[i])
+		}
+	}
+}
+
+func TestTrainAllSizes(t *testing.T) {
+	other := new(bs.Network)
+	out := other.Add(operators.Neurons(1), other.AddInput([]int{3})).Opt(optimizers.SGD())
+	other.AddHP("learning-rate", hyperparams.Constant(0.1))
+	if err := other.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	nets := []*bs.Network{xorNet(t, 1), other}
+	args := make([]bs.TrainArgs, 2)
+
+	err := bs.TrainAll(nets, bs.SliceSource(xorData), args)
+	if e, ok := err.(bs.SizeMismatchError); !ok {
+		t.Errorf("Differing input sizes gave error %v, expected type SizeMismatchError", err)
+	} else if e.Expected != 2 || e.Given != 3 {
+		t.Errorf("Error was for %d inputs instead of %d, expected 3 instead of 2", e.Given, e.Expected)
+	}
+}
@@ -1,7 +1,12 @@
 package badstudent
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"time"
 )
 
 // Datum is a simple type used to send training samples to the Network
@@ -15,12 +20,79 @@ type Datum struct {
 	// For recurrent networks, providing nil (or length 0) can be used to signify that the outputs
 	// are not significant, and that the hidden state will be updated to reflect the inputs
 	Outputs []float64
+
+	// Label is the index of the correct output, for classification problems where Outputs would
+	// otherwise be a one-hot vector. It is only used if HasLabel is true, in which case Outputs
+	// must be nil. If the Network's CostFunction is a LabelCostFunction, the one-hot vector will
+	// never be constructed.
+	Label    int
+	HasLabel bool
+
+	// Weight is the importance of the sample, relative to others. Both the sample's contribution
+	// to the reported cost and its gradients are multiplied by it, which can be used to balance
+	// datasets where certain classes are rare. A Weight of zero (i.e. if it is not set) is treated
+	// as 1.
+	Weight float64
+}
+
+// weight returns the Weight of the Datum, substituting 1 if it has not been set
+func (d Datum) weight() float64 {
+	if d.Weight == 0 {
+		return 1
+	}
+
+	return d.Weight
+}
+
+// hasTargets returns whether or not the Datum gives targets for the outputs of the Network, either
+// through Outputs or Label
+func (d Datum) hasTargets() bool {
+	return len(d.Outputs) != 0 || d.HasLabel
+}
+
+// targets returns the target outputs of the Datum. If they are given by Label, a one-hot vector
+// with 'size' values is constructed.
+func (d Datum) targets(size int) []float64 {
+	if !d.HasLabel {
+		return d.Outputs
+	}
+
+	ts := make([]float64, size)
+	ts[d.Label] = 1
+	return ts
+}
+
+// cost returns the cost of the outputs for the targets given by the Datum, using the labeled
+// variant of 'cf' if it is available
+func (d Datum) cost(cf CostFunction, outs []float64) float64 {
+	if lcf, ok := cf.(LabelCostFunction); ok && d.HasLabel {
+		return lcf.LabelCost(outs, d.Label)
+	}
+
+	return cf.Cost(outs, d.targets(len(outs)))
+}
+
+// derivs returns the derivatives of the outputs for the targets given by the Datum, in the same
+// way as cost
+func (d Datum) derivs(cf CostFunction, outs []float64) []float64 {
+	if lcf, ok := cf.(LabelCostFunction); ok && d.HasLabel {
+		return lcf.LabelDerivs(outs, d.Label)
+	}
+
+	return cf.Derivs(outs, d.targets(len(outs)))
 }
 
 // Fits indicates whether or not a given Datum's dimensions match those of the Network, allowing it
-// to be used for training or testing.
+// to be used for training or testing. If the Datum has a Label, it must be a valid index of the
+// outputs, and Outputs must not be given.
 func (d Datum) Fits(net *Network) bool {
-	return len(d.Inputs) == net.InputSize() && ((len(d.Outputs) == 0 && net.hasDelay) || len(d.Outputs) == net.OutputSize())
+	if len(d.Inputs) != net.InputSize() {
+		return false
+	} else if d.HasLabel {
+		return len(d.Outputs) == 0 && d.Label >= 0 && d.Label < net.OutputSize()
+	}
+
+	return (len(d.Outputs) == 0 && net.hasDelay) || len(d.Outputs) == net.OutputSize()
 }
 
 // DataSupplier is the primary method of providing datasets to the Network, either for training or
@@ -57,15 +129,55 @@ type Sequential interface {
 	SetEnded(int) bool
 }
 
+// Epochal builds upon DataSupplier, for datasets that consist of repeated passes (epochs) through
+// the same set of samples.
+type Epochal interface {
+	DataSupplier
+
+	// EpochEnded returns whether or not the most recent sample was the last in its epoch, given the
+	// current iteration. EpochEnded is called immediately after BatchEnded.
+	EpochEnded(int) bool
+}
+
+// Shuffleable builds upon DataSupplier, for datasets that can change the order in which their
+// samples are given. Data provides this.
+type Shuffleable interface {
+	DataSupplier
+
+	// Shuffle randomly reorders the samples, using the random number generator provided. If the
+	// DataSupplier is also Epochal, Shuffle will only be called at the start of an epoch.
+	Shuffle(*rand.Rand)
+}
+
+// Reorderable builds upon Shuffleable, for datasets whose current order can be recorded and later
+// restored, so that training can be resumed exactly (see SaveTrainingState). Data provides this.
+type Reorderable interface {
+	Shuffleable
+
+	// Order returns a copy of the current order of the samples, as indexes into the dataset.
+	Order() []int
+
+	// SetOrder restores an order given by Order, returning an error if it is not valid for the
+	// dataset.
+	SetOrder([]int) error
+}
+
 // Result is a wrapper for sending back the progress of the training or testing
 type Result struct {
 	// The iteration the result is being sent before
 	Iteration int
 
-	// Average cost, from the Network's CostFunction
+	// The number of epochs that have been completed within the current call to Train. This will
+	// always be zero if the training data is not Epochal.
+	Epoch int
+
+	// The average cost per sample, where the cost of each sample is given by the Network's
+	// CostFunction. Only samples with outputs are counted, and each is weighted by its Weight. For
+	// status updates, this is averaged over the samples since the previous update; for tests, it
+	// is over the entire test.
 	Cost float64
 
-	// The fraction correct, as per IsCorrect() from TrainArgs
+	// The fraction correct, as per IsCorrect() from TrainArgs, averaged in the same way as Cost
 	// 0 → 1
 	Correct float64
 
@@ -73,10 +185,38 @@ type Result struct {
 	IsTest bool
 }
 
+// EpochResult is the summary of a single epoch of training, given to TrainArgs.OnEpoch
+type EpochResult struct {
+	// The number of epochs that have been completed within the current call to Train, including
+	// this one
+	Epoch int
+
+	// The average cost and fraction correct on the training data over the epoch, in the same
+	// manner as for Result
+	Cost, Correct float64
+
+	// The average cost and fraction correct on the validation data at the end of the epoch. If
+	// there is no validation data, both will be NaN.
+	ValCost, ValCorrect float64
+
+	// The time spent training during the epoch. This does not include time spent testing,
+	// validating, or in calls to Update or OnEpoch.
+	Duration time.Duration
+
+	// The number of training samples processed per second, as per Duration. If Duration is zero,
+	// so is Throughput.
+	Throughput float64
+}
+
 // TrainArgs serves to allow optional arguments to (*Network).Train()
 type TrainArgs struct {
 	TrainData DataSupplier
 
+	// Data is an alternative to TrainData, for datasets that are provided as a DataSource. It is
+	// only used if TrainData is nil, in which case it is converted by FromSource with a batch size
+	// of 1. For larger batches, FromSource should be used to set TrainData instead.
+	Data DataSource
+
 	// TestData is the source of cross-validation data while training. This can be nil if
 	// ShouldTest is also nil
 	TestData DataSupplier
@@ -86,6 +226,31 @@ type TrainArgs struct {
 	// work as intended).
 	ShouldTest func(int) bool
 
+	// Validation is an optional source of data to test the Network on at the end of every epoch.
+	// The results are given to Update with IsTest = true. If Validation is not nil, TrainData must
+	// be Epochal; both Data and FromSource provide this. If Validation provides no samples, no
+	// results will be sent.
+	//
+	// Validation cannot be used with recurrent Networks.
+	Validation DataSource
+
+	// OnEpoch is an optional alternative to Update for reporting progress, called at the end of
+	// every epoch (after validation, if there is any) with a summary of the epoch. If OnEpoch is
+	// not nil, TrainData must be Epochal.
+	OnEpoch func(EpochResult)
+
+	// CheckpointEvery, if greater than zero, causes the Network to be saved every CheckpointEvery
+	// epochs, so that training can be resumed if it is interrupted. Each checkpoint is saved (with
+	// Save) to its own directory within CheckpointPath, named by the number of epochs completed --
+	// e.g. "<CheckpointPath>/epoch-5". Existing checkpoints with the same name are overwritten.
+	//
+	// Checkpoints are only saved once all changes to the weights have been applied, so if the
+	// end of an epoch is partway through a batch (or accumulated batches), the checkpoint will be
+	// saved at the end of that batch. If CheckpointEvery is greater than zero, TrainData must be
+	// Epochal.
+	CheckpointEvery int
+	CheckpointPath  string
+
 	// SendStatus indicates whether or not to send back general information about the status of the
 	// training since the last time 'true' was returned. SendStatus can be left nil to represent an
 	// unconditional false.
@@ -100,8 +265,87 @@ type TrainArgs struct {
 	// continue. Training will stop if 'false' is returned.
 	RunCondition func(int) bool
 
+	// AccumSteps, if greater than one, is the number of batches over which changes are accumulated
+	// before they are applied to the weights. This gives the same effect as using batches that are
+	// AccumSteps times larger. Any changes that have not been applied when training finishes will
+	// be applied then.
+	AccumSteps int
+
+	// ClipNorm, if greater than zero, limits the size of each change to the weights of the Network.
+	// Before the changes from a batch are applied, the L2 norm of all of them together is
+	// calculated; if it is greater than ClipNorm, every change is scaled down uniformly so that the
+	// norm is equal to ClipNorm.
+	ClipNorm float64
+
+	// LossScale, if greater than zero, is a factor that the derivatives of the cost are multiplied
+	// by before backpropagation, keeping small deltas from vanishing to zero. The gradients of the
+	// weights are divided by the same factor before they are given to the Optimizers, so training
+	// is otherwise unaffected. A LossScale of zero is treated as one.
+	LossScale float64
+
+	// EMADecay, if greater than zero, causes an exponential moving average of the weights to be
+	// tracked. Each time the weights are changed, the averages are updated by:
+	//	ema = EMADecay*ema + (1-EMADecay)*weight
+	// EMADecay should be in (0, 1), typically close to 1 (e.g. 0.999). The averaged weights can be
+	// used with UseEMAWeights. They are not saved with the Network.
+	EMADecay float64
+
+	// Context can optionally be provided to allow training to be stopped from elsewhere. It is
+	// checked before each iteration, alongside RunCondition. If it is cancelled, Train will return
+	// the Context's error once it has finished up, in the same way as it would if RunCondition had
+	// returned false: any changes saved from an incomplete batch will still be applied. For
+	// recurrent Networks, a sequence that is only partway through will be discarded without
+	// adjusting the Network.
+	Context context.Context
+
+	// RecordUpdateNorms, if true, causes the L2 norm of the total change to each trainable Node's
+	// weights to be recorded at the end of every epoch. They can be retrieved afterwards with
+	// UpdateNorms. Changes that have not been applied by the end of an epoch are counted in the
+	// next one. If RecordUpdateNorms is true, TrainData must be Epochal.
+	RecordUpdateNorms bool
+
+	// DivergenceEpochs, if greater than zero, causes training to be stopped with type DivergedError
+	// if the average cost on the training data (as given to OnEpoch) increases in each of
+	// DivergenceEpochs consecutive epochs, which is usually a sign that the learning rate is too
+	// high. This is only checked once that many epochs (plus one, for the first comparison) have
+	// finished. If DivergenceEpochs is greater than zero, TrainData must be Epochal.
+	DivergenceEpochs int
+
+	// Shuffle, if true, causes TrainData to be shuffled before training begins and again at the
+	// end of every epoch. If Shuffle is true, TrainData must be Shuffleable; Data provides this.
+	Shuffle bool
+
+	// Seed is the seed for the random number generator used to shuffle TrainData, if Shuffle is
+	// true. Each call to Train has its own generator, so that the order of the samples is
+	// independent of any other randomness. If Seed is not zero, it is used directly, so two runs
+	// with the same Seed, data, and initial weights will be identical. Otherwise, the seed is taken
+	// from the generator that is shared by badstudent (see Seed and Rand); this is reproducible
+	// only if that generator has been seeded.
+	Seed int64
+
+	// Resume, if true, causes training to continue from where the previous call to Train stopped,
+	// or from the state restored by LoadTrainingState, instead of starting over. The number of
+	// iterations and epochs continue to be counted from where they were, so RunCondition should be
+	// the same as for the original run. If Shuffle is true, the generator used for shuffling is
+	// continued, and TrainData is not shuffled again before training; if the state was restored by
+	// LoadTrainingState, the order of TrainData is also restored, for which it must be Reorderable.
+	//
+	// Provided that all of the other arguments are the same (and TrainData gives its samples based
+	// on the iteration, as Data does), a resumed run will be identical to one that was never
+	// interrupted.
+	Resume bool
+
+	// CheckFinite, if true, causes the values and weights of every Node (along with any changes to
+	// the weights that have been saved) to be checked after each time the Network is adjusted. If
+	// any are NaN or ±Inf, training is stopped immediately with type NotFiniteError. This allows
+	// divergence to be detected as soon as it happens, at the cost of some speed. The values of
+	// input Nodes are not checked, because NaN inputs mark missing values.
+	CheckFinite bool
+
 	// IsCorrect returns whether or not the network outputs are correct, given the target outputs.
-	// In order, it is given: outputs; targets.
+	// In order, it is given: outputs; targets. CorrectRound, CorrectThreshold, CorrectHighest, and
+	// CorrectWithin provide a few common criteria. If IsCorrect is nil, no outputs will be counted
+	// as correct.
 	//
 	// The length of both provided slices is guaranteed to be equal.
 	IsCorrect func([]float64, []float64) bool
@@ -109,6 +353,19 @@ type TrainArgs struct {
 	// Update is how testing and status updates are returned. If both ShouldTest and SendData are
 	// nil, then Update can also be left nil.
 	Update func(Result)
+
+	// ResultsBuffer, if greater than zero, causes Update to be called from a separate goroutine,
+	// with up to that many Results waiting to be given to it, so that a slow Update doesn't slow
+	// training. Once the buffer is full, training waits for Update unless DropResults is true.
+	// Train does not return until every Result has been given to Update.
+	ResultsBuffer int
+
+	// DropResults, if true, also causes Update to be called from a separate goroutine, but instead
+	// of waiting when the buffer is full, the oldest waiting Result is discarded. The most recent
+	// Result is never dropped, so the final Result will always be given to Update. In this case,
+	// Train does not wait for Update, which may still be running once Train returns. If
+	// ResultsBuffer is less than one, a buffer of one is used.
+	DropResults bool
 }
 
 // TrainContext provides additional context to training/testing-based errors. Iterations are stored
@@ -155,7 +412,15 @@ func (err DoesNotFitError) Error() string {
 		erroneous += fmt.Sprintf(" Inputs expected %d, got %d.", err.Net.InputSize(), len(err.D.Inputs))
 	}
 
-	if len(err.D.Outputs) != 0 && len(err.D.Outputs) != err.Net.OutputSize() {
+	if err.D.HasLabel {
+		if len(err.D.Outputs) != 0 {
+			erroneous += " Both Outputs and Label given."
+		}
+
+		if err.D.Label < 0 || err.D.Label >= err.Net.OutputSize() {
+			erroneous += fmt.Sprintf(" Label %d out of range for %d outputs.", err.D.Label, err.Net.OutputSize())
+		}
+	} else if len(err.D.Outputs) != 0 && len(err.D.Outputs) != err.Net.OutputSize() {
 		erroneous += fmt.Sprintf(" Outputs expected %d, got %d.", err.Net.OutputSize(), len(err.D.Outputs))
 	}
 
@@ -163,30 +428,200 @@ func (err DoesNotFitError) Error() string {
 		err.Iteration, err.Net.InputSize(), err.Net.OutputSize(), len(err.D.Inputs), len(err.D.Outputs), erroneous)
 }
 
+// NotFiniteError results from values or weights becoming NaN or ±Inf during training, if
+// TrainArgs.CheckFinite is true. It gives the first Node (in the order they were added) where this
+// was found.
+type NotFiniteError struct {
+	TrainContext
+
+	// The epoch of training, as counted by Train
+	Epoch int
+
+	N *Node
+
+	// What is not finite: either "values", "weights", or "changes to weights"
+	Of string
+}
+
+func (err NotFiniteError) Error() string {
+	return fmt.Sprintf("Non-finite %s in Node %v. Iteration: %d, epoch: %d.", err.Of, err.N, err.Iteration, err.Epoch)
+}
+
+// DivergedError results from the average training cost increasing for TrainArgs.DivergenceEpochs
+// consecutive epochs.
+type DivergedError struct {
+	TrainContext
+
+	// The epoch of training, as counted by Train
+	Epoch int
+
+	// The average training cost of each epoch that was compared, oldest first
+	Costs []float64
+}
+
+func (err DivergedError) Error() string {
+	return fmt.Sprintf("Training diverged: cost increased for %d consecutive epochs %v. Iteration: %d, epoch: %d.",
+		len(err.Costs)-1, err.Costs, err.Iteration, err.Epoch)
+}
+
+// CostMismatchError results from the CostFunction of a Network being incompatible with the number
+// of outputs, as given by SizedCostFunction.
+type CostMismatchError struct {
+	// The TypeString of the CostFunction
+	CostFunction string
+
+	// The number of outputs of the Network
+	Outputs int
+
+	// The error given by CheckSize
+	Err error
+}
+
+func (err CostMismatchError) Error() string {
+	return fmt.Sprintf("CostFunction %q cannot be used with %d outputs: %v", err.CostFunction, err.Outputs, err.Err)
+}
+
+// costWindow is a ring buffer of the average training costs of the most recent epochs, for
+// TrainArgs.DivergenceEpochs
+type costWindow struct {
+	costs []float64
+	next  int
+	full  bool
+}
+
+func (w *costWindow) add(cost float64) {
+	w.costs[w.next] = cost
+	w.next = (w.next + 1) % len(w.costs)
+	w.full = w.full || w.next == 0
+}
+
+// ordered returns the costs in the window, oldest first
+func (w *costWindow) ordered() []float64 {
+	return append(append([]float64(nil), w.costs[w.next:]...), w.costs[:w.next]...)
+}
+
+// increasing returns whether or not the window is full, with every cost greater than the last
+func (w *costWindow) increasing() bool {
+	if !w.full {
+		return false
+	}
+
+	cs := w.ordered()
+	for i := 1; i < len(cs); i++ {
+		if !(cs[i] > cs[i-1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// asyncUpdate returns a function that passes Results to 'update' from a separate goroutine, with a
+// buffer of the given size, along with a function to call once there are no more Results. If
+// 'drop' is true, the oldest buffered Result is discarded instead of waiting for space in the
+// buffer, and 'finish' doesn't wait for the remaining Results to be given to 'update'.
+func asyncUpdate(update func(Result), buffer int, drop bool) (send func(Result), finish func()) {
+	if drop && buffer < 1 {
+		buffer = 1
+	}
+
+	ch := make(chan Result, buffer)
+	done := make(chan struct{})
+	go func() {
+		for r := range ch {
+			update(r)
+		}
+
+		close(done)
+	}()
+
+	send = func(r Result) {
+		if !drop {
+			ch <- r
+			return
+		}
+
+		// Only this function sends on ch, so there will be space once the oldest has been removed
+		for {
+			select {
+			case ch <- r:
+				return
+			default:
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+	}
+
+	finish = func() {
+		close(ch)
+		if !drop {
+			<-done
+		}
+	}
+
+	return
+}
+
 // Train does what it says. It trains the Network following the conditions laid out in the
 // arguments provided.
 //
 // Train has several error conditions:
 //	(0) args.Runcondition == nil;
-//	(1) args.TrainData == nil;
+//	(1) args.TrainData == nil and args.Data == nil;
 //	(2) args.TrainData is not sequential but Network has delay;
 //	(3) args.TestData is not sequential but Network has delay;
 //	(4) args.ShouldTest != nil but args.TestData == nil;
-//	(5) Failures to run TrainData.Get() or TestData.Get();
-//	(6) Data provided by Get() doesn't fit Network;
+//	(5) args.Validation != nil but the Network has delay;
+//	(6) args.Validation != nil, args.OnEpoch != nil, args.CheckpointEvery > 0,
+//		args.DivergenceEpochs > 0, or args.RecordUpdateNorms is true, but args.TrainData is not
+//		Epochal;
+//	(7) Failures to run TrainData.Get() or TestData.Get(), or to read from args.Validation;
+//	(8) Data provided by Get() doesn't fit Network;
+//	(9) args.Context is cancelled;
+//	(10) args.CheckFinite is true and the Network has NaN or ±Inf values or weights;
+//	(11) Failures to save checkpoints;
+//	(12) args.Shuffle is true but args.TrainData is not Shuffleable;
+//	(13) args.DivergenceEpochs > 0 and the training cost has increased for that many epochs;
+//	(14) args.LossScale < 0;
+//	(15) the Network's CostFunction is a SizedCostFunction that can't be used with its outputs;
+//	(16) args.Resume is true and the order of TrainData is to be restored, but it is not
+//		Reorderable or the order is invalid for it;
 // (0) and (1) return type NilArgError, (2) and (3) return ErrTrainNotSequential and
-// ErrTestNotSequential, respectively. (4) returns ErrShouldTestButNil, (5) gives type
-// GetdataError, and (6) returns type DoesNotFitError.
+// ErrTestNotSequential, respectively. If args.Data is used, any errors from FromSource will also
+// be returned. (4) returns ErrShouldTestButNil, (5) gives ErrTestNotSequential, (6) gives
+// ErrNotEpochal, (7) gives type GetdataError, (8) returns type DoesNotFitError, (9) returns
+// args.Context.Err(), (10) gives type NotFiniteError, (11) gives any error from Save, (12) gives
+// ErrNotShuffleable, (13) gives type DivergedError, (14) gives ErrInvalidLossScale, (15) gives
+// type CostMismatchError, and (16) gives ErrNotReorderable or any error from SetOrder.
 func (net *Network) Train(args TrainArgs) error {
 	// handle error cases and set defaults
 	var trainSeq Sequential
+	var trainEpochs Epochal
+	var trainShuffle Shuffleable
 	{
+		// this is checked first so that no data is read if the Network can't be trained
+		if sc, ok := net.cf.(SizedCostFunction); ok {
+			if err := sc.CheckSize(net.OutputSize()); err != nil {
+				return CostMismatchError{sc.TypeString(), net.OutputSize(), err}
+			}
+		}
+
 		if args.Update == nil {
 			args.Update = func(r Result) {}
 		}
 
 		if args.TrainData == nil {
-			return NilArgError{"TrainData"}
+			if args.Data == nil {
+				return NilArgError{"TrainData"}
+			}
+
+			var err error
+			if args.TrainData, err = FromSource(args.Data, 1); err != nil {
+				return err
+			}
 		}
 
 		var ok bool
@@ -204,6 +639,21 @@ func (net *Network) Train(args TrainArgs) error {
 			return ErrTestNotSequential
 		}
 
+		if args.Validation != nil && net.hasDelay {
+			return ErrTestNotSequential
+		}
+
+		// epochs are counted whenever possible, so that they are available to Operators
+		if trainEpochs, ok = args.TrainData.(Epochal); !ok && (args.Validation != nil || args.OnEpoch != nil || args.CheckpointEvery > 0 || args.DivergenceEpochs > 0 || args.RecordUpdateNorms) {
+			return ErrNotEpochal
+		}
+
+		if args.Shuffle {
+			if trainShuffle, ok = args.TrainData.(Shuffleable); !ok {
+				return ErrNotShuffleable
+			}
+		}
+
 		if args.SendStatus == nil {
 			args.SendStatus = func(i int) bool { return false }
 		}
@@ -215,16 +665,106 @@ func (net *Network) Train(args TrainArgs) error {
 		if args.IsCorrect == nil {
 			args.IsCorrect = func(a, b []float64) bool { return false }
 		}
+
+		if args.LossScale < 0 {
+			return ErrInvalidLossScale
+		}
+	}
+
+	if args.ResultsBuffer > 0 || args.DropResults {
+		var finish func()
+		args.Update, finish = asyncUpdate(args.Update, args.ResultsBuffer, args.DropResults)
+		defer finish()
+	}
+
+	if !args.Resume {
+		net.longIter += net.iter
+		net.iter = 0
+		net.epoch = 0
+	}
+
+	net.updateNorms, net.normStart = nil, nil
+	if args.RecordUpdateNorms {
+		net.updateNorms = make(map[string][]float64)
+		net.startUpdateNorms()
+	}
+
+	// shuffling has its own generator, so that it isn't affected by any other randomness. It is
+	// kept with the Network so that it can be saved by SaveTrainingState.
+	var shuffleRand *rand.Rand
+	if trainShuffle != nil {
+		if args.Resume && net.shuffleSrc != nil {
+			if net.resumeOrder != nil {
+				r, ok := trainShuffle.(Reorderable)
+				if !ok {
+					return ErrNotReorderable
+				} else if err := r.SetOrder(net.resumeOrder); err != nil {
+					return err
+				}
+
+				net.resumeOrder = nil
+			}
+
+			shuffleRand = rand.New(net.shuffleSrc)
+		} else {
+			seed := args.Seed
+			if seed == 0 {
+				seed = Rand().Int63()
+			}
+
+			net.shuffleSrc = &splitMix{uint64(seed)}
+			shuffleRand = rand.New(net.shuffleSrc)
+			trainShuffle.Shuffle(shuffleRand)
+		}
+	} else if !args.Resume {
+		net.shuffleSrc = nil
 	}
 
-	net.longIter += net.iter
-	net.iter = 0
+	net.shuffled = trainShuffle
+	net.resumeOrder = nil
+
+	net.setTraining(true)
+	defer net.setTraining(false)
+	defer net.releaseWeights()
+
+	net.lossScale = args.LossScale
+	defer func() { net.lossScale = 0 }()
+
+	// the averaged weights are only for evaluation; they are never trained directly
+	net.UseRawWeights()
+	if args.EMADecay > 0 {
+		net.startEMA()
+	}
 
+	// costs and fractions correct are weighted averages, so the sizes are the sums of the weights
 	var statusCost, statusCorrect float64
-	var statusSize int
+	var statusSize float64
+
+	epoch := net.epoch
+	var epochEnded bool
+
+	// whether or not a checkpoint should be saved once there are no unapplied changes
+	var checkpointNext bool
+
+	// for args.OnEpoch
+	var epochCost, epochCorrect, epochSize float64
+	var epochSamples int
+	var epochTime time.Duration
+
+	// set if training is stopped by args.Context
+	var cancelErr error
+
+	// for args.DivergenceEpochs; one more than the number of increases, for the first comparison
+	var divergence costWindow
+	if args.DivergenceEpochs > 0 {
+		divergence.costs = make([]float64, args.DivergenceEpochs+1)
+	}
+
+	// the number of batches that have finished, for args.AccumSteps
+	var batches int
 
 	// used only for training RNNs
-	var targets [][]float64
+	var sequence []Datum
 	var betweenSequences, testNext, batchNext bool = net.hasDelay, false, false // a (very) slight optimization
 
 	// for args.RunCondition() (conditional is embedded farther down)
@@ -232,8 +772,9 @@ func (net *Network) Train(args TrainArgs) error {
 		if args.SendStatus(net.iter) && net.iter != 0 {
 			r := Result{
 				Iteration: net.iter,
-				Cost:      statusCost / float64(statusSize),
-				Correct:   statusCorrect / float64(statusSize),
+				Epoch:     epoch,
+				Cost:      statusCost / statusSize,
+				Correct:   statusCorrect / statusSize,
 				IsTest:    false,
 			}
 
@@ -254,6 +795,7 @@ func (net *Network) Train(args TrainArgs) error {
 
 				r := Result{
 					Iteration: net.iter,
+					Epoch:     epoch,
 					Cost:      cost,
 					Correct:   correct,
 					IsTest:    true,
@@ -263,12 +805,97 @@ func (net *Network) Train(args TrainArgs) error {
 			}
 		}
 
+		if epochEnded {
+			epochEnded = false
+
+			if args.RecordUpdateNorms {
+				net.recordUpdateNorms()
+			}
+
+			valCost, valCorrect := math.NaN(), math.NaN()
+
+			// If there is no validation data, or it gives no samples, there's nothing to test.
+			// Any other error is from the source itself.
+			var data DataSupplier
+			if args.Validation != nil {
+				var err error
+				if data, err = FromSource(args.Validation, 1); err != nil && err != ErrNoData {
+					return GetDataError{TrainContext{net.iter, true}, err}
+				}
+			}
+
+			if data != nil {
+				cost, correct, err := net.Test(data, args.IsCorrect)
+				if err != nil {
+					return err
+				}
+
+				valCost, valCorrect = cost, correct
+
+				r := Result{
+					Iteration: net.iter,
+					Epoch:     epoch,
+					Cost:      cost,
+					Correct:   correct,
+					IsTest:    true,
+				}
+
+				args.Update(r)
+			}
+
+			if args.OnEpoch != nil {
+				// the epoch may have been too quick to be measured
+				var throughput float64
+				if epochTime > 0 {
+					throughput = float64(epochSamples) / epochTime.Seconds()
+				}
+
+				args.OnEpoch(EpochResult{
+					Epoch:      epoch,
+					Cost:       epochCost / epochSize,
+					Correct:    epochCorrect / epochSize,
+					ValCost:    valCost,
+					ValCorrect: valCorrect,
+					Duration:   epochTime,
+					Throughput: throughput,
+				})
+			}
+
+			if args.DivergenceEpochs > 0 {
+				divergence.add(epochCost / epochSize)
+				if divergence.increasing() {
+					return DivergedError{TrainContext{net.iter, false}, epoch, divergence.ordered()}
+				}
+			}
+
+			epochCost, epochCorrect = 0, 0
+			epochSize, epochSamples = 0, 0
+			epochTime = 0
+
+			if args.CheckpointEvery > 0 && epoch%args.CheckpointEvery == 0 {
+				checkpointNext = true
+			}
+		}
+
+		if checkpointNext && !net.hasSavedChanges {
+			checkpointNext = false
+
+			if err := net.checkpoint(args.CheckpointPath, epoch); err != nil {
+				return err
+			}
+		}
+
 		if !args.RunCondition(net.iter) {
 			break
+		} else if args.Context != nil && args.Context.Err() != nil {
+			cancelErr = args.Context.Err()
+			break
 		}
 
 		betweenSequences = false
 
+		start := time.Now()
+
 		d, err := args.TrainData.Get(net.iter)
 		if err != nil {
 			return GetDataError{TrainContext{net.iter, false}, err}
@@ -287,31 +914,67 @@ func (net *Network) Train(args TrainArgs) error {
 		var cost float64
 		var correct bool
 
-		if len(d.Outputs) != 0 { // will always be true for non-recurrent
-			cost = net.cf.Cost(outs, d.Outputs)
-			correct = args.IsCorrect(outs, d.Outputs)
+		if d.hasTargets() { // will always be true for non-recurrent
+			cost = d.cost(net.reduced(net.cf), outs)
+			correct = args.IsCorrect(outs, d.targets(len(outs)))
 		}
 
 		endBatch := args.TrainData.BatchEnded(net.iter)
 
+		// With accumulation, only every AccumSteps batches are treated as the end of a batch
+		if endBatch && args.AccumSteps > 1 {
+			batches++
+			endBatch = batches%args.AccumSteps == 0
+		}
+
+		endEpoch := trainEpochs != nil && trainEpochs.EpochEnded(net.iter)
+
 		if !net.hasDelay {
-			net.getDeltas(d.Outputs)
+			net.getDeltas(d, net.reduced(net.cf))
 
 			// saveChanges = net.hasSavedChanges || !endBatch
-			net.adjust(net.hasSavedChanges || !endBatch)
+			// Changes must always be saved for clipping, so that they can be scaled first.
+			net.adjust(net.hasSavedChanges || !endBatch || args.ClipNorm > 0)
+
+			if args.CheckFinite {
+				if n, of := net.firstNonFinite(); n != nil {
+					return NotFiniteError{TrainContext{net.iter, false}, epoch, n, of}
+				}
+			}
 
 			if endBatch && net.hasSavedChanges {
+				net.clipChanges(args.ClipNorm)
 				net.AddWeights()
 			}
+
+			// the weights are changed at the end of each batch
+			if endBatch {
+				net.updateEMA(args.EMADecay)
+			}
 		} else {
-			targets = append(targets, d.Outputs)
+			sequence = append(sequence, d)
 
 			if trainSeq.SetEnded(net.iter) {
 
 				// saveChanges = (endBatch || batchNext)
-				net.adjustRecurrent(targets, !(endBatch || batchNext))
+				net.adjustRecurrent(sequence, !(endBatch || batchNext) || args.ClipNorm > 0)
+
+				if args.CheckFinite {
+					if n, of := net.firstNonFinite(); n != nil {
+						return NotFiniteError{TrainContext{net.iter, false}, epoch, n, of}
+					}
+				}
 
-				targets = nil
+				if (endBatch || batchNext) && args.ClipNorm > 0 {
+					net.clipChanges(args.ClipNorm)
+					net.AddWeights()
+				}
+
+				if endBatch || batchNext {
+					net.updateEMA(args.EMADecay)
+				}
+
+				sequence = nil
 				betweenSequences = true
 				batchNext = false
 			} else if endBatch {
@@ -319,34 +982,137 @@ func (net *Network) Train(args TrainArgs) error {
 			}
 		}
 
-		if len(d.Outputs) != 0 {
-			statusCost += cost
+		epochTime += time.Since(start)
+		epochSamples++
+
+		if d.hasTargets() {
+			w := d.weight()
+
+			statusCost += w * cost
+			epochCost += w * cost
 			if correct {
-				statusCorrect += 1.0
+				statusCorrect += w
+				epochCorrect += w
+			}
+			statusSize += w
+			epochSize += w
+		}
+
+		if endEpoch {
+			epoch++
+			epochEnded = true
+			net.epoch = epoch
+
+			if trainShuffle != nil {
+				trainShuffle.Shuffle(shuffleRand)
 			}
-			statusSize++
 		}
 
+		// any temporary copies of the weights are no longer needed until the next iteration
+		net.releaseWeights()
 		net.iter++
 	}
 
 	// finish up before returning
 	{
 		if net.hasSavedChanges {
+			net.clipChanges(args.ClipNorm)
 			net.AddWeights()
+			net.updateEMA(args.EMADecay)
 		}
 
 		if net.hasDelay {
-			net.ClearDelays()
+			net.ResetState()
+		}
+
+		if checkpointNext {
+			if err := net.checkpoint(args.CheckpointPath, epoch); err != nil {
+				return err
+			}
 		}
 	}
 
-	return nil
+	return cancelErr
+}
+
+// checkpoint saves the Network to the directory for the given epoch within 'dir', as described for
+// TrainArgs.CheckpointEvery, overwriting any previous checkpoint there
+func (net *Network) checkpoint(dir string, epoch int) error {
+	_, err := net.Save(filepath.Join(dir, fmt.Sprintf("epoch-%d", epoch)), true)
+	return err
+}
+
+// TrainOnline trains the Network incrementally from a stream of samples, with a forward pass,
+// backward pass, and adjustment for each sample as soon as it is received. TrainOnline returns once
+// 'samples' has been closed and every sample from it has been trained on.
+//
+// The remaining arguments are used in the same way as by Train, with a few differences:
+// args.TrainData and args.Data are ignored, and args.RunCondition may be left nil, in which case
+// training will only stop when 'samples' is closed (or args.Context is cancelled). Each sample is
+// its own batch; args.AccumSteps can be used to apply changes to the weights less often. Because
+// the samples do not form epochs, args.Validation, args.OnEpoch, args.CheckpointEvery, and
+// args.RecordUpdateNorms cannot be used.
+//
+// TrainOnline gives the same errors as Train. Because the samples are not Sequential, it cannot be
+// used with Networks that have delay.
+func (net *Network) TrainOnline(samples <-chan Datum, args TrainArgs) error {
+	s := &onlineSupplier{samples: samples, ctx: args.Context}
+
+	cond := args.RunCondition
+	args.RunCondition = func(iter int) bool {
+		if cond != nil && !cond(iter) {
+			return false
+		}
+
+		return s.receive()
+	}
+
+	args.TrainData, args.Data = s, nil
+	return net.Train(args)
+}
+
+// onlineSupplier is the DataSupplier used by TrainOnline. The next sample is received as part of
+// the run condition, so that Train is able to stop once the channel has closed.
+type onlineSupplier struct {
+	samples <-chan Datum
+	ctx     context.Context
+	next    Datum
+}
+
+// receive waits for the next sample, returning false if the channel has closed. If the Context is
+// cancelled while waiting, receive returns true so that Train can handle the cancellation.
+func (s *onlineSupplier) receive() bool {
+	var done <-chan struct{}
+	if s.ctx != nil {
+		done = s.ctx.Done()
+	}
+
+	select {
+	case d, ok := <-s.samples:
+		s.next = d
+		return ok
+	case <-done:
+		return true
+	}
+}
+
+func (s *onlineSupplier) Get(iter int) (Datum, error) {
+	return s.next, nil
+}
+
+func (s *onlineSupplier) BatchEnded(iter int) bool {
+	return true
+}
+
+func (s *onlineSupplier) DoneTesting(iter int) bool {
+	return true
 }
 
 // Test will test the Network on the supplied Data and function for determining whether or not the
-// outputs are correct. Test returns (in order) the average cost of the outputs and the percent of
-// the outputs that are correct.
+// outputs are correct. Test returns (in order) the average cost of the outputs and the fraction of
+// the outputs that are correct. Both are averaged over the samples that have outputs, where the cost
+// of each sample is given by the Network's CostFunction. If the samples have Weights, these are
+// weighted averages.
 //
 // Test has several possible error conditions:
 //	(0) If 'data' is not Sequential, but the Network has delay: ErrTestNotSequential;
@@ -355,71 +1121,166 @@ func (net *Network) Train(args TrainArgs) error {
 // Test also assumes that 'data' is non-nil, and will panic (without a particular error) if that
 // interface is nil.
 func (net *Network) Test(data DataSupplier, isCorrect func([]float64, []float64) bool) (float64, float64, error) {
-	var ok bool
-	var dataSeq Sequential
-	if dataSeq, ok = data.(Sequential); net.hasDelay && !ok {
-		return 0, 0, ErrTestNotSequential
+	avgCost, avgCorrect, _, err := net.average(data, net.reduced(net.cf), isCorrect)
+	return avgCost, avgCorrect, err
+}
+
+// Evaluate measures the Network on the samples given by 'data', without training. It returns the
+// average cost and the fraction of the outputs that are correct, in the same manner as Test. A
+// single pass is made through 'data', after which it will have been Reset.
+//
+// If 'cost' is nil, the Network's CostFunction is used. Either way, the Network's Reduction is
+// applied to it. If 'correct' is nil, no outputs will be counted as correct.
+//
+// Evaluate has a few error conditions:
+//	(0) If 'data' is nil, type NilArgError;
+//	(1) If 'data' gives no samples with outputs, ErrNoData;
+//	(2) If 'data' is a CheckedSource and fails, the error from data.Err(), wrapped in GetDataError
+//		if it is not with the first sample;
+//	(3) Any of the error conditions from Test;
+func (net *Network) Evaluate(data DataSource, cost CostFunction, correct func([]float64, []float64) bool) (avgCost, accuracy float64, err error) {
+	if data == nil {
+		return 0, 0, NilArgError{"DataSource"}
 	}
 
-	var avgCost, avgCorrect float64
-	var testSize int = 0
+	if cost == nil {
+		cost = net.cf
+	}
 
-	// may result in a superfluous flush
-	defer net.ClearDelays()
+	if correct == nil {
+		correct = func(a, b []float64) bool { return false }
+	}
 
-	// done only refers to RNNs and DoneTesting waiting for SetEnded
-	var done bool
+	supplier, err := FromSource(data, 1)
+	if err != nil {
+		return 0, 0, err
+	}
 
-	for {
-		if data.DoneTesting(testSize) {
-			if !net.hasDelay {
-				break
-			}
-			done = true
+	avgCost, accuracy, size, err := net.average(supplier, net.reduced(cost), correct)
+	if err != nil {
+		return 0, 0, err
+	} else if size == 0 {
+		return 0, 0, ErrNoData
+	}
+
+	return avgCost, accuracy, nil
+}
+
+// average gives the average cost and fraction correct of the outputs of the Network on 'data', as
+// described by Test, in addition to the total weight of the samples that were averaged over. It has
+// the same error conditions as Test.
+func (net *Network) average(data DataSupplier, cf CostFunction, isCorrect func([]float64, []float64) bool) (avgCost, avgCorrect, size float64, err error) {
+	// 'size' is the total weight of the samples with outputs, which are the only ones that
+	// contribute to the cost
+	err = net.forEachTest(data, func(d Datum, outs []float64) {
+		w := d.weight()
+
+		avgCost += w * d.cost(cf, outs)
+		if isCorrect(outs, d.targets(len(outs))) {
+			avgCorrect += w
 		}
 
-		if net.hasDelay && dataSeq.SetEnded(testSize) {
-			net.ClearDelays()
-			if done {
-				break
-			}
+		size += w
+	})
+
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	if size != 0 {
+		avgCost /= size
+		avgCorrect /= size
+	}
+
+	return avgCost, avgCorrect, size, nil
+}
+
+// ClassAccuracy tests the Network on the supplied data in the same way as Test, but gives the
+// results for each class separately, for classification problems. The class of each sample is
+// given by the index of its highest target output, and the sample is correct if that is also the
+// index of the highest output of the Network (as per CorrectHighest).
+//
+// For each class (i.e. each output index), 'correct' gives the number of samples that were
+// correct, and 'total' gives the number of samples in that class. Classes that do not appear in the
+// data will have a total of zero. Sample Weights are not taken into account.
+//
+// ClassAccuracy has the same error conditions as Test.
+func (net *Network) ClassAccuracy(data DataSupplier) (correct, total []int, err error) {
+	correct = make([]int, net.OutputSize())
+	total = make([]int, net.OutputSize())
+
+	err = net.forEachTest(data, func(d Datum, outs []float64) {
+		class := d.Label
+		if !d.HasLabel {
+			class = HighestIndex(d.Outputs)
 		}
 
-		testSize++
+		total[class]++
+		if HighestIndex(outs) == class {
+			correct[class]++
+		}
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
 
-		d, err := data.Get(testSize)
+	return correct, total, nil
+}
+
+// forEachTest runs the Network through the samples given by 'data' until data.DoneTesting, calling
+// 'f' with each sample that has outputs and the outputs of the Network for it. This is the shared
+// basis for Test and similar methods, and it has the same error conditions as Test.
+func (net *Network) forEachTest(data DataSupplier, f func(d Datum, outs []float64)) error {
+	var ok bool
+	var dataSeq Sequential
+	if dataSeq, ok = data.(Sequential); net.hasDelay && !ok {
+		return ErrTestNotSequential
+	}
+
+	// may result in a superfluous flush
+	defer net.ResetState()
+
+	// testing may happen in the middle of training
+	defer net.setTraining(net.training)
+	net.setTraining(false)
+
+	for i := 0; ; i++ {
+		d, err := data.Get(i)
 		if err != nil {
-			return 0, 0, GetDataError{TrainContext{net.iter, true}, err}
+			return GetDataError{TrainContext{net.iter, true}, err}
 		} else if !d.Fits(net) {
-			return 0, 0, DoesNotFitError{TrainContext{net.iter, true}, net, d}
+			return DoesNotFitError{TrainContext{net.iter, true}, net, d}
 		}
 
 		// for the same reasons as outlined in (*Network).Train(), we can ignore the error output
 		// from GetOutputs.
 		outs, _ := net.GetOutputs(d.Inputs)
 
-		if len(d.Outputs) == 0 {
-			continue
+		if d.hasTargets() {
+			f(d, outs)
 		}
 
-		avgCost += net.cf.Cost(outs, d.Outputs)
-		if isCorrect(outs, d.Outputs) {
-			avgCorrect += 1
+		// DoneTesting is only called at the end of sequences for recurrent Networks
+		if net.hasDelay {
+			if !dataSeq.SetEnded(i) {
+				continue
+			}
+
+			net.ResetState()
 		}
-	}
 
-	if testSize != 0 {
-		avgCost /= float64(testSize)
-		avgCorrect /= float64(testSize)
+		if data.DoneTesting(i) {
+			return nil
+		}
 	}
-
-	return avgCost, avgCorrect, nil
 }
 
 type internalSupplier struct {
 	get         func(int) (Datum, error)
 	batchEnded  func(int) bool
 	doneTesting func(int) bool
+	epochEnded  func(int) bool
 }
 
 type internalSequential struct {
@@ -439,6 +1300,10 @@ func (s internalSupplier) DoneTesting(iter int) bool {
 	return s.doneTesting(iter)
 }
 
+func (s internalSupplier) EpochEnded(iter int) bool {
+	return s.epochEnded(iter)
+}
+
 func (s internalSequential) SetEnded(iter int) bool {
 	return s.setEnded(iter)
 }
@@ -455,6 +1320,9 @@ func (err DataMissingError) Error() string {
 // Data converts a 3D dataset of float64 to a DataSupplier, which can be used for training or
 // testing. dataset indexing is: [data index][inputs, outputs][values]
 //
+// The returned DataSupplier is Epochal, with each epoch being a single pass through the dataset.
+// It is also Shuffleable (and Reorderable), so it can be used with TrainArgs.Shuffle.
+//
 // N.B.: Data does not check if the data fit a certain network; that will be done during
 // training/testing
 //
@@ -477,16 +1345,58 @@ func Data(dataset [][][]float64, batchSize int) (DataSupplier, error) {
 		}
 	}
 
+	// the order of the samples, which is changed by shuffling
+	order := make([]int, len(d))
+	for i := range order {
+		order[i] = i
+	}
+
 	is := internalSupplier{
 		get: func(iter int) (Datum, error) {
-			i := iter % len(dataset)
-			return Datum{d[i][0], d[i][1]}, nil
+			i := order[iter%len(dataset)]
+			return Datum{Inputs: d[i][0], Outputs: d[i][1]}, nil
 		},
 		batchEnded:  EndEvery(batchSize),
 		doneTesting: EndEvery(len(dataset)),
+		epochEnded:  EndEvery(len(dataset)),
+	}
+
+	return shuffleableSupplier{is, order}, nil
+}
+
+// shuffleableSupplier is the DataSupplier returned by Data, which allows the order of its samples
+// to be changed
+type shuffleableSupplier struct {
+	internalSupplier
+	order []int
+}
+
+func (s shuffleableSupplier) Shuffle(r *rand.Rand) {
+	r.Shuffle(len(s.order), func(i, j int) {
+		s.order[i], s.order[j] = s.order[j], s.order[i]
+	})
+}
+
+func (s shuffleableSupplier) Order() []int {
+	return append([]int(nil), s.order...)
+}
+
+func (s shuffleableSupplier) SetOrder(order []int) error {
+	if len(order) != len(s.order) {
+		return SizeMismatchError{len(s.order), len(order), "order"}
 	}
 
-	return is, nil
+	seen := make([]bool, len(order))
+	for _, i := range order {
+		if i < 0 || i >= len(order) || seen[i] {
+			return ErrInvalidOrder
+		}
+
+		seen[i] = true
+	}
+
+	copy(s.order, order)
+	return nil
 }
 
 // SeqData converts a 3D dataset of float64 to a DataSupplier that is also Sequential. SeqData runs
@@ -504,7 +1414,8 @@ func SeqData(dataset [][][]float64, batchSize, setSize int) (DataSupplier, error
 	if err != nil {
 		return nil, err
 	}
-	is, _ := ds.(internalSupplier)
+	// sequences must stay in order, so the result is not Shuffleable
+	is := ds.(shuffleableSupplier).internalSupplier
 
 	return internalSequential{
 		internalSupplier: is,
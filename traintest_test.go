@@ -0,0 +1,940 @@
+package badstudent_test
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	bs "github.com/sharnoff/badstudent"
+	"github.com/sharnoff/badstudent/costfuncs"
+	"github.com/sharnoff/badstudent/hyperparams"
+	"github.com/sharnoff/badstudent/operators"
+	"github.com/sharnoff/badstudent/optimizers"
+)
+
+func TestTrainValidation(t *testing.T) {
+	const epochs = 5
+
+	net := xorNet(t, 1)
+
+	args := xorArgs(t, epochs*len(xorData))
+	args.Validation = bs.SliceSource(xorData[:2])
+
+	var tests []bs.Result
+	args.Update = func(r bs.Result) {
+		if r.IsTest {
+			tests = append(tests, r)
+		}
+	}
+
+	var epochResults []bs.EpochResult
+	args.OnEpoch = func(r bs.EpochResult) { epochResults = append(epochResults, r) }
+
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tests) != epochs {
+		t.Fatalf("Got %d validation results, expected one per epoch (%d)", len(tests), epochs)
+	}
+
+	for i, r := range tests {
+		if r.Epoch != i+1 || r.Iteration != (i+1)*len(xorData) {
+			t.Errorf("Validation result %d was at epoch %d, iteration %d; expected %d, %d", i, r.Epoch, r.Iteration, i+1, (i+1)*len(xorData))
+		}
+
+		if epochResults[i].ValCost != r.Cost || epochResults[i].ValCorrect != r.Correct {
+			t.Errorf("Epoch %d: OnEpoch gave validation results %v, %v; Update gave %v, %v",
+				i+1, epochResults[i].ValCost, epochResults[i].ValCorrect, r.Cost, r.Correct)
+		}
+	}
+
+	// the final validation result is from the trained Network, without changing it
+	ws := net.Weights()
+	cost, correct, err := net.Evaluate(bs.SliceSource(xorData[:2]), nil, bs.CorrectRound)
+	if err != nil {
+		t.Fatal(err)
+	} else if last := tests[len(tests)-1]; cost != last.Cost || correct != last.Correct {
+		t.Errorf("Final validation gave %v, %v; expected %v, %v", last.Cost, last.Correct, cost, correct)
+	} else if !sliceEqual(net.Weights(), ws, 0) {
+		t.Errorf("Evaluating the Network changed its weights")
+	}
+}
+
+func TestTrainEmptyValidation(t *testing.T) {
+	net := xorNet(t, 1)
+
+	args := xorArgs(t, 3*len(xorData))
+	args.Validation = bs.SliceSource(nil)
+
+	var tests int
+	args.Update = func(r bs.Result) {
+		if r.IsTest {
+			tests++
+		}
+	}
+
+	var epochs int
+	args.OnEpoch = func(r bs.EpochResult) {
+		epochs++
+		if !math.IsNaN(r.ValCost) || !math.IsNaN(r.ValCorrect) {
+			t.Errorf("Epoch %d had validation results %v, %v without validation data", r.Epoch, r.ValCost, r.ValCorrect)
+		}
+	}
+
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if tests != 0 {
+		t.Errorf("Got %d validation results from an empty validation set", tests)
+	} else if epochs != 3 {
+		t.Errorf("OnEpoch was called %d times, expected 3", epochs)
+	}
+}
+
+func TestTrainCancel(t *testing.T) {
+	net := xorNet(t, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	args := xorArgs(t, 0)
+	args.RunCondition = func(int) bool { return true }
+	args.Context = ctx
+
+	done := make(chan error, 1)
+	go func() { done <- net.Train(args) }()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Train returned %v after cancellation, expected context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Train did not return within one second of cancellation")
+	}
+
+	// the weights are left usable
+	for i, w := range net.Weights() {
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			t.Fatalf("Weight %d is %v after cancellation", i, w)
+		}
+	}
+
+	if _, err := net.GetOutputs(xorData[0][0]); err != nil {
+		t.Errorf("Network could not be used after cancellation: %v", err)
+	}
+}
+
+func TestTrainOnlineCancel(t *testing.T) {
+	net := xorNet(t, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// nothing is ever sent, so training can only stop from the Context
+	samples := make(chan bs.Datum)
+
+	done := make(chan error, 1)
+	go func() { done <- net.TrainOnline(samples, bs.TrainArgs{Context: ctx}) }()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("TrainOnline returned %v after cancellation, expected context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TrainOnline did not return within one second of cancellation")
+	}
+}
+
+func TestTrainClipNorm(t *testing.T) {
+	const clip = 0.25
+
+	// a huge target gives a huge gradient
+	dataset := [][][]float64{{{1, -1}, {1e6}}}
+
+	step := func(clipNorm float64) float64 {
+		net := xorNet(t, 1)
+		before := net.Weights()
+
+		args := bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(1), ClipNorm: clipNorm}
+		if err := net.Train(args); err != nil {
+			t.Fatal(err)
+		}
+
+		var sum float64
+		for i, w := range net.Weights() {
+			sum += (w - before[i]) * (w - before[i])
+		}
+
+		return math.Sqrt(sum)
+	}
+
+	if norm := step(0); norm <= clip {
+		t.Fatalf("Unclipped step had norm %v, expected more than %v", norm, clip)
+	}
+
+	if norm := step(clip); !approxEqual(norm, clip, 1e-9) {
+		t.Errorf("Clipped step had norm %v, expected %v", norm, clip)
+	}
+}
+
+func TestTrainOnEpoch(t *testing.T) {
+	tests := []struct {
+		iterations, epochs int
+	}{
+		{5 * len(xorData), 5},
+		// stopping partway through an epoch doesn't complete it
+		{5 * len(xorData) / 2, 2},
+	}
+
+	for _, test := range tests {
+		net := xorNet(t, 1)
+		args := xorArgs(t, test.iterations)
+
+		var results []bs.EpochResult
+		args.OnEpoch = func(r bs.EpochResult) { results = append(results, r) }
+
+		if err := net.Train(args); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != test.epochs {
+			t.Errorf("%d iterations: OnEpoch was called %d times, expected %d", test.iterations, len(results), test.epochs)
+			continue
+		}
+
+		for i, r := range results {
+			if r.Epoch != i+1 {
+				t.Errorf("%d iterations: result %d was for epoch %d", test.iterations, i, r.Epoch)
+			} else if math.IsNaN(r.Cost) || r.Correct < 0 || r.Correct > 1 {
+				t.Errorf("%d iterations: epoch %d had cost %v, fraction correct %v", test.iterations, r.Epoch, r.Cost, r.Correct)
+			}
+		}
+	}
+}
+
+func TestTrainAverageCost(t *testing.T) {
+	// a single neuron giving the identity, which isn't changed by training
+	net := new(bs.Network)
+	out := net.Add(operators.Neurons(1), net.AddInput([]int{1})).Opt(optimizers.SGD())
+	net.AddHP("learning-rate", hyperparams.Constant(0))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	} else if err := net.SetWeights([]float64{1, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the costs of each sample are 0.5*(1-0)^2, 0.5*(2-0)^2, and 0.5*(3-1)^2
+	dataset := [][][]float64{{{1}, {0}}, {{2}, {0}}, {{3}, {1}}}
+	const expected = (0.5 + 2 + 2) / 3
+
+	var epochs []bs.EpochResult
+	err := net.Train(bs.TrainArgs{
+		Data:         bs.SliceSource(dataset),
+		RunCondition: bs.TrainUntil(2 * len(dataset)),
+		OnEpoch:      func(r bs.EpochResult) { epochs = append(epochs, r) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(epochs) != 2 {
+		t.Fatalf("OnEpoch was called %d times, expected 2", len(epochs))
+	}
+
+	for _, r := range epochs {
+		if !approxEqual(r.Cost, expected, 1e-12) {
+			t.Errorf("Epoch %d had average cost %v, expected %v", r.Epoch, r.Cost, expected)
+		}
+	}
+
+	if cost, _, err := net.Evaluate(bs.SliceSource(dataset), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !approxEqual(cost, expected, 1e-12) {
+		t.Errorf("Evaluate gave average cost %v, expected %v", cost, expected)
+	}
+}
+
+// slowSource is a DataSource that waits before giving each sample
+type slowSource struct {
+	bs.DataSource
+	wait time.Duration
+}
+
+func (s slowSource) Next() ([]float64, []float64, bool) {
+	time.Sleep(s.wait)
+	return s.DataSource.Next()
+}
+
+func TestTrainEpochDuration(t *testing.T) {
+	// epochDurations trains for two epochs of the given size, with slow validation
+	epochDurations := func(repeats int) []bs.EpochResult {
+		var dataset [][][]float64
+		for i := 0; i < repeats; i++ {
+			dataset = append(dataset, xorData...)
+		}
+
+		var results []bs.EpochResult
+		err := xorNet(t, 1).Train(bs.TrainArgs{
+			Data:         bs.SliceSource(dataset),
+			Validation:   slowSource{bs.SliceSource(xorData), 5 * time.Millisecond},
+			RunCondition: bs.TrainUntil(2 * len(dataset)),
+			OnEpoch:      func(r bs.EpochResult) { results = append(results, r) },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return results
+	}
+
+	small, large := epochDurations(1), epochDurations(500)
+	if len(small) != 2 || len(large) != 2 {
+		t.Fatalf("OnEpoch was called %d and %d times, expected 2", len(small), len(large))
+	}
+
+	for _, r := range append(small, large...) {
+		if r.Duration <= 0 || r.Throughput <= 0 {
+			t.Errorf("Epoch %d had duration %v, throughput %v", r.Epoch, r.Duration, r.Throughput)
+		}
+	}
+
+	// validation takes at least 20ms, which training on four samples shouldn't come close to
+	for _, r := range small {
+		if r.Duration >= 20*time.Millisecond {
+			t.Errorf("Epoch %d of 4 samples took %v, which must include validation", r.Epoch, r.Duration)
+		}
+	}
+
+	if small[0].Duration+small[1].Duration >= large[0].Duration+large[1].Duration {
+		t.Errorf("Epochs of 4 samples took longer than epochs of 2000 (%v, %v vs %v, %v)",
+			small[0].Duration, small[1].Duration, large[0].Duration, large[1].Duration)
+	}
+}
+
+// weightedSupplier gives the samples of another DataSupplier with Weights given by 'weight'
+type weightedSupplier struct {
+	bs.DataSupplier
+	weight func(bs.Datum) float64
+}
+
+func (s weightedSupplier) Get(iter int) (bs.Datum, error) {
+	d, err := s.DataSupplier.Get(iter)
+	d.Weight = s.weight(d)
+	return d, err
+}
+
+func (s weightedSupplier) EpochEnded(iter int) bool {
+	return s.DataSupplier.(bs.Epochal).EpochEnded(iter)
+}
+
+func TestTrainSampleWeights(t *testing.T) {
+	// class 1 is rare, and overlaps with class 0 at 0.5
+	var dataset [][][]float64
+	for _, x := range []float64{-1, -1, -1, 0, 0, 0, 0.5, 0.5, 0.5} {
+		dataset = append(dataset, [][]float64{{x}, {0}})
+	}
+	dataset = append(dataset, [][]float64{{0.5}, {1}}, [][]float64{{1}, {1}})
+
+	// boundary trains a logistic regression with each sample of class 1 given the weight, and
+	// returns the input at which the output is 0.5
+	boundary := func(weight float64) float64 {
+		net := new(bs.Network)
+		l := net.Add(operators.Neurons(1), net.AddInput([]int{1})).Opt(optimizers.SGD())
+		l = net.Add(operators.Logistic(), l)
+		net.AddHP("learning-rate", hyperparams.Constant(0.5))
+		if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+			t.Fatal(err)
+		} else if err := net.SetWeights([]float64{0, 0}); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := bs.Data(dataset, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = net.Train(bs.TrainArgs{
+			TrainData: weightedSupplier{data, func(d bs.Datum) float64 {
+				if d.Outputs[0] == 1 {
+					return weight
+				}
+				return 1
+			}},
+			RunCondition: bs.TrainUntil(2000 * len(dataset)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ws := net.Weights()
+		return -ws[1] / ws[0]
+	}
+
+	uniform, weighted := boundary(1), boundary(10)
+	if !(uniform > 0.5 && weighted < 0.5) {
+		t.Errorf("Decision boundary moved from %v to %v when class 1 was upweighted, expected it to cross 0.5", uniform, weighted)
+	}
+}
+
+func TestClassAccuracy(t *testing.T) {
+	// the outputs are exactly the inputs
+	net := new(bs.Network)
+	out := net.Add(operators.Identity(), net.AddInput([]int{3}))
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	// no samples are in class 2, though one is wrongly given it
+	dataset := [][][]float64{
+		{{0.9, 0.1, 0}, {1, 0, 0}},
+		{{0.5, 0.2, 0.3}, {1, 0, 0}},
+		{{0.1, 0.2, 0.7}, {1, 0, 0}},
+		{{0.2, 0.8, 0}, {0, 1, 0}},
+		{{0.6, 0.4, 0}, {0, 1, 0}},
+	}
+
+	data, err := bs.Data(dataset, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	correct, total, err := net.ClassAccuracy(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCorrect, expectedTotal := []int{2, 1, 0}, []int{3, 2, 0}
+	for i := range expectedTotal {
+		if correct[i] != expectedCorrect[i] || total[i] != expectedTotal[i] {
+			t.Errorf("Class %d had %d/%d correct, expected %d/%d", i, correct[i], total[i], expectedCorrect[i], expectedTotal[i])
+		}
+	}
+}
+
+func TestTrainAccumSteps(t *testing.T) {
+	const steps = 4
+
+	// train returns the weights after training for one pass over xorData, with the given batch
+	// size and number of accumulation steps
+	train := func(batchSize, accum int) []float64 {
+		net := xorNet(t, 1)
+
+		data, err := bs.Data(xorData, batchSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = net.Train(bs.TrainArgs{TrainData: data, RunCondition: bs.TrainUntil(len(xorData)), AccumSteps: accum})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return net.Weights()
+	}
+
+	// accumulating over batches of one gives the same single step as one batch of all of them
+	accumulated, batched := train(1, steps), train(steps, 1)
+	if !sliceEqual(accumulated, batched, 1e-12) {
+		t.Errorf("Accumulated weights differ from a single batch:\n%v\n%v", accumulated, batched)
+	}
+
+	if sliceEqual(accumulated, train(1, 1), 1e-12) {
+		t.Errorf("Accumulating had no effect")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// the outputs are exactly the inputs
+	net := new(bs.Network)
+	out := net.Add(operators.Identity(), net.AddInput([]int{2}))
+	net.SetReduction(bs.ReduceSum)
+	if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	dataset := [][][]float64{
+		{{1, 0}, {1, 0}},     // cost 0, correct
+		{{0.6, 0.4}, {0, 1}}, // cost 0.36, incorrect
+		{{0.2, 0.9}, {0, 1}}, // cost 0.025, correct
+	}
+
+	cost, accuracy, err := net.Evaluate(bs.SliceSource(dataset), nil, bs.CorrectHighest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (0 + 0.36 + 0.025) / 3; !approxEqual(cost, expected, 1e-12) {
+		t.Errorf("Average cost is %v, expected %v", cost, expected)
+	} else if !approxEqual(accuracy, 2.0/3, 1e-12) {
+		t.Errorf("Accuracy is %v, expected %v", accuracy, 2.0/3)
+	}
+
+	if _, _, err := net.Evaluate(bs.SliceSource(nil), nil, nil); err != bs.ErrNoData {
+		t.Errorf("Empty data gave error %v, expected ErrNoData", err)
+	}
+
+	if _, _, err := net.Evaluate(nil, nil, nil); err == nil {
+		t.Errorf("Nil data gave no error")
+	} else if _, ok := err.(bs.NilArgError); !ok {
+		t.Errorf("Nil data gave error %v, expected type NilArgError", err)
+	}
+}
+
+// labelsOnly is a LabelCostFunction that fails the test if it is given a vector of targets for more
+// than one output, which would mean that a one-hot vector was constructed for a label
+type labelsOnly struct {
+	bs.LabelCostFunction
+	t *testing.T
+}
+
+func (c labelsOnly) Cost(outs, targets []float64) float64 {
+	if len(targets) > 1 {
+		c.t.Errorf("Cost was given targets %v instead of a label", targets)
+	}
+
+	return c.LabelCostFunction.Cost(outs, targets)
+}
+
+func (c labelsOnly) Derivs(outs, targets []float64) []float64 {
+	if len(targets) > 1 {
+		c.t.Errorf("Derivs was given targets %v instead of a label", targets)
+	}
+
+	return c.LabelCostFunction.Derivs(outs, targets)
+}
+
+func TestTrainLabels(t *testing.T) {
+	inputs := [][]float64{{1, 0}, {0, 1}, {1, 1}, {0.5, -1}}
+	labels := []int{0, 2, 1, 2}
+
+	oneHot := make([][][]float64, len(inputs))
+	for i := range inputs {
+		targets := make([]float64, 3)
+		targets[labels[i]] = 1
+		oneHot[i] = [][]float64{inputs[i], targets}
+	}
+
+	// train returns the weights after training, along with the cost afterwards
+	train := func(cf bs.CostFunction, r bs.Reduction, data bs.DataSource) ([]float64, float64) {
+		net := new(bs.Network)
+		l := net.AddInput([]int{2})
+		l = net.Add(operators.Neurons(3), l).Opt(optimizers.SGD())
+		l = net.Add(operators.Softmax(), l)
+
+		net.AddHP("learning-rate", hyperparams.Constant(0.1))
+		if err := net.Finalize(cf, l); err != nil {
+			t.Fatal(err)
+		}
+
+		net.SetReduction(r)
+		randomWeights(net, 4)
+
+		supplier, err := bs.FromSource(data, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := net.Train(bs.TrainArgs{TrainData: supplier, RunCondition: bs.TrainUntil(8)}); err != nil {
+			t.Fatal(err)
+		}
+
+		cost, _, err := net.Evaluate(data, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return net.Weights(), cost
+	}
+
+	// training with labels should be exactly the same as with the equivalent one-hot targets,
+	// regardless of the Reduction
+	for _, r := range []bs.Reduction{bs.ReduceDefault, bs.ReduceSum, bs.ReduceMean} {
+		labeled := labelsOnly{costfuncs.CrossEntropy(), t}
+		withLabels, labelCost := train(labeled, r, bs.LabelSource(inputs, labels, 3))
+		withTargets, targetCost := train(costfuncs.CrossEntropy(), r, bs.SliceSource(oneHot))
+
+		if !sliceEqual(withLabels, withTargets, 1e-12) {
+			t.Errorf("Reduction %d: weights after training with labels are %v, expected %v from one-hot targets", r, withLabels, withTargets)
+		}
+
+		if !approxEqual(labelCost, targetCost, 1e-12) {
+			t.Errorf("Reduction %d: cost with labels is %v, expected %v from one-hot targets", r, labelCost, targetCost)
+		}
+	}
+}
+
+func TestTrainCheckFinite(t *testing.T) {
+	build := func() (*bs.Network, *bs.Node) {
+		net := new(bs.Network)
+		out := net.Add(operators.Neurons(1), net.AddInput([]int{2})).Opt(optimizers.SGD()).SetName("out")
+
+		// a learning rate this large makes the weights overflow within a few iterations
+		net.AddHP("learning-rate", hyperparams.Constant(1e200))
+		if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+			t.Fatal(err)
+		}
+
+		return net, out
+	}
+
+	dataset := [][][]float64{{{1e10, -1e10}, {1}}, {{-1e10, 1e10}, {-1}}}
+
+	// without the check, training finishes with garbage
+	net, _ := build()
+	if err := net.Train(bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(20)}); err != nil {
+		t.Fatalf("Training without CheckFinite gave error: %v", err)
+	}
+
+	net, out := build()
+	args := bs.TrainArgs{Data: bs.SliceSource(dataset), RunCondition: bs.TrainUntil(20), CheckFinite: true}
+	err := net.Train(args)
+	if e, ok := err.(bs.NotFiniteError); !ok {
+		t.Fatalf("Training with CheckFinite gave error %v, expected type NotFiniteError", err)
+	} else if e.N != out {
+		t.Errorf("Error was for Node %v, expected %v", e.N, out)
+	} else if e.Iteration >= 20 {
+		t.Errorf("Error was at iteration %d, expected training to stop early", e.Iteration)
+	} else if msg := e.Error(); !strings.Contains(msg, "out") || !strings.Contains(msg, e.Of) {
+		t.Errorf("Error message %q does not say what was non-finite, or where", msg)
+	}
+}
+
+func TestTrainUpdateNorms(t *testing.T) {
+	// train returns a Network trained for the given number of epochs, with its hidden Neurons frozen
+	train := func(epochs int, record bool) *bs.Network {
+		net := xorNet(t, 5)
+		for _, n := range net.Nodes() {
+			if n.Name() == "hidden neurons" {
+				n.SetTrainable(false)
+			}
+		}
+
+		args := bs.TrainArgs{
+			Data:              bs.SliceSource(xorData),
+			RunCondition:      bs.TrainUntil(epochs * len(xorData)),
+			RecordUpdateNorms: record,
+		}
+
+		if err := net.Train(args); err != nil {
+			t.Fatal(err)
+		}
+
+		return net
+	}
+
+	net := train(3, true)
+	norms := net.UpdateNorms()
+
+	if ns := norms["output neurons"]; len(ns) != 3 {
+		t.Fatalf("Recorded %d norms for trainable Node, expected 3", len(ns))
+	}
+
+	for _, name := range []string{"input", "hidden neurons", "hidden logistic", "output logistic"} {
+		if ns, ok := norms[name]; ok {
+			t.Errorf("Recorded norms %v for Node %q, which is not trainable", ns, name)
+		}
+	}
+
+	// only the output Neurons are trained, so the norm of the first epoch is the norm of the
+	// changes to all of the weights after one epoch
+	start, after := xorNet(t, 5).Weights(), train(1, false).Weights()
+
+	var sum float64
+	for i := range start {
+		sum += (after[i] - start[i]) * (after[i] - start[i])
+	}
+
+	if n := norms["output neurons"][0]; sum == 0 || !approxEqual(n, math.Sqrt(sum), 1e-12) {
+		t.Errorf("Norm for the first epoch is %v, expected %v", n, math.Sqrt(sum))
+	}
+
+	if train(1, false).UpdateNorms() != nil {
+		t.Errorf("UpdateNorms gave norms without RecordUpdateNorms, expected nil")
+	}
+}
+
+func TestTrainOnline(t *testing.T) {
+	const passes = 5000
+
+	net := xorNet(t, 1)
+
+	samples := make(chan bs.Datum)
+	go func() {
+		for i := 0; i < passes; i++ {
+			for _, d := range xorData {
+				samples <- bs.Datum{Inputs: d[0], Outputs: d[1]}
+			}
+		}
+
+		close(samples)
+	}()
+
+	if err := net.TrainOnline(samples, bs.TrainArgs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range xorData {
+		outs, err := net.GetOutputs(d[0])
+		if err != nil {
+			t.Fatal(err)
+		} else if !bs.CorrectRound(outs, d[1]) {
+			t.Errorf("Output for %v is %v after training, expected %v", d[0], outs, d[1])
+		}
+	}
+}
+
+func TestTrainShuffleSeed(t *testing.T) {
+	train := func(seed int64) []float64 {
+		net := xorNet(t, 1)
+		args := xorArgs(t, 20*len(xorData))
+		args.Shuffle, args.Seed = true, seed
+
+		if err := net.Train(args); err != nil {
+			t.Fatal(err)
+		}
+
+		return net.Weights()
+	}
+
+	first := train(42)
+
+	// the shared generator is not used for shuffling when a seed is given
+	bs.Seed(7)
+	bs.Rand().Int63()
+
+	if second := train(42); !sliceEqual(first, second, 0) {
+		t.Errorf("Training twice with the same seed gave different weights: %v and %v", first, second)
+	}
+
+	if other := train(43); sliceEqual(first, other, 0) {
+		t.Errorf("Training with different seeds gave identical weights, expected the order of samples to differ")
+	}
+
+	net := xorNet(t, 1)
+	args := bs.TrainArgs{Data: bs.SliceSource(xorData), RunCondition: bs.TrainUntil(1), Shuffle: true}
+	if err := net.Train(args); err != bs.ErrNotShuffleable {
+		t.Errorf("Shuffling a DataSource gave error %v, expected ErrNotShuffleable", err)
+	}
+}
+
+func TestTrainDivergence(t *testing.T) {
+	// train returns the error from training a single Neuron for 10 epochs, with DivergenceEpochs
+	// set to 'epochs'
+	train := func(lr float64, epochs int) error {
+		net := new(bs.Network)
+		out := net.Add(operators.Neurons(1), net.AddInput([]int{2})).Opt(optimizers.SGD())
+		net.AddHP("learning-rate", hyperparams.Constant(lr))
+		if err := net.Finalize(costfuncs.MSE(), out); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := net.SetWeights([]float64{0, 0, 0}); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := bs.Data([][][]float64{{{1, 1}, {1}}}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return net.Train(bs.TrainArgs{TrainData: data, RunCondition: bs.TrainUntil(10), DivergenceEpochs: epochs})
+	}
+
+	// with a learning rate this large, each step overshoots by more than the last, so the cost
+	// increases in every epoch
+	err := train(1, 3)
+	if e, ok := err.(bs.DivergedError); !ok {
+		t.Fatalf("Diverging training gave error %v, expected type DivergedError", err)
+	} else if len(e.Costs) != 4 || e.Epoch >= 9 {
+		t.Errorf("Error gave costs %v at epoch %d, expected 4 costs before the end of training", e.Costs, e.Epoch)
+	} else {
+		for i := 1; i < len(e.Costs); i++ {
+			if e.Costs[i] <= e.Costs[i-1] {
+				t.Errorf("Costs %v given by the error are not increasing", e.Costs)
+			}
+		}
+	}
+
+	// the window is never full, so training isn't stopped
+	if err := train(1, 10); err != nil {
+		t.Errorf("Training with fewer epochs than the window gave error %v", err)
+	}
+
+	if err := train(0.1, 3); err != nil {
+		t.Errorf("Converging training gave error %v", err)
+	}
+}
+
+func TestTrainDropResults(t *testing.T) {
+	const iterations = 200
+
+	// the last Result from training without dropping any, for comparison
+	var last bs.Result
+	args := xorArgs(t, iterations)
+	args.SendStatus = bs.Every(1)
+	args.Update = func(r bs.Result) { last = r }
+	if err := xorNet(t, 1).Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	// Update never returns until training has finished
+	release := make(chan struct{})
+	received := make(chan bs.Result, iterations)
+
+	args = xorArgs(t, iterations)
+	args.SendStatus = bs.Every(1)
+	args.DropResults = true
+	args.Update = func(r bs.Result) {
+		<-release
+		received <- r
+	}
+
+	done := make(chan error)
+	go func() { done <- xorNet(t, 1).Train(args) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		close(release)
+		t.Fatal("Training did not finish while Update was blocked")
+	}
+
+	close(release)
+
+	// the final Result is never dropped
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case r := <-received:
+			if r == last {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Final Result %+v was not given to Update", last)
+		}
+	}
+}
+
+// deltaRecorder is an elementwise identity Operator that records the largest magnitude of its
+// deltas during backpropagation
+type deltaRecorder struct {
+	max float64
+}
+
+func (r *deltaRecorder) TypeString() string              { return "delta-recorder" }
+func (r *deltaRecorder) Finalize(n *bs.Node) error       { return nil }
+func (r *deltaRecorder) Value(in float64, i int) float64 { return in }
+
+func (r *deltaRecorder) Deriv(n *bs.Node, i int) float64 {
+	r.max = math.Max(r.max, math.Abs(n.Delta(i)))
+	return 1
+}
+
+func TestTrainLossScale(t *testing.T) {
+	// train returns the weights after training with the given loss scale, along with the largest
+	// delta of the hidden Node
+	train := func(scale float64) ([]float64, float64) {
+		rec := new(deltaRecorder)
+
+		net := new(bs.Network)
+		l := net.Add(operators.Neurons(3), net.AddInput([]int{2})).Opt(optimizers.SGD())
+		l = net.Add(operators.Logistic(), l)
+		l = net.Add(rec, l)
+		l = net.Add(operators.Neurons(1), l).Opt(optimizers.SGD())
+		l = net.Add(operators.Logistic(), l)
+		net.AddHP("learning-rate", hyperparams.Constant(0.5))
+		if err := net.Finalize(costfuncs.MSE(), l); err != nil {
+			t.Fatal(err)
+		}
+
+		randomWeights(net, 1)
+
+		args := xorArgs(t, 20*len(xorData))
+		args.LossScale = scale
+		if err := net.Train(args); err != nil {
+			t.Fatal(err)
+		}
+
+		return net.Weights(), rec.max
+	}
+
+	unscaled, unscaledDelta := train(0)
+	scaled, scaledDelta := train(1024)
+
+	if !sliceEqual(scaled, unscaled, 1e-12) {
+		t.Errorf("Weights with a loss scale are %v, expected %v as without", scaled, unscaled)
+	}
+
+	if !approxEqual(scaledDelta, 1024*unscaledDelta, 1e-9) {
+		t.Errorf("Largest hidden delta with a loss scale of 1024 is %v, expected %v", scaledDelta, 1024*unscaledDelta)
+	}
+
+	net := xorNet(t, 1)
+	args := xorArgs(t, 1)
+	args.LossScale = -1
+	if err := net.Train(args); err != bs.ErrInvalidLossScale {
+		t.Errorf("Training with a negative loss scale gave error %v, expected ErrInvalidLossScale", err)
+	}
+}
+
+func TestTrainCheckpoints(t *testing.T) {
+	const epochs, every = 5, 2
+
+	dir := t.TempDir()
+
+	net := xorNet(t, 1)
+	args := xorArgs(t, epochs*len(xorData))
+	args.CheckpointEvery, args.CheckpointPath = every, dir
+	if err := net.Train(args); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	if expected := []string{"epoch-2", "epoch-4"}; strings.Join(names, " ") != strings.Join(expected, " ") {
+		t.Fatalf("Checkpoints saved are %v, expected %v", names, expected)
+	}
+
+	// the last checkpoint should have the weights from exactly 4 epochs of training
+	loaded, err := bs.Load(filepath.Join(dir, "epoch-4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	straight := xorNet(t, 1)
+	if err := straight.Train(xorArgs(t, 4*len(xorData))); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := straight.Weights(), loaded.Weights(); !sliceEqual(got, expected, 0) {
+		t.Errorf("Weights from checkpoint are %v, expected %v", got, expected)
+	}
+}
@@ -16,8 +16,23 @@ import (
 // 'opsPerThread' is the number of operations that each goroutine will handle before requesting another set
 // 'threadsPerCPU' is the number of goroutines created for each CPU
 func MultiThread(start, end int, f func(int), opsPerThread, threadsPerCPU int) {
+	MultiThreadN(start, end, f, opsPerThread, runtime.NumCPU()*threadsPerCPU)
+}
+
+// Multithreads an operation on a range of integers, using a fixed number of goroutines
+//
+// this is the same as MultiThread, except that 'numThreads' gives the total number of goroutines
+// that will be created, instead of the number for each CPU. If numThreads ≤ 1, 'f' is run
+// sequentially in the calling goroutine, in order.
+func MultiThreadN(start, end int, f func(int), opsPerThread, numThreads int) {
+	if numThreads <= 1 {
+		for i := start; i < end; i++ {
+			f(i)
+		}
+
+		return
+	}
 
-	numThreads := runtime.NumCPU() * threadsPerCPU
 	index := start
 	var indexMux sync.Mutex
 